@@ -0,0 +1,336 @@
+/*
+This file handles formatting the source of template files. Formatting only changes
+whitespace that does not affect the output of templates, or that only affects the
+indentation of the output:
+  - Whitespace inside of actions is normalized, i.e. {{  if   .Active }} becomes
+    {{if .Active}}. Whitespace trimming markers, strings, and comments are kept as is.
+  - Indentation is normalized to tabs, or spaces, with spaces that do not make up a
+    complete tab kept for alignment.
+  - Trailing whitespace is removed from each line, line endings are converted to \n, and
+    the file ends with a single newline.
+
+Lines inside of <pre> and <textarea> elements are not changed since whitespace in these
+elements is shown to the user. Lines that continue an action split over many lines are
+not reindented, and whitespace inside of raw strings and comments that span lines is
+not changed, since this whitespace may be part of the output.
+*/
+
+package main
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+)
+
+//options are the settings used when formatting.
+type options struct {
+	//tabWidth is the number of columns a tab is treated as when normalizing
+	//indentation.
+	tabWidth int
+
+	//useSpaces indents using spaces instead of tabs.
+	useSpaces bool
+}
+
+//preformattedTag matches the start and end tags of elements where whitespace is shown
+//to the user.
+var preformattedTag = regexp.MustCompile(`(?i)<(/?)(pre|textarea)[\s>]`)
+
+//format returns the formatted source of a template file. Formatting is idempotent;
+//formatting already formatted source returns the source as is.
+func format(src []byte, opts options) []byte {
+	if opts.tabWidth <= 0 {
+		opts.tabWidth = 4
+	}
+
+	src = formatActions(src)
+
+	lines := strings.Split(string(src), "\n")
+	inAction, inLiteral := lineStates(string(src), len(lines))
+	out := make([]string, 0, len(lines))
+	preDepth := 0
+	for n, line := range lines {
+		if preDepth == 0 {
+			if !inLiteral[n] {
+				line = strings.TrimRight(line, " \t\r")
+			}
+			if !inAction[n] {
+				line = formatIndent(line, opts)
+			}
+		}
+
+		for _, m := range preformattedTag.FindAllStringSubmatch(line, -1) {
+			if m[1] == "/" {
+				if preDepth > 0 {
+					preDepth--
+				}
+				continue
+			}
+			preDepth++
+		}
+
+		out = append(out, line)
+	}
+
+	//End with a single newline.
+	for len(out) > 0 && strings.TrimSpace(out[len(out)-1]) == "" {
+		out = out[:len(out)-1]
+	}
+	if len(out) == 0 {
+		return nil
+	}
+
+	return []byte(strings.Join(out, "\n") + "\n")
+}
+
+//lineStates returns, for each of the numLines lines of src, if the line starts inside of
+//an action and if the line ends inside of a raw string or comment in an action.
+func lineStates(src string, numLines int) (inAction, inLiteral []bool) {
+	inAction = make([]bool, numLines)
+	inLiteral = make([]bool, numLines)
+
+	line := 0
+	for i := 0; i < len(src); i++ {
+		if src[i] == '\n' {
+			line++
+			continue
+		}
+		if !strings.HasPrefix(src[i:], "{{") {
+			continue
+		}
+
+		end := actionEnd([]byte(src[i+2:]))
+		if end == -1 {
+			break
+		}
+
+		//Find the lines the action spans, noting if each line ends inside of a raw
+		//string or comment. closer is the end of the raw string or comment being
+		//skipped over.
+		action := src[i+2 : i+2+end]
+		closer := ""
+		for j := 0; j < len(action); j++ {
+			switch {
+			case action[j] == '\n':
+				inLiteral[line] = closer != ""
+				line++
+				inAction[line] = true
+
+			case closer != "":
+				if strings.HasPrefix(action[j:], closer) {
+					j += len(closer) - 1
+					closer = ""
+				}
+
+			case action[j] == '"' || action[j] == '\'':
+				//Strings cannot span lines.
+				quote := action[j]
+				for j++; j < len(action) && action[j] != quote && action[j] != '\n'; j++ {
+					if action[j] == '\\' {
+						j++
+					}
+				}
+				if j < len(action) && action[j] == '\n' {
+					j--
+				}
+
+			case action[j] == '`':
+				closer = "`"
+
+			case strings.HasPrefix(action[j:], "/*"):
+				closer = "*/"
+				j++
+			}
+		}
+
+		i += 2 + end + 1
+	}
+
+	return
+}
+
+//formatIndent normalizes the leading whitespace of a line.
+func formatIndent(line string, opts options) string {
+	col := 0
+	i := 0
+	for ; i < len(line); i++ {
+		if line[i] == ' ' {
+			col++
+		} else if line[i] == '\t' {
+			col += opts.tabWidth - col%opts.tabWidth
+		} else {
+			break
+		}
+	}
+	if i == len(line) {
+		return ""
+	}
+
+	if opts.useSpaces {
+		return strings.Repeat(" ", col) + line[i:]
+	}
+
+	return strings.Repeat("\t", col/opts.tabWidth) + strings.Repeat(" ", col%opts.tabWidth) + line[i:]
+}
+
+//formatActions normalizes the whitespace inside of each action.
+func formatActions(src []byte) []byte {
+	var out bytes.Buffer
+	for {
+		start := bytes.Index(src, []byte("{{"))
+		if start == -1 {
+			out.Write(src)
+			break
+		}
+
+		end := actionEnd(src[start+2:])
+		if end == -1 {
+			out.Write(src)
+			break
+		}
+
+		out.Write(src[:start])
+		out.WriteString(formatAction(string(src[start+2 : start+2+end])))
+		src = src[start+2+end+2:]
+	}
+
+	return out.Bytes()
+}
+
+//actionEnd returns the index of the }} that ends an action, skipping over strings and
+//comments in the action. -1 is returned if the action does not end.
+func actionEnd(b []byte) int {
+	for i := 0; i < len(b); i++ {
+		switch b[i] {
+		case '"', '\'':
+			quote := b[i]
+			for i++; i < len(b) && b[i] != quote; i++ {
+				if b[i] == '\\' {
+					i++
+				}
+			}
+		case '`':
+			end := bytes.IndexByte(b[i+1:], '`')
+			if end == -1 {
+				return -1
+			}
+			i += end + 1
+		case '/':
+			if i+1 < len(b) && b[i+1] == '*' {
+				end := bytes.Index(b[i+2:], []byte("*/"))
+				if end == -1 {
+					return -1
+				}
+				i += end + 3
+			}
+		case '}':
+			if i+1 < len(b) && b[i+1] == '}' {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+//formatAction returns an action, provided without its delimiters, with whitespace
+//normalized and its delimiters added.
+func formatAction(action string) string {
+	//Whitespace trimming markers must be followed, or preceded, by whitespace.
+	trimLeft := len(action) >= 2 && action[0] == '-' && isSpace(action[1])
+	if trimLeft {
+		action = action[1:]
+	}
+	trimRight := len(action) >= 2 && action[len(action)-1] == '-' && isSpace(action[len(action)-2])
+	if trimRight {
+		action = action[:len(action)-1]
+	}
+
+	action = collapseSpaces(strings.TrimSpace(action))
+
+	var b strings.Builder
+	b.WriteString("{{")
+	if trimLeft {
+		b.WriteString("- ")
+	}
+	b.WriteString(action)
+	if trimRight {
+		b.WriteString(" -")
+	}
+	b.WriteString("}}")
+
+	return b.String()
+}
+
+//collapseSpaces replaces runs of spaces and tabs in an action with a single space,
+//skipping over strings and comments. Newlines, and the indentation following them, are
+//kept so that long actions split over many lines are not joined.
+func collapseSpaces(action string) string {
+	var b strings.Builder
+	for i := 0; i < len(action); i++ {
+		c := action[i]
+		switch {
+		case c == '"' || c == '\'':
+			j := i + 1
+			for ; j < len(action) && action[j] != c; j++ {
+				if action[j] == '\\' {
+					j++
+				}
+			}
+			b.WriteString(action[i:minInt(j+1, len(action))])
+			i = j
+
+		case c == '`':
+			j := strings.IndexByte(action[i+1:], '`')
+			if j == -1 {
+				b.WriteString(action[i:])
+				return b.String()
+			}
+			b.WriteString(action[i : i+j+2])
+			i += j + 1
+
+		case c == '/' && strings.HasPrefix(action[i:], "/*"):
+			j := strings.Index(action[i+2:], "*/")
+			if j == -1 {
+				b.WriteString(action[i:])
+				return b.String()
+			}
+			b.WriteString(action[i : i+j+4])
+			i += j + 3
+
+		case c == ' ' || c == '\t':
+			j := i
+			for j < len(action) && (action[j] == ' ' || action[j] == '\t') {
+				j++
+			}
+
+			//Drop spaces at the end of a line and keep indentation.
+			if i > 0 && action[i-1] == '\n' {
+				b.WriteString(action[i:j])
+			} else if j < len(action) && action[j] != '\n' && action[j] != '\r' {
+				b.WriteByte(' ')
+			}
+			i = j - 1
+
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return b.String()
+}
+
+//isSpace returns if c is whitespace as defined by the template package.
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
+
+//minInt returns the smaller of a and b.
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
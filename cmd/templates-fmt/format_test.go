@@ -0,0 +1,56 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	opts := options{tabWidth: 4}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Actions, indentation, and trailing whitespace normalized.
+	tests := map[string]string{
+		"{{ .Name }}":                          "{{.Name}}\n",
+		"{{if   .Active}}x{{  end  }}":         "{{if .Active}}x{{end}}\n",
+		"{{-   .Name   -}}":                    "{{- .Name -}}\n",
+		"{{-3}}":                               "{{-3}}\n",
+		`{{ printf "%s  %s" .A  .B }}`:         `{{printf "%s  %s" .A .B}}` + "\n",
+		"{{ printf `a  }}  b` }}":              "{{printf `a  }}  b`}}\n",
+		"{{ /*  a  comment  */ }}":             "{{/*  a  comment  */}}\n",
+		"{{ index .M \"}}\" }}":                "{{index .M \"}}\"}}\n",
+		"<div>  \n        <p>x</p>\t\r\n":      "<div>\n\t\t<p>x</p>\n",
+		"  <p>x</p>":                           "  <p>x</p>\n",
+		"\t  <p>x</p>":                         "\t  <p>x</p>\n",
+		"<p>x</p>\n\n\n":                       "<p>x</p>\n",
+		"<pre>\n    keep  \n  </pre>\n    <p>": "<pre>\n    keep  \n  </pre>\n\t<p>\n",
+		"{{if .A}}\n      {{.B}}\n{{end}}":     "{{if .A}}\n\t  {{.B}}\n{{end}}\n",
+		"{{printf `a  \n    b  \n`}}  ":        "{{printf `a  \n    b  \n`}}\n",
+		"{{/* a  \n        b */}}":             "{{/* a  \n        b */}}\n",
+		"{{ printf\n        .A  .B }}":         "{{printf\n        .A .B}}\n",
+		"  {{\"`\"}}\n    <p>":                 "  {{\"`\"}}\n\t<p>\n",
+	}
+	for src, expected := range tests {
+		formatted := string(format([]byte(src), opts))
+		if formatted != expected {
+			t.Fatalf("Not formatted as expected\nsrc:      %q\nexpected: %q\ngot:      %q", src, expected, formatted)
+			return
+		}
+
+		//Formatting must be idempotent.
+		if again := string(format([]byte(formatted), opts)); again != formatted {
+			t.Fatalf("Formatting is not idempotent\nsrc:    %q\nfirst:  %q\nsecond: %q", src, formatted, again)
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Indent with spaces.
+	opts.useSpaces = true
+	formatted := string(format([]byte("\t<p>x</p>"), opts))
+	if formatted != "    <p>x</p>\n" {
+		t.Fatalf("Not formatted as expected %q", formatted)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,119 @@
+/*
+Command templates-fmt formats template files so that formatting is consistent across
+your templates, making diffs and reviews easier. See format.go for the changes made
+when formatting.
+
+Usage:
+	templates-fmt [flags] [path ...]
+
+Paths can be files or directories. Directories are walked for files with the extension
+set by -ext. With no paths, the source is read from stdin and the formatted source is
+written to stdout.
+
+Flags:
+	-w         write the formatted source to each file instead of stdout.
+	-l         list the files whose formatting differs.
+	-ext       extension of template files to format when walking directories. Default "html".
+	-tabwidth  columns per tab when normalizing indentation. Default 4.
+	-spaces    indent with spaces instead of tabs.
+*/
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	write := flag.Bool("w", false, "write the formatted source to each file instead of stdout")
+	list := flag.Bool("l", false, "list the files whose formatting differs")
+	ext := flag.String("ext", "html", "extension of template files to format when walking directories")
+	tabWidth := flag.Int("tabwidth", 4, "columns per tab when normalizing indentation")
+	useSpaces := flag.Bool("spaces", false, "indent with spaces instead of tabs")
+	flag.Parse()
+
+	opts := options{
+		tabWidth:  *tabWidth,
+		useSpaces: *useSpaces,
+	}
+
+	if flag.NArg() == 0 {
+		src, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+
+		os.Stdout.Write(format(src, opts))
+		return
+	}
+
+	exitCode := 0
+	for _, path := range flag.Args() {
+		err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+
+			//Only filter files when walking a directory, files provided directly are
+			//always formatted. Symlinks are skipped since they may point to directories
+			//or to files outside of the directory.
+			if p != path && (!d.Type().IsRegular() || strings.TrimPrefix(filepath.Ext(p), ".") != strings.TrimPrefix(*ext, ".")) {
+				return nil
+			}
+
+			return formatFile(p, opts, *write, *list)
+		})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			exitCode = 2
+		}
+	}
+
+	os.Exit(exitCode)
+}
+
+//formatFile formats a file writing the formatted source to the file, if write is true,
+//or to stdout. If list is true, the file's path is written to stdout if its formatting
+//differs.
+func formatFile(path string, opts options, write, list bool) error {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	formatted := format(src, opts)
+	changed := !bytes.Equal(src, formatted)
+
+	if list && changed {
+		fmt.Println(path)
+	}
+
+	if write {
+		if !changed {
+			return nil
+		}
+
+		fi, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(path, formatted, fi.Mode().Perm())
+	}
+
+	if !list {
+		os.Stdout.Write(formatted)
+	}
+
+	return nil
+}
@@ -1,3 +1,5 @@
 module github.com/c9845/templates
 
-go 1.17
+go 1.18
+
+require golang.org/x/net v0.33.0
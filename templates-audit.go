@@ -0,0 +1,72 @@
+/*
+This file handles recording changes made to templates at runtime, such as for apps that
+allow editing templates in-app and must keep a record of who changed what, and when,
+for compliance. Set AuditFunc in your config to receive an AuditEvent for each change
+and store it however your app stores its audit log.
+
+An event is recorded when:
+  - a template is added or replaced with ParseExtra() or ParseUntrusted().
+  - a template is rolled back with RollbackUntrusted().
+  - templates are rebuilt, with Build() or Rebuild(), after they were already built.
+    Building templates when your app starts is not recorded.
+  - a standby set of templates is promoted with Promote().
+
+AuditFunc is called after the change is made, and only if the change succeeded, and is
+called without holding any locks so it can use your config.
+*/
+
+package templates
+
+import "time"
+
+//AuditAction is the type of change recorded in an AuditEvent.
+type AuditAction string
+
+//Types of changes recorded in an AuditEvent.
+const (
+	AuditAdd      AuditAction = "add"
+	AuditReplace  AuditAction = "replace"
+	AuditRollback AuditAction = "rollback"
+	AuditRebuild  AuditAction = "rebuild"
+	AuditPromote  AuditAction = "promote"
+)
+
+//AuditEvent is a change made to templates at runtime.
+type AuditEvent struct {
+	//Time is when the change was made.
+	Time time.Time
+
+	//Action is the type of change.
+	Action AuditAction
+
+	//SubDir is the subdirectory whose templates were changed. This is blank when all
+	//templates were rebuilt or promoted.
+	SubDir string
+
+	//Templates is the names of the templates, or paths to the files, that were changed.
+	//This is blank when all templates were rebuilt or promoted.
+	Templates []string
+
+	//Actor is who made the change. For changes made with ParseUntrusted() and
+	//RollbackUntrusted() this is the policy's Author; otherwise this is blank since
+	//the change was made by your app.
+	Actor string
+
+	//Hash is the hash of the source files after templates were rebuilt or promoted,
+	//see Hash().
+	Hash string
+}
+
+//audit calls AuditFunc, if set, with the event. This must not be called while holding
+//the lock.
+func (c *Config) audit(e AuditEvent) {
+	if c.AuditFunc == nil {
+		return
+	}
+
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	c.AuditFunc(e)
+}
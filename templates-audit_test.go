@@ -0,0 +1,95 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAuditFunc(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	var events []AuditEvent
+	base := filepath.Join(dir, "_testdata", "templates")
+	c := NewOnDiskConfig(base, []string{"app", "help"})
+	c.AuditFunc = func(e AuditEvent) {
+		//Make sure the lock is not held.
+		c.Hash()
+
+		events = append(events, e)
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Building when starting is not recorded.
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+	if len(events) != 0 {
+		t.Fatal("Initial build should not have been recorded", events)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	p := UntrustedPolicy{Namespace: "tenant-1/", Author: "user-42"}
+	err = c.ParseUntrusted("app", "tenant-1/home.html", "v1", p)
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	err = c.ParseUntrusted("app", "tenant-1/home.html", "v2", p)
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	err = c.RollbackUntrusted("app", "tenant-1/home.html", p)
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	err = c.ParseExtra("help", filepath.Join(dir, "_testdata", "extra", "extra.html"))
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+
+	//Failed changes are not recorded.
+	c.ParseUntrusted("app", "tenant-1/home.html", "{{if}}", p)
+
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	expected := []AuditAction{AuditAdd, AuditReplace, AuditRollback, AuditAdd, AuditRebuild}
+	if len(events) != len(expected) {
+		t.Fatal("Wrong number of events recorded", events)
+		return
+	}
+	for i, e := range events {
+		if e.Action != expected[i] || e.Time.IsZero() {
+			t.Fatal("Event not as expected", i, e)
+			return
+		}
+	}
+	if events[1].Actor != "user-42" || events[1].SubDir != "app" || events[1].Templates[0] != "tenant-1/home.html" {
+		t.Fatal("Event not as expected", events[1])
+		return
+	}
+	if events[3].Actor != "" || events[3].SubDir != "help" {
+		t.Fatal("Event not as expected", events[3])
+		return
+	}
+	if events[4].Hash != c.Hash() || events[4].SubDir != "" {
+		t.Fatal("Event not as expected", events[4])
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
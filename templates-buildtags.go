@@ -0,0 +1,102 @@
+/*
+This file handles build directives in template files. Directives exclude whole files, or
+blocks within files, from being parsed based upon the environment your app is running
+in. This is useful for templates that must not exist in some environments, such as a
+debug panel that must not be included in production, rather than just being hidden with
+{{if .Development}}.
+
+A file is only parsed if the expression in its build directive is satisfied. The
+directive must be the first thing in the file:
+	{{/* +build development staging *\/}}
+A block is only parsed if the expression in its directive is satisfied:
+	{{/* +if development *\/}}
+	<div id="debug-panel">...</div>
+	{{/* +end *\/}}
+Blocks cannot be nested.
+
+Expressions use the same syntax as the original go build constraints: space separated
+terms are OR'd, comma separated terms are AND'd, and a term prefixed with ! is negated.
+The tags that are set are the config's BuildTags, the config's Environment, and
+"development" if Development is true. Tags are case insensitive.
+
+Since directives are comments, templates are still valid if BuildTags is never used.
+*/
+
+package templates
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	//buildDirective matches a build directive for a whole file.
+	buildDirective = regexp.MustCompile(`^\s*\{\{-?\s*/\*\s*\+build\s+([^*]*?)\s*\*/\s*-?\}\}[ \t]*\r?\n?`)
+
+	//blockDirective matches a block, from the directive to the end directive, that is
+	//parsed conditionally.
+	blockDirective = regexp.MustCompile(`(?s)\{\{-?\s*/\*\s*\+if\s+([^*]*?)\s*\*/\s*-?\}\}[ \t]*\r?\n?(.*?)\{\{-?\s*/\*\s*\+end\s*\*/\s*-?\}\}[ \t]*\r?\n?`)
+)
+
+//applyBuildDirectives returns the source of a file with blocks whose directives are not
+//satisfied removed. False is returned if the file's build directive is not satisfied and
+//the file should not be parsed.
+func (c *Config) applyBuildDirectives(b []byte) ([]byte, bool) {
+	tags := c.buildTags()
+
+	if m := buildDirective.FindSubmatchIndex(b); m != nil {
+		if !matchBuildExpr(string(b[m[2]:m[3]]), tags) {
+			return nil, false
+		}
+		b = b[m[1]:]
+	}
+
+	b = blockDirective.ReplaceAllFunc(b, func(block []byte) []byte {
+		m := blockDirective.FindSubmatch(block)
+		if !matchBuildExpr(string(m[1]), tags) {
+			return nil
+		}
+		return m[2]
+	})
+
+	return b, true
+}
+
+//buildTags returns the tags used to evaluate build directives.
+func (c *Config) buildTags() map[string]bool {
+	tags := make(map[string]bool, len(c.BuildTags)+2)
+	for _, t := range c.BuildTags {
+		tags[strings.ToLower(strings.TrimSpace(t))] = true
+	}
+	if env := strings.TrimSpace(c.Environment); env != "" {
+		tags[strings.ToLower(env)] = true
+	}
+	if c.Development {
+		tags["development"] = true
+	}
+
+	return tags
+}
+
+//matchBuildExpr returns if a build expression is satisfied by the set tags.
+func matchBuildExpr(expr string, tags map[string]bool) bool {
+	for _, or := range strings.Fields(expr) {
+		matched := true
+		for _, and := range strings.Split(or, ",") {
+			tag := strings.ToLower(and)
+			negate := strings.HasPrefix(tag, "!")
+			tag = strings.TrimPrefix(tag, "!")
+
+			if tag == "" || tags[tag] == negate {
+				matched = false
+				break
+			}
+		}
+
+		if matched {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,95 @@
+package templates
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuildDirectives(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	subdirs := []string{"buildtags"}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Development only file and block are included.
+	c := NewOnDiskConfig(base, subdirs)
+	c.Development = true
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	w := httptest.NewRecorder()
+	c.Show(w, "buildtags", "debug", nil)
+	if w.Body.String() != "debug panel\n" {
+		t.Fatal("Development only file should have been included", w.Body.String())
+		return
+	}
+
+	w = httptest.NewRecorder()
+	c.Show(w, "buildtags", "page", nil)
+	if w.Body.String() != "start\ndev only\nend\n" {
+		t.Fatal("Blocks not included as expected", w.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Development only file and block are excluded in production.
+	c = NewOnDiskConfig(base, subdirs)
+	c.Environment = "Production"
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	w = httptest.NewRecorder()
+	c.Show(w, "buildtags", "debug", nil)
+	if w.Code != http.StatusNotFound {
+		t.Fatal("Development only file should have been excluded", w.Code)
+		return
+	}
+
+	w = httptest.NewRecorder()
+	c.Show(w, "buildtags", "page", nil)
+	if w.Body.String() != "start\nstaging or production\nend\n" {
+		t.Fatal("Blocks not included as expected", w.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestMatchBuildExpr(t *testing.T) {
+	tags := map[string]bool{"a": true, "b": true}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	tests := map[string]bool{
+		"a":      true,
+		"c":      false,
+		"c a":    true,
+		"a,b":    true,
+		"a,c":    false,
+		"!c":     true,
+		"!a":     false,
+		"a,!b c": false,
+		"A":      true,
+		"":       false,
+	}
+	for expr, expected := range tests {
+		if matchBuildExpr(expr, tags) != expected {
+			t.Fatal("Expression not matched as expected", expr, expected)
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
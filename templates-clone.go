@@ -0,0 +1,404 @@
+/*
+This file handles deriving new configs from an existing, built, config. This is useful
+when some requests need slightly different settings, such as a different AppVersion,
+Environment, or set of cache busting files for a theme, without parsing every template
+again.
+
+A derived config is independent of the config it was derived from; changing either
+config's fields, or rebuilding either config, does not affect the other. Parsed
+templates are shared where this is safe. Templates that call funcs reading values from
+the config, such as isEnv, signURL, or request-scoped funcs such as flag, are parsed
+again so that the funcs read values from the derived config.
+
+Fields that change how templates are parsed, such as FuncMap, BuildTags, or BasePath,
+only take effect once Build() is called on the derived config.
+*/
+
+package templates
+
+import (
+	"html/template"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+//Option modifies a config derived with With().
+type Option func(c *Config)
+
+//WithEnvironment sets the Environment of a derived config.
+func WithEnvironment(env string) Option {
+	return func(c *Config) {
+		c.Environment = env
+	}
+}
+
+//WithAppVersion sets the AppVersion of a derived config.
+func WithAppVersion(version string) Option {
+	return func(c *Config) {
+		c.AppVersion = version
+	}
+}
+
+//WithDevelopment sets Development for a derived config.
+func WithDevelopment(development bool) Option {
+	return func(c *Config) {
+		c.Development = development
+	}
+}
+
+//WithUseLocalFiles sets UseLocalFiles for a derived config.
+func WithUseLocalFiles(useLocalFiles bool) Option {
+	return func(c *Config) {
+		c.UseLocalFiles = useLocalFiles
+	}
+}
+
+//WithCacheBustingFilePairs sets the CacheBustingFilePairs of a derived config, for
+//example to use the files for a different theme.
+func WithCacheBustingFilePairs(pairs map[string]string) Option {
+	return func(c *Config) {
+		c.CacheBustingFilePairs = pairs
+	}
+}
+
+//WithSecurityHeaders sets the SecurityHeaders of a derived config.
+func WithSecurityHeaders(sh *SecurityHeaders) Option {
+	return func(c *Config) {
+		c.SecurityHeaders = sh
+	}
+}
+
+//WithErrorFunc sets the ErrorFunc of a derived config.
+func WithErrorFunc(fn func(w http.ResponseWriter, status int, message string)) Option {
+	return func(c *Config) {
+		c.ErrorFunc = fn
+	}
+}
+
+//Clone returns a copy of the config that shares the config's parsed templates where
+//safe. The copy can be modified without affecting the config. Cached and prerendered
+//output is not copied since it may differ based upon the copy's fields. An error is
+//returned if templates that must be parsed again, see templates-clone.go, cannot be
+//parsed.
+func (c *Config) Clone() (clone *Config, err error) {
+	clone = c.copyExported()
+	if c.mu != nil {
+		c.mu.RLock()
+		clone.copyState(c)
+		c.mu.RUnlock()
+	}
+	clone.copyFields()
+
+	//State that is unique to each config.
+	clone.mu = &sync.RWMutex{}
+	clone.maintenance = atomic.LoadInt32(&c.maintenance)
+	clone.renderSlots = clone.newRenderSlots()
+	if clone.hash != "" {
+		//Options for a derived config set fields directly.
+		clone.configChangedAt = time.Now()
+		clone.refs = newSetRefs()
+	}
+
+	err = clone.rebindConfigFuncs()
+	if err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+//Clone returns a copy of the default package level config.
+func Clone() (clone *Config, err error) {
+	clone, err = Default().Clone()
+	return
+}
+
+//With returns a copy of the config, see Clone(), with the options applied.
+func (c *Config) With(opts ...Option) (derived *Config, err error) {
+	derived, err = c.Clone()
+	if err != nil {
+		return
+	}
+
+	for _, opt := range opts {
+		opt(derived)
+	}
+
+	return
+}
+
+//With returns a copy of the default package level config with the options applied.
+func With(opts ...Option) (derived *Config, err error) {
+	derived, err = Default().With(opts...)
+	return
+}
+
+//copyExported returns a new config with the exported fields of the config. Fields are
+//copied one by one, rather than copying the whole struct, so that unexported state,
+//some of which is modified atomically, is never copied without synchronization. Add new
+//exported fields here.
+func (c *Config) copyExported() *Config {
+	return &Config{
+		Development:                 c.Development,
+		LintAccessibility:           c.LintAccessibility,
+		LintHTML:                    c.LintHTML,
+		StrictCSP:                   c.StrictCSP,
+		LintFunc:                    c.LintFunc,
+		TrimBlocks:                  c.TrimBlocks,
+		BuildTags:                   c.BuildTags,
+		SourceTransforms:            c.SourceTransforms,
+		ContribPartials:             c.ContribPartials,
+		UseLocalFiles:               c.UseLocalFiles,
+		Environment:                 c.Environment,
+		AppVersion:                  c.AppVersion,
+		BasePath:                    c.BasePath,
+		SubDirs:                     c.SubDirs,
+		Groups:                      c.Groups,
+		Extension:                   c.Extension,
+		UseEmbedded:                 c.UseEmbedded,
+		StaticPath:                  c.StaticPath,
+		EmbeddedFS:                  c.EmbeddedFS,
+		FS:                          c.FS,
+		FuncMap:                     c.FuncMap,
+		CacheBustingFilePairs:       c.CacheBustingFilePairs,
+		SubDirCacheBustingFilePairs: c.SubDirCacheBustingFilePairs,
+		RequestIDHeader:             c.RequestIDHeader,
+		RequestIDContextKey:         c.RequestIDContextKey,
+		CacheControl:                c.CacheControl,
+		Vary:                        c.Vary,
+		SecurityHeaders:             c.SecurityHeaders,
+		SubDirSecurityHeaders:       c.SubDirSecurityHeaders,
+		ConditionalGET:              c.ConditionalGET,
+		TrustProxyHeaders:           c.TrustProxyHeaders,
+		URLSigningKey:               c.URLSigningKey,
+		FeatureFlagFunc:             c.FeatureFlagFunc,
+		DeviceFunc:                  c.DeviceFunc,
+		DeviceVary:                  c.DeviceVary,
+		NotificationMaxLength:       c.NotificationMaxLength,
+		RedactErrors:                c.RedactErrors,
+		ErrorFunc:                   c.ErrorFunc,
+		NotFoundStatus:              c.NotFoundStatus,
+		StatusTemplates:             c.StatusTemplates,
+		ValidateData:                c.ValidateData,
+		FixturesDir:                 c.FixturesDir,
+		MaxOutputBytes:              c.MaxOutputBytes,
+		MaxIncludeDepth:             c.MaxIncludeDepth,
+		IgnoreHiddenFiles:           c.IgnoreHiddenFiles,
+		IgnoreSymlinks:              c.IgnoreSymlinks,
+		MaxFiles:                    c.MaxFiles,
+		MaxFileSize:                 c.MaxFileSize,
+		MaxTotalBytes:               c.MaxTotalBytes,
+		MaxConcurrentRenders:        c.MaxConcurrentRenders,
+		RenderQueueTimeout:          c.RenderQueueTimeout,
+		BuildProgress:               c.BuildProgress,
+		AuditFunc:                   c.AuditFunc,
+		SmokeTests:                  c.SmokeTests,
+		DisableRenderCache:          c.DisableRenderCache,
+		DataKey:                     c.DataKey,
+		FlattenInjectedData:         c.FlattenInjectedData,
+		MergeInjectedMap:            c.MergeInjectedMap,
+		SessionProvider:             c.SessionProvider,
+		UserProvider:                c.UserProvider,
+		PreferenceProvider:          c.PreferenceProvider,
+		Authorizer:                  c.Authorizer,
+	}
+}
+
+//copyState copies the built templates from another config so that changes to either
+//config's templates, such as with ParseExtra(), do not affect the other. The templates
+//themselves are shared. This must be called while holding the other config's read
+//lock.
+func (c *Config) copyState(from *Config) {
+	c.hash = from.hash
+	c.builtParseFingerprint = from.builtParseFingerprint
+	c.buildTime = from.buildTime
+	c.generation = from.generation
+	c.templates = copyTemplateMap(from.templates)
+	if from.requestTemplates != nil {
+		c.requestTemplates = make(map[string]*requestPool, len(from.requestTemplates))
+		for k, v := range from.requestTemplates {
+			c.requestTemplates[k] = v
+		}
+	}
+	c.untrustedTemplates = copyTemplateMap(from.untrustedTemplates)
+
+	if from.named != nil {
+		c.named = make(map[string]map[string]*template.Template, len(from.named))
+		for k, v := range from.named {
+			c.named[k] = v
+		}
+	}
+	if from.files != nil {
+		c.files = make(map[string][]string, len(from.files))
+		for k, v := range from.files {
+			c.files[k] = v
+		}
+	}
+	if from.sources != nil {
+		c.sources = make(map[string]map[string]untrustedSource, len(from.sources))
+		for k, v := range from.sources {
+			c.sources[k] = v
+		}
+	}
+	if from.previousSources != nil {
+		//The previous sources for a subdirectory are modified in place so they must be
+		//copied as well.
+		c.previousSources = make(map[string]map[string]untrustedSource, len(from.previousSources))
+		for k, v := range from.previousSources {
+			sources := make(map[string]untrustedSource, len(v))
+			for name, s := range v {
+				sources[name] = s
+			}
+			c.previousSources[k] = sources
+		}
+	}
+	if from.untrustedLimits != nil {
+		c.untrustedLimits = make(map[string]map[string]UntrustedLimits, len(from.untrustedLimits))
+		for k, v := range from.untrustedLimits {
+			c.untrustedLimits[k] = v
+		}
+	}
+	if from.fileHashes != nil {
+		c.fileHashes = make(map[string]ManifestFile, len(from.fileHashes))
+		for k, v := range from.fileHashes {
+			c.fileHashes[k] = v
+		}
+	}
+	if from.includes != nil {
+		c.includes = make(map[string]map[string][]string, len(from.includes))
+		for k, v := range from.includes {
+			c.includes[k] = v
+		}
+	}
+	if from.modTimes != nil {
+		c.modTimes = make(map[string]time.Time, len(from.modTimes))
+		for k, v := range from.modTimes {
+			c.modTimes[k] = v
+		}
+	}
+	if from.nodeCounts != nil {
+		c.nodeCounts = make(map[string]int, len(from.nodeCounts))
+		for k, v := range from.nodeCounts {
+			c.nodeCounts[k] = v
+		}
+	}
+	if from.memoizableSubDirs != nil {
+		c.memoizableSubDirs = make(map[string]bool, len(from.memoizableSubDirs))
+		for k, v := range from.memoizableSubDirs {
+			c.memoizableSubDirs[k] = v
+		}
+	}
+}
+
+//copyFields copies the config's exported maps and slices so that modifying them does
+//not modify the config the fields were copied from.
+func (c *Config) copyFields() {
+	c.SubDirs = append([]string(nil), c.SubDirs...)
+	c.BuildTags = append([]string(nil), c.BuildTags...)
+	c.Vary = append([]string(nil), c.Vary...)
+	c.DeviceVary = append([]string(nil), c.DeviceVary...)
+	c.URLSigningKey = append([]byte(nil), c.URLSigningKey...)
+	c.SourceTransforms = append([]func(string, []byte) ([]byte, error)(nil), c.SourceTransforms...)
+	c.SmokeTests = append([]SmokeTest(nil), c.SmokeTests...)
+
+	c.CacheBustingFilePairs = copyStringMap(c.CacheBustingFilePairs)
+	c.CacheControl = copyStringMap(c.CacheControl)
+
+	if c.FuncMap != nil {
+		fm := make(template.FuncMap, len(c.FuncMap))
+		for k, v := range c.FuncMap {
+			fm[k] = v
+		}
+		c.FuncMap = fm
+	}
+	if c.Groups != nil {
+		groups := make(map[string][]string, len(c.Groups))
+		for k, v := range c.Groups {
+			groups[k] = append([]string(nil), v...)
+		}
+		c.Groups = groups
+	}
+	if c.SubDirCacheBustingFilePairs != nil {
+		pairs := make(map[string]map[string]string, len(c.SubDirCacheBustingFilePairs))
+		for k, v := range c.SubDirCacheBustingFilePairs {
+			pairs[k] = copyStringMap(v)
+		}
+		c.SubDirCacheBustingFilePairs = pairs
+	}
+	if c.SubDirSecurityHeaders != nil {
+		headers := make(map[string]*SecurityHeaders, len(c.SubDirSecurityHeaders))
+		for k, v := range c.SubDirSecurityHeaders {
+			headers[k] = v
+		}
+		c.SubDirSecurityHeaders = headers
+	}
+	if c.StatusTemplates != nil {
+		refs := make(map[int]TemplateRef, len(c.StatusTemplates))
+		for k, v := range c.StatusTemplates {
+			refs[k] = v
+		}
+		c.StatusTemplates = refs
+	}
+}
+
+//rebindConfigFuncs parses the templates that call funcs reading values from the config
+//again so that the funcs read values from this config rather than the config the
+//templates were copied from. This includes request-scoped funcs, which read fields such
+//as FeatureFlagFunc even when no request is known. Templates added with
+//ParseUntrusted() are always parsed again since funcs cannot be replaced in their
+//unexecuted copies. The tryTemplate func is not bound to a config, only to a set of
+//templates, so templates that only use it are shared as is.
+func (c *Config) rebindConfigFuncs() (err error) {
+	var names []string
+	for name := range c.configFuncMap() {
+		if _, ok := c.FuncMap[name]; !ok {
+			names = append(names, name)
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for subdir, t := range c.templates {
+		if !usesFuncs(t, names) && c.requestTemplates[subdir] == nil && len(c.sources[subdir]) == 0 {
+			continue
+		}
+
+		err = c.reparse(subdir, c.files[subdir], c.sources[subdir])
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+//copyTemplateMap returns a copy of a map of templates. The templates are not copied.
+func copyTemplateMap(m map[string]*template.Template) map[string]*template.Template {
+	if m == nil {
+		return nil
+	}
+
+	copied := make(map[string]*template.Template, len(m))
+	for k, v := range m {
+		copied[k] = v
+	}
+
+	return copied
+}
+
+//copyStringMap returns a copy of a map of strings.
+func copyStringMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+
+	copied := make(map[string]string, len(m))
+	for k, v := range m {
+		copied[k] = v
+	}
+
+	return copied
+}
@@ -0,0 +1,153 @@
+package templates
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestWith(t *testing.T) {
+	fsys := fstest.MapFS{
+		"tpl/app/env.html":    {Data: []byte(`{{if isEnv "staging"}}staging{{else}}other{{end}}`)},
+		"tpl/help/plain.html": {Data: []byte(`{{.AppVersion}}`)},
+	}
+
+	c := NewFSConfig(fsys, "tpl", []string{"app", "help"})
+	c.AppVersion = "1"
+	c.CacheBustingFilePairs = map[string]string{"a.css": "a.1.css"}
+	err := c.Build()
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Options are applied to the derived config only.
+	derived, err := c.With(WithEnvironment("staging"), WithAppVersion("2"))
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+
+	for _, tc := range []struct {
+		c        *Config
+		subdir   string
+		name     string
+		expected string
+	}{
+		{c, "app", "env", "other"},
+		{derived, "app", "env", "staging"},
+		{c, "help", "plain", "1"},
+		{derived, "help", "plain", "2"},
+	} {
+		w := httptest.NewRecorder()
+		tc.c.Show(w, tc.subdir, tc.name, nil)
+		if w.Body.String() != tc.expected {
+			t.Fatal("Unexpected output", tc.subdir, tc.name, w.Body.String(), tc.expected)
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Templates are only parsed again if they read values from the config.
+	if derived.templates["help"] != c.templates["help"] {
+		t.Fatal("Templates should have been shared")
+		return
+	}
+	if derived.templates["app"] == c.templates["app"] {
+		t.Fatal("Templates should have been parsed again")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//The configs are independent.
+	derived.CacheBustingFilePairs["a.css"] = "a.2.css"
+	if c.CacheBustingFilePairs["a.css"] != "a.1.css" {
+		t.Fatal("Modifying the derived config modified the original config")
+		return
+	}
+
+	fsys["tpl/help/plain.html"] = &fstest.MapFile{Data: []byte(`changed`)}
+	err = derived.Build()
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	w := httptest.NewRecorder()
+	c.Show(w, "help", "plain", nil)
+	if w.Body.String() != "1" {
+		t.Fatal("Rebuilding the derived config modified the original config", w.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestClone(t *testing.T) {
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Every exported field is copied. Each field is set to a non-zero value so that a
+	//field missing from copyExported() is found.
+	c := NewConfig()
+	v := reflect.ValueOf(c).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Field(i)
+		if !v.Type().Field(i).IsExported() {
+			continue
+		}
+
+		switch f.Kind() {
+		case reflect.Bool:
+			f.SetBool(true)
+		case reflect.String:
+			f.SetString("x")
+		case reflect.Int, reflect.Int32, reflect.Int64:
+			f.SetInt(1)
+		case reflect.Slice:
+			f.Set(reflect.MakeSlice(f.Type(), 1, 1))
+		case reflect.Map:
+			f.Set(reflect.MakeMap(f.Type()))
+		case reflect.Ptr:
+			f.Set(reflect.New(f.Type().Elem()))
+		case reflect.Func:
+			f.Set(reflect.MakeFunc(f.Type(), func([]reflect.Value) []reflect.Value { return nil }))
+		case reflect.Interface:
+			if reflect.TypeOf(fstest.MapFS{}).Implements(f.Type()) {
+				f.Set(reflect.ValueOf(fstest.MapFS{}))
+			}
+		}
+	}
+
+	clone, err := c.Clone()
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+
+	cv := reflect.ValueOf(clone).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if field.IsExported() && !v.Field(i).IsZero() && cv.Field(i).IsZero() {
+			t.Fatal("Field not copied", field.Name)
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Cloning while maintenance mode is toggled does not race.
+	c = NewConfig()
+	done := make(chan struct{})
+	go func() {
+		c.SetMaintenance(true)
+		close(done)
+	}()
+	_, err = c.Clone()
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	<-done
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
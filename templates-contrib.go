@@ -0,0 +1,314 @@
+/*
+This file handles the optional set of partials, contrib partials, that are provided by
+this package for common UI elements: a table with sortable columns, pagination links,
+and flash messages. These give a new project working UI scaffolding without having to
+write these partials for each project.
+
+Contrib partials are only parsed when ContribPartials is set in your config. They are
+parsed into every subdirectory's templates, before your own files, so that you can
+replace a partial by defining a template with the same name. The partials are:
+	{{template "contrib/table" .InjectedData.Table}}         uses a Table
+	{{template "contrib/pagination" .InjectedData.Paging}}   uses a Pagination
+	{{template "contrib/flash" .InjectedData.Flashes}}       uses a []Flash
+The markup uses Bootstrap class names. Source is stored in the contrib directory of this
+package.
+*/
+
+package templates
+
+import (
+	"embed"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+//contribFS holds the source of the contrib partials.
+//
+//go:embed contrib/*.html
+var contribFS embed.FS
+
+//contribDir is the directory in contribFS the contrib partials are stored in.
+const contribDir = "contrib"
+
+//Query parameters read by NewPagination() and set in the URLs built by Pagination.
+const (
+	PageParam  = "page"
+	SortParam  = "sort"
+	OrderParam = "order"
+)
+
+//Levels of flash messages. These match Bootstrap's alert classes.
+const (
+	FlashSuccess = "success"
+	FlashInfo    = "info"
+	FlashWarning = "warning"
+	FlashDanger  = "danger"
+)
+
+//Flash is a message shown to a user once, typically after a form is submitted, i.e.
+//"Settings saved.". Show flash messages with the contrib/flash partial.
+type Flash struct {
+	//Level is the type of message, see FlashSuccess, FlashInfo, etc.
+	Level string
+
+	//Message is the text shown to the user.
+	Message string
+}
+
+//TableColumn is a column shown in the contrib/table partial.
+type TableColumn struct {
+	//Label is the column's header.
+	Label string
+
+	//Field is the name of the field, or map key, shown in the column. This can be a
+	//path to a nested field, i.e. "User.Name".
+	Field string
+
+	//Sortable means the column's header links to sort the table by Field. The table's
+	//Pagination must be set for columns to be sorted.
+	Sortable bool
+}
+
+//Table is the data for the contrib/table partial.
+type Table struct {
+	//Columns is the list of columns shown in the table, in order.
+	Columns []TableColumn
+
+	//Rows is a slice of structs, maps, or pointers to either.
+	Rows interface{}
+
+	//Pagination is used to build links for sortable columns and to show pagination
+	//links below the table. This is optional.
+	Pagination *Pagination
+
+	//Empty is the text shown when there are no rows. This defaults to "No results.".
+	Empty string
+}
+
+//Items returns each row of the table.
+func (t Table) Items() []interface{} {
+	items, _ := sliceItems(t.Rows)
+	out := make([]interface{}, len(items))
+	for i, item := range items {
+		out[i] = item.Interface()
+	}
+
+	return out
+}
+
+//Cell returns the value shown in a row's column. nil is returned if the column's Field
+//does not exist.
+func (t Table) Cell(row interface{}, col TableColumn) interface{} {
+	v, err := fieldValue(reflect.ValueOf(row), col.Field)
+	if err != nil || !isSet(v) {
+		return nil
+	}
+
+	return v.Interface()
+}
+
+//Pagination is the current page, and sorting, of a list of items split into pages.
+//This is used by the contrib/pagination and contrib/table partials to build links that
+//keep the request's other query parameters. Use NewPagination() to build a Pagination
+//from a request.
+type Pagination struct {
+	//Page is the current page, starting at 1.
+	Page int
+
+	//PerPage is the number of items shown on each page.
+	PerPage int
+
+	//Total is the total number of items across all pages.
+	Total int
+
+	//Sort is the field the items are sorted by.
+	Sort string
+
+	//Order is "asc" or "desc".
+	Order string
+
+	//path is the path of the request's URL used when building links.
+	path string
+
+	//query is the request's query parameters kept when building links.
+	query url.Values
+}
+
+//NewPagination returns the Pagination for a request based upon the request's page,
+//sort, and order query parameters. The page is limited to the pages that exist based
+//upon total and perPage. Use Offset() and PerPage to query for the items to show.
+func NewPagination(r *http.Request, total, perPage int) *Pagination {
+	if perPage < 1 {
+		perPage = 1
+	}
+
+	p := &Pagination{
+		PerPage: perPage,
+		Total:   total,
+		query:   url.Values{},
+	}
+	if r != nil && r.URL != nil {
+		p.path = r.URL.Path
+		p.query = r.URL.Query()
+	}
+
+	p.Page, _ = strconv.Atoi(p.query.Get(PageParam))
+	if p.Page < 1 {
+		p.Page = 1
+	}
+	if p.Page > p.Pages() {
+		p.Page = p.Pages()
+	}
+
+	p.Sort = p.query.Get(SortParam)
+	p.Order = "asc"
+	if strings.EqualFold(p.query.Get(OrderParam), "desc") {
+		p.Order = "desc"
+	}
+
+	return p
+}
+
+//Pages returns the number of pages. There is always at least one page.
+func (p *Pagination) Pages() int {
+	if p.PerPage < 1 || p.Total <= p.PerPage {
+		return 1
+	}
+
+	return (p.Total + p.PerPage - 1) / p.PerPage
+}
+
+//Offset returns the number of items before the current page.
+func (p *Pagination) Offset() int {
+	if p.Page < 1 {
+		return 0
+	}
+
+	return (p.Page - 1) * p.PerPage
+}
+
+//HasPrev returns if there is a page before the current page.
+func (p *Pagination) HasPrev() bool {
+	return p.Page > 1
+}
+
+//HasNext returns if there is a page after the current page.
+func (p *Pagination) HasNext() bool {
+	return p.Page < p.Pages()
+}
+
+//PrevURL returns the URL of the page before the current page.
+func (p *Pagination) PrevURL() template.URL {
+	return p.PageURL(p.Page - 1)
+}
+
+//NextURL returns the URL of the page after the current page.
+func (p *Pagination) NextURL() template.URL {
+	return p.PageURL(p.Page + 1)
+}
+
+//PageURL returns the URL of a page, keeping the request's other query parameters.
+func (p *Pagination) PageURL(page int) template.URL {
+	return p.url(map[string]string{PageParam: strconv.Itoa(page)})
+}
+
+//SortURL returns the URL to sort by field. If the items are already sorted by field,
+//the order is reversed. Sorting returns to the first page.
+func (p *Pagination) SortURL(field string) template.URL {
+	order := "asc"
+	if p.IsSortedBy(field) && !p.Descending() {
+		order = "desc"
+	}
+
+	return p.url(map[string]string{SortParam: field, OrderParam: order, PageParam: ""})
+}
+
+//IsSortedBy returns if the items are sorted by field.
+func (p *Pagination) IsSortedBy(field string) bool {
+	return p.Sort != "" && p.Sort == field
+}
+
+//Descending returns if the items are sorted in descending order.
+func (p *Pagination) Descending() bool {
+	return p.Order == "desc"
+}
+
+//PageNumbers returns the page numbers to show links for. The first and last pages,
+//and the pages near the current page, are returned. A 0 is returned in place of each
+//range of skipped pages so that an ellipsis can be shown.
+func (p *Pagination) PageNumbers() (pages []int) {
+	const near = 2
+
+	last := p.Pages()
+	for i := 1; i <= last; i++ {
+		if i == 1 || i == last || (i >= p.Page-near && i <= p.Page+near) {
+			pages = append(pages, i)
+			continue
+		}
+
+		if len(pages) > 0 && pages[len(pages)-1] != 0 {
+			pages = append(pages, 0)
+		}
+	}
+
+	return
+}
+
+//url returns the URL of the request with the provided query parameters set. Blank
+//values remove the query parameter.
+func (p *Pagination) url(set map[string]string) template.URL {
+	q := url.Values{}
+	for k, v := range p.query {
+		q[k] = v
+	}
+	for k, v := range set {
+		if v == "" {
+			q.Del(k)
+			continue
+		}
+		q.Set(k, v)
+	}
+
+	//Collapse leading slashes so that a request for a path such as //example.com/x does
+	//not result in a protocol-relative URL linking to another site.
+	path := p.path
+	if strings.HasPrefix(path, "/") {
+		path = "/" + strings.TrimLeft(path, "/")
+	}
+
+	u := url.URL{Path: path, RawQuery: q.Encode()}
+	return template.URL(u.String())
+}
+
+//parseContrib parses the contrib partials into t, if ContribPartials is set.
+func (c *Config) parseContrib(t *template.Template) error {
+	if !c.ContribPartials {
+		return nil
+	}
+
+	entries, err := fs.ReadDir(contribFS, contribDir)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		p := path.Join(contribDir, e.Name())
+		b, err := contribFS.ReadFile(p)
+		if err != nil {
+			return err
+		}
+
+		_, err = t.New(p).Parse(c.source(b))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
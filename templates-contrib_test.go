@@ -0,0 +1,135 @@
+package templates
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestContribPartials(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	subdirs := []string{"contribs", "contribs-custom"}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Partials are not available unless enabled.
+	c := NewOnDiskConfig(base, subdirs)
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+	w := httptest.NewRecorder()
+	c.Show(w, "contribs", "list", nil)
+	if w.Code != http.StatusInternalServerError {
+		t.Fatal("Contrib partials should not exist", w.Code, w.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	c = NewOnDiskConfig(base, subdirs)
+	c.ContribPartials = true
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	type user struct {
+		Name  string
+		Email string
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	r := httptest.NewRequest(http.MethodGet, "/users?q=a&page=2&sort=Name", nil)
+	data := map[string]interface{}{
+		"Flashes": []Flash{{Level: FlashSuccess, Message: "Saved <now>."}},
+		"Table": Table{
+			Columns: []TableColumn{
+				{Label: "Name", Field: "Name", Sortable: true},
+				{Label: "Email", Field: "Email"},
+			},
+			Rows:       []user{{"Ann", "ann@example.com"}},
+			Pagination: NewPagination(r, 45, 10),
+		},
+	}
+	w = httptest.NewRecorder()
+	c.ShowRequest(w, r, "contribs", "list", data)
+	body := w.Body.String()
+
+	expected := []string{
+		`<div class="alert alert-success" role="status">Saved &lt;now&gt;.</div>`,
+		`aria-sort="ascending"`,
+		`<a href="/users?order=desc&amp;q=a&amp;sort=Name">Name</a>`,
+		`<td>ann@example.com</td>`,
+		`<a class="page-link" href="/users?page=1&amp;q=a&amp;sort=Name" rel="prev">Previous</a>`,
+		`<span class="page-link" aria-current="page">2</span>`,
+		`<a class="page-link" href="/users?page=5&amp;q=a&amp;sort=Name">5</a>`,
+	}
+	for _, e := range expected {
+		if !strings.Contains(body, e) {
+			t.Fatal("Output missing expected markup", e, body)
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Partials can be replaced.
+	w = httptest.NewRecorder()
+	c.Show(w, "contribs-custom", "override", nil)
+	if strings.TrimSpace(w.Body.String()) != "custom flash" {
+		t.Fatal("Contrib partial should have been replaced", w.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestPagination(t *testing.T) {
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	r := httptest.NewRequest(http.MethodGet, "/items?page=50&order=DESC&sort=Date", nil)
+	p := NewPagination(r, 95, 10)
+	if p.Page != 10 || p.Pages() != 10 || p.Offset() != 90 || p.HasNext() || !p.HasPrev() {
+		t.Fatal("Pagination not built as expected", p)
+		return
+	}
+	if !p.Descending() || !p.IsSortedBy("Date") {
+		t.Fatal("Sorting not read as expected", p)
+		return
+	}
+	if u := p.SortURL("Date"); u != "/items?order=asc&sort=Date" {
+		t.Fatal("Sort URL not built as expected", u)
+		return
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.URL.Path = "//example.com/items"
+	p = NewPagination(r, 95, 10)
+	if u := p.SortURL("Date"); u != "/example.com/items?order=asc&sort=Date" {
+		t.Fatal("Leading slashes not collapsed", u)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	p = &Pagination{Page: 6, PerPage: 10, Total: 200}
+	if n := p.PageNumbers(); !reflect.DeepEqual(n, []int{1, 0, 4, 5, 6, 7, 8, 0, 20}) {
+		t.Fatal("Page numbers not returned as expected", n)
+		return
+	}
+	p = NewPagination(nil, 0, 10)
+	if p.Page != 1 || p.Pages() != 1 || p.Offset() != 0 {
+		t.Fatal("Pagination without items not built as expected", p)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
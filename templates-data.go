@@ -0,0 +1,70 @@
+/*
+This file handles how the data provided to templates is exposed. By default, templates
+are provided a RenderData with your data at {{.InjectedData}}. Some teams prefer a
+different name, such as {{.Data}}, or prefer their data at the top level, i.e.
+{{.UserName}} rather than {{.InjectedData.UserName}}.
+
+Set DataKey to use a different name for your data. The data provided to templates is
+then a map with each RenderData field plus your data at DataKey, rather than a
+RenderData.
+
+Set FlattenInjectedData to provide your data to templates as is, as the top level data,
+rather than in a RenderData. Note that the other fields of RenderData, such as
+{{.Development}}, are not available to templates when this is set.
+
+Set MergeInjectedMap to merge your data, when it is a map[string]interface{}, with the
+fields of RenderData into a single map. This allows your data to be used at the top
+level while the fields of RenderData are still available. Keys in your data replace
+the fields of RenderData with the same name. Your data is still available at
+{{.InjectedData}}, or DataKey, as well.
+*/
+
+package templates
+
+//defaultDataKey is the name of the field injected data is provided at by default.
+const defaultDataKey = "InjectedData"
+
+//templateData returns the data to provide to templates based upon the config.
+func (c *Config) templateData(d *RenderData) interface{} {
+	if c.FlattenInjectedData {
+		return d.InjectedData
+	}
+
+	injected, isMap := d.InjectedData.(map[string]interface{})
+	merge := c.MergeInjectedMap && isMap
+	renamed := c.DataKey != "" && c.DataKey != defaultDataKey
+	if !merge && !renamed {
+		return d
+	}
+
+	m := d.toMap()
+	if renamed {
+		delete(m, defaultDataKey)
+		m[c.DataKey] = d.InjectedData
+	}
+	if merge {
+		for k, v := range injected {
+			m[k] = v
+		}
+	}
+
+	return m
+}
+
+//toMap returns the fields of d as a map keyed by field name.
+func (d *RenderData) toMap() map[string]interface{} {
+	return map[string]interface{}{
+		"Development":      d.Development,
+		"Environment":      d.Environment,
+		"UseLocalFiles":    d.UseLocalFiles,
+		"AppVersion":       d.AppVersion,
+		"CacheBustFiles":   d.CacheBustFiles,
+		"TemplatesVersion": d.TemplatesVersion,
+		"BuildTime":        d.BuildTime,
+		"RequestID":        d.RequestID,
+		"InjectedData":     d.InjectedData,
+		"Session":          d.Session,
+		"User":             d.User,
+		"Prefs":            d.Prefs,
+	}
+}
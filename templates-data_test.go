@@ -0,0 +1,124 @@
+package templates
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDataKey(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	subdirs := []string{"data"}
+	c := NewOnDiskConfig(base, subdirs)
+	c.Development = true
+	c.DataKey = "Data"
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	data := map[string]string{"Name": "Jane"}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Data provided at custom key with other fields still available.
+	w := httptest.NewRecorder()
+	c.Show(w, "data", "key", data)
+	if w.Code != http.StatusOK {
+		t.Fatal("Error showing", w.Code, w.Body)
+		return
+	}
+	if strings.TrimSpace(w.Body.String()) != "Jane|true" {
+		t.Fatal("Data not provided at key as expected", w.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Data provided at top level when flattened.
+	c.DataKey = ""
+	c.FlattenInjectedData = true
+	w = httptest.NewRecorder()
+	c.Show(w, "data", "flat", data)
+	if w.Code != http.StatusOK {
+		t.Fatal("Error showing", w.Code, w.Body)
+		return
+	}
+	if strings.TrimSpace(w.Body.String()) != "Jane" {
+		t.Fatal("Data not flattened as expected", w.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//RenderData provided by default.
+	c.FlattenInjectedData = false
+	d := c.renderData(renderView{}, "data", "", data)
+	if _, ok := c.templateData(&d).(*RenderData); !ok {
+		t.Fatal("RenderData should have been provided by default")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestMergeInjectedMap(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	subdirs := []string{"data"}
+	c := NewOnDiskConfig(base, subdirs)
+	c.Development = true
+	c.MergeInjectedMap = true
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Map merged with other fields.
+	w := httptest.NewRecorder()
+	c.Show(w, "data", "merge", map[string]interface{}{"UserName": "jane"})
+	if w.Code != http.StatusOK {
+		t.Fatal("Error showing", w.Code, w.Body)
+		return
+	}
+	if strings.TrimSpace(w.Body.String()) != "jane|true|jane" {
+		t.Fatal("Data not merged as expected", w.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Keys in data replace fields.
+	d := c.renderData(renderView{}, "data", "", map[string]interface{}{"Development": "overridden"})
+	data := c.templateData(&d)
+	m, ok := data.(map[string]interface{})
+	if !ok || m["Development"] != "overridden" {
+		t.Fatal("Key in data should have replaced field", data)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Data that isn't a map is not merged.
+	d = c.renderData(renderView{}, "data", "", "string")
+	if _, ok := c.templateData(&d).(*RenderData); !ok {
+		t.Fatal("RenderData should have been provided for non-map data")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,59 @@
+/*
+This file handles the default config used by the package level funcs.
+
+The primary way of using this package is with your own *Config, created with one of the
+New...Config() funcs, and calling its methods. Each of your sites, or each of your
+tests, can have its own config and configs do not affect each other.
+
+The package level funcs, such as Show() and Build(), are thin wrappers that call the
+same method on the default config, returned by Default(). The Default...Config() funcs
+replace the default config. The default config can be swapped with SetDefault(), and
+reset with ResetDefault(), safely even while package level funcs are being called; a
+call in progress keeps using the config it started with.
+*/
+
+package templates
+
+import (
+	"sync"
+)
+
+var (
+	//defaultConfig is the config used by the package level funcs. Access this with
+	//Default() and SetDefault().
+	defaultConfig = new(Config)
+
+	//defaultMu protects defaultConfig from being replaced while it is being read.
+	defaultMu sync.RWMutex
+)
+
+//Default returns the config used by the package level funcs.
+func Default() *Config {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+
+	return defaultConfig
+}
+
+//SetDefault replaces the config used by the package level funcs and returns the config
+//that was replaced. This is useful for tests, to restore the previous config when a test
+//is done, or for swapping to a config derived with With(). A nil config
+//resets the default config, see ResetDefault().
+func SetDefault(c *Config) (previous *Config) {
+	if c == nil {
+		c = new(Config)
+	}
+
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+
+	previous = defaultConfig
+	defaultConfig = c
+	return
+}
+
+//ResetDefault replaces the config used by the package level funcs with an empty
+//config, the same as when your app starts.
+func ResetDefault() {
+	SetDefault(nil)
+}
@@ -0,0 +1,78 @@
+package templates
+
+import (
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"testing/fstest"
+)
+
+func TestSetDefault(t *testing.T) {
+	previous := Default()
+	defer SetDefault(previous)
+
+	newConfig := func(body string) *Config {
+		c := NewFSConfig(fstest.MapFS{"tpl/app/page.html": {Data: []byte(body)}}, "tpl", []string{"app"})
+		err := c.Build()
+		if err != nil {
+			t.Fatal("Error should not have occured but did", err)
+		}
+		return c
+	}
+	a := newConfig("a")
+	b := newConfig("b")
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Package level funcs use the default config.
+	replaced := SetDefault(a)
+	if replaced != previous {
+		t.Fatal("Previous config should have been returned")
+		return
+	}
+
+	w := httptest.NewRecorder()
+	Show(w, "app", "page", nil)
+	if w.Body.String() != "a" {
+		t.Fatal("Unexpected output", w.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Default config is swapped while package level funcs are called.
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			w := httptest.NewRecorder()
+			Show(w, "app", "page", nil)
+			if body := w.Body.String(); body != "a" && body != "b" {
+				t.Error("Unexpected output", body)
+			}
+		}()
+		go func(i int) {
+			defer wg.Done()
+			if i%2 == 0 {
+				SetDefault(a)
+			} else {
+				SetDefault(b)
+			}
+		}(i)
+	}
+	wg.Wait()
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Reset default config.
+	ResetDefault()
+	if Default() == a || Default() == b {
+		t.Fatal("Default config should have been reset")
+		return
+	}
+	if Default().Hash() != "" {
+		t.Fatal("Default config should not be built")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
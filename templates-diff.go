@@ -0,0 +1,103 @@
+/*
+This file handles comparing the templates of two builds. This is useful for deploy
+tooling to report which template changes are going out, for example by building the
+currently deployed templates and the templates about to be deployed and printing the
+difference.
+
+Templates are compared by the hash of the contents of each source file, calculated
+when templates are built, so files are not reread when comparing. Files are matched by
+their path relative to each config's BasePath, so configs using different base paths,
+or different sources such as on-disk and embedded files, can be compared. Templates
+added with ParseExtra() or ParseUntrusted() are not compared.
+*/
+
+package templates
+
+import (
+	"errors"
+	"path/filepath"
+	"sort"
+)
+
+//ErrNotBuilt is returned when comparing templates of a config that has not been built.
+var ErrNotBuilt = errors.New("templates: templates have not been built, call Build() first")
+
+//TemplateDiff is the difference between the templates of two builds. Each list is
+//sorted and holds paths to source files relative to the config's BasePath, for
+//example "header.html" or "app/dashboard.html", using forward slash separators.
+type TemplateDiff struct {
+	Added   []string
+	Removed []string
+	Changed []string
+}
+
+//Empty returns true if no templates were added, removed, or changed.
+func (d TemplateDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+//Diff returns the templates added, removed, and changed in newConfig compared to
+//oldConfig. Both configs must have been built, ErrNotBuilt is returned otherwise.
+func Diff(oldConfig, newConfig *Config) (d TemplateDiff, err error) {
+	oldFiles, err := oldConfig.builtFileHashes()
+	if err != nil {
+		return
+	}
+	newFiles, err := newConfig.builtFileHashes()
+	if err != nil {
+		return
+	}
+
+	d = diffFiles(oldFiles, newFiles)
+	return
+}
+
+//diffFiles returns the files added, removed, and changed, by hash, in newFiles compared
+//to oldFiles.
+func diffFiles(oldFiles, newFiles map[string]ManifestFile) (d TemplateDiff) {
+	for p, f := range newFiles {
+		of, ok := oldFiles[p]
+		if !ok {
+			d.Added = append(d.Added, p)
+		} else if of.Hash != f.Hash {
+			d.Changed = append(d.Changed, p)
+		}
+	}
+	for p := range oldFiles {
+		if _, ok := newFiles[p]; !ok {
+			d.Removed = append(d.Removed, p)
+		}
+	}
+
+	sort.Strings(d.Added)
+	sort.Strings(d.Removed)
+	sort.Strings(d.Changed)
+	return
+}
+
+//builtFileHashes returns the hash and size of each file found when templates were
+//built.
+func (c *Config) builtFileHashes() (files map[string]ManifestFile, err error) {
+	if c == nil || c.mu == nil {
+		return nil, ErrNotBuilt
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.hash == "" {
+		return nil, ErrNotBuilt
+	}
+
+	return c.fileHashes, nil
+}
+
+//relPath returns the path to a file relative to BasePath, using forward slash
+//separators. The path is returned as is if it is not within BasePath.
+func (c *Config) relPath(path string) string {
+	rel, err := filepath.Rel(c.BasePath, path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+
+	return filepath.ToSlash(rel)
+}
@@ -0,0 +1,75 @@
+package templates
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestDiff(t *testing.T) {
+	oldFS := fstest.MapFS{
+		"old/header.html":    {Data: []byte(`{{define "header"}}<h1>Header</h1>{{end}}`)},
+		"old/app/page.html":  {Data: []byte(`<p>page</p>`)},
+		"old/app/about.html": {Data: []byte(`<p>about</p>`)},
+	}
+	newFS := fstest.MapFS{
+		"new/header.html":   {Data: []byte(`{{define "header"}}<h1>Changed</h1>{{end}}`)},
+		"new/app/page.html": {Data: []byte(`<p>page</p>`)},
+		"new/app/help.html": {Data: []byte(`<p>help</p>`)},
+	}
+
+	oldConfig := NewFSConfig(oldFS, "old", []string{"app"})
+	err := oldConfig.Build()
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	newConfig := NewFSConfig(newFS, "new", []string{"app"})
+	err = newConfig.Build()
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Files are matched relative to each config's base path.
+	d, err := Diff(oldConfig, newConfig)
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	if len(d.Added) != 1 || d.Added[0] != "app/help.html" {
+		t.Fatal("Unexpected added", d.Added)
+		return
+	}
+	if len(d.Removed) != 1 || d.Removed[0] != "app/about.html" {
+		t.Fatal("Unexpected removed", d.Removed)
+		return
+	}
+	if len(d.Changed) != 1 || d.Changed[0] != "header.html" {
+		t.Fatal("Unexpected changed", d.Changed)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Same build.
+	d, err = Diff(oldConfig, oldConfig)
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	if !d.Empty() {
+		t.Fatal("Diff should be empty", d)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Config not built.
+	_, err = Diff(oldConfig, NewFSConfig(newFS, "new", []string{"app"}))
+	if err != ErrNotBuilt {
+		t.Fatal("ErrNotBuilt should have occured but did not", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
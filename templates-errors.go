@@ -0,0 +1,104 @@
+/*
+This file handles responding with an error when a template cannot be shown. By default,
+the error is written to the response as is so that it is easy to diagnose issues during
+development. In production, the error may include internal details such as template
+names or paths, so RedactErrors can be set to respond with a generic message and a
+reference ID instead while the full error is only logged.
+
+A missing subdirectory or template results in a 404 Not Found response, which can be
+changed with NotFoundStatus, while an error executing a template results in a 500
+Internal Server Error response. The response is written with http.Error() by default,
+as plain text. Set ErrorFunc to write a different response, such as a static HTML
+page, or to use a different status code.
+*/
+
+package templates
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+)
+
+//redactedErrorMessage is the message written to the response, followed by a reference
+//ID, when RedactErrors is set.
+const redactedErrorMessage = "An error occurred while displaying this page. Reference: "
+
+//writeError responds with an error and logs the error. The message msg is used as the
+//prefix when logging. If RedactErrors is set, a generic message with a reference ID is
+//written to the response instead of the error. The reference ID is the request's ID,
+//if known, so that the reference can be found in your app's logs; otherwise a random
+//ID is generated.
+func (c *Config) writeError(w http.ResponseWriter, requestID string, status int, msg string, err error) {
+	//Make sure the error is not cached.
+	w.Header().Del("Cache-Control")
+	w.Header().Del("Last-Modified")
+
+	if !c.RedactErrors {
+		c.writeErrorBody(w, status, err.Error())
+
+		//log errors out since they may not always show up in gui
+		if requestID != "" {
+			log.Println(msg, "request_id="+requestID, err)
+		} else {
+			log.Println(msg, err)
+		}
+		return
+	}
+
+	ref := requestID
+	if ref == "" {
+		ref = newErrorReference()
+	}
+
+	c.writeErrorBody(w, status, redactedErrorMessage+ref)
+	log.Println(msg, "ref="+ref, err)
+}
+
+//writeErrorBody writes the error response using ErrorFunc, if set, or http.Error()
+//otherwise.
+func (c *Config) writeErrorBody(w http.ResponseWriter, status int, message string) {
+	if c.ErrorFunc == nil {
+		http.Error(w, message, status)
+		return
+	}
+
+	c.ErrorFunc(w, status, message)
+}
+
+//newErrorReference returns a random ID used to reference an error in logs.
+func newErrorReference() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "unknown"
+	}
+
+	return hex.EncodeToString(b)
+}
+
+//notFoundStatus returns the HTTP status code to use when a subdirectory or template
+//does not exist.
+func (c *Config) notFoundStatus() int {
+	if c.NotFoundStatus == 0 {
+		return http.StatusNotFound
+	}
+
+	return c.NotFoundStatus
+}
+
+//errorStatus returns the HTTP status code to use for an error returned when rendering.
+//Unknown subdirectories and templates are treated as not found, too many renders in
+//progress is treated as unavailable, and any other error is treated as an internal
+//server error.
+func (c *Config) errorStatus(err error) int {
+	if errors.Is(err, ErrUnknownSubDir) || errors.Is(err, ErrTemplateNotFound) {
+		return c.notFoundStatus()
+	}
+	if errors.Is(err, ErrRenderQueueTimeout) {
+		return http.StatusServiceUnavailable
+	}
+
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,177 @@
+package templates
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRedactErrors(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	subdirs := []string{"help"}
+	c := NewOnDiskConfig(base, subdirs)
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	//Capture logging to check the full error is logged.
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Error shown as is when not redacting.
+	w := httptest.NewRecorder()
+	c.Show(w, "missing", "help", nil)
+	if !strings.Contains(w.Body.String(), "invalid subdirectory") {
+		t.Fatal("Error should have been shown as is", w.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Error redacted, with random reference logged.
+	c.RedactErrors = true
+	logged.Reset()
+	w = httptest.NewRecorder()
+	c.Show(w, "missing", "help", nil)
+	if w.Code != http.StatusNotFound {
+		t.Fatal("Wrong status code", w.Code)
+		return
+	}
+	body := w.Body.String()
+	if strings.Contains(body, "invalid subdirectory") || !strings.HasPrefix(body, redactedErrorMessage) {
+		t.Fatal("Error should have been redacted", body)
+		return
+	}
+	ref := strings.TrimSpace(strings.TrimPrefix(body, redactedErrorMessage))
+	if ref == "" || !strings.Contains(logged.String(), "ref="+ref) || !strings.Contains(logged.String(), "invalid subdirectory") {
+		t.Fatal("Full error and reference should have been logged", logged.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Request ID used as reference.
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(defaultRequestIDHeader, "abc123")
+	w = httptest.NewRecorder()
+	c.ShowRequest(w, r, "help", "not-found", nil)
+	if strings.TrimSpace(w.Body.String()) != redactedErrorMessage+"abc123" {
+		t.Fatal("Request ID should have been used as reference", w.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestErrorFunc(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	subdirs := []string{"help"}
+	c := NewOnDiskConfig(base, subdirs)
+	c.RedactErrors = true
+	c.ErrorFunc = func(w http.ResponseWriter, status int, message string) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("<h1>Oops</h1><p>" + message + "</p>"))
+	}
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Custom error response written.
+	w := httptest.NewRecorder()
+	c.Show(w, "missing", "help", nil)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatal("Wrong status code", w.Code)
+		return
+	}
+	if w.Header().Get("Content-Type") != "text/html; charset=utf-8" {
+		t.Fatal("Wrong content type", w.Header().Get("Content-Type"))
+		return
+	}
+	if !strings.HasPrefix(w.Body.String(), "<h1>Oops</h1><p>"+redactedErrorMessage) {
+		t.Fatal("Custom error response not written", w.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestErrorStatus(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	subdirs := []string{"help"}
+	c := NewOnDiskConfig(base, subdirs)
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Missing template returns not found.
+	w := httptest.NewRecorder()
+	c.Show(w, "help", "not-found", nil)
+	if w.Code != http.StatusNotFound {
+		t.Fatal("Wrong status code", w.Code)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Not found status is configurable.
+	c.NotFoundStatus = http.StatusGone
+	w = httptest.NewRecorder()
+	c.Show(w, "missing", "help", nil)
+	if w.Code != http.StatusGone {
+		t.Fatal("Wrong status code", w.Code)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Execution errors return internal server error.
+	if s := c.errorStatus(errors.New("some execution error")); s != http.StatusInternalServerError {
+		t.Fatal("Wrong status code", s)
+		return
+	}
+	if s := c.errorStatus(ErrTemplateNotFound); s != http.StatusGone {
+		t.Fatal("Wrong status code", s)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,89 @@
+/*
+This file handles capturing the data provided to templates as fixtures, files of JSON,
+so that realistic data from your app can be used in tests, such as golden file tests
+of your templates, without having to write the data by hand.
+
+When FixturesDir is set and Development is true, the data provided to each template is
+saved the first time the template is shown. Fixtures are saved to a file per template
+at FixturesDir/subdir/template.json, i.e. fixtures/app/users.json. Existing fixtures
+are never replaced so that a fixture can be edited by hand; delete a fixture to capture
+it again. Load a fixture with LoadFixture().
+
+Data is saved using encoding/json so only exported fields are saved and data that
+cannot be encoded, such as funcs or channels, results in an error being logged. Since
+fixtures are captured from real requests, make sure they do not contain sensitive data
+before committing them.
+*/
+
+package templates
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+//FixturePath returns the path to the fixture for a template within dir. The template's
+//name may include the extension.
+func FixturePath(dir, subdir, templateName string) string {
+	name := strings.TrimSuffix(templateName, filepath.Ext(templateName))
+	return filepath.Join(dir, subdir, name+".json")
+}
+
+//LoadFixture reads the fixture for a template within dir and decodes it into v, which
+//must be a pointer. This is typically the type your app provides to the template.
+func LoadFixture(dir, subdir, templateName string, v interface{}) (err error) {
+	b, err := os.ReadFile(FixturePath(dir, subdir, templateName))
+	if err != nil {
+		return
+	}
+
+	return json.Unmarshal(b, v)
+}
+
+//captureFixture saves the data provided to a template to FixturesDir if the data for
+//the template has not already been saved. Nothing is saved unless FixturesDir is set
+//and Development is true, or if the data is nil. Errors are logged.
+func (c *Config) captureFixture(subdir, templateName string, injectedData interface{}) {
+	if !c.Development || c.FixturesDir == "" || injectedData == nil || c.mu == nil {
+		return
+	}
+
+	path := FixturePath(c.FixturesDir, subdir, templateName)
+
+	c.mu.Lock()
+	if c.captured[path] {
+		c.mu.Unlock()
+		return
+	}
+	if c.captured == nil {
+		c.captured = make(map[string]bool)
+	}
+	c.captured[path] = true
+	c.mu.Unlock()
+
+	//Never replace an existing fixture.
+	if _, err := os.Stat(path); err == nil {
+		return
+	}
+
+	b, err := json.MarshalIndent(injectedData, "", "  ")
+	if err != nil {
+		log.Println("templates.captureFixture", "could not encode data for '"+templateName+"' at subdir '"+subdir+"'", err)
+		return
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		log.Println("templates.captureFixture", "could not create directory for fixture", err)
+		return
+	}
+
+	err = os.WriteFile(path, append(b, '\n'), 0644)
+	if err != nil {
+		log.Println("templates.captureFixture", "could not save fixture", err)
+		return
+	}
+}
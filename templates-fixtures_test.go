@@ -0,0 +1,78 @@
+package templates
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCaptureFixture(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	fixtures := t.TempDir()
+	base := filepath.Join(dir, "_testdata", "templates")
+	c := NewOnDiskConfig(base, []string{"help"})
+	c.FixturesDir = fixtures
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	type data struct {
+		Name  string
+		Items []int
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Not captured outside of Development.
+	c.Show(httptest.NewRecorder(), "help", "version", data{Name: "Bobby"})
+	if _, err := os.Stat(FixturePath(fixtures, "help", "version")); !os.IsNotExist(err) {
+		t.Fatal("Fixture should not have been captured", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Captured on first render only.
+	c.Development = true
+	c.Show(httptest.NewRecorder(), "help", "version", data{Name: "Bobby", Items: []int{1, 2}})
+	c.Show(httptest.NewRecorder(), "help", "version.html", data{Name: "Jimmy"})
+
+	var got data
+	err = LoadFixture(fixtures, "help", "version.html", &got)
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	if got.Name != "Bobby" || len(got.Items) != 2 {
+		t.Fatal("Fixture not as expected", got)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Existing fixtures are not replaced.
+	path := FixturePath(fixtures, "help", "env")
+	err = os.WriteFile(path, []byte(`{"Name":"Edited"}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	c.Show(httptest.NewRecorder(), "help", "env", data{Name: "Bobby"})
+	err = LoadFixture(fixtures, "help", "env", &got)
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	if got.Name != "Edited" {
+		t.Fatal("Fixture should not have been replaced", got)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,33 @@
+/*
+This file handles reading template files from any filesystem implementing io/fs.FS,
+rather than only files stored on-disk or embedded via the embed package. This allows
+templates to be read from a zip archive (archive/zip.Reader), a subdirectory of another
+filesystem (fs.Sub), an fstest.MapFS in tests, or a filesystem you wrote, such as one
+reading from a database.
+
+Set the config's FS field, or use NewFSConfig(), to read files from a filesystem. Paths,
+such as BasePath and the paths of files, are paths within the filesystem and always use
+forward slash separators. Modification times are read from the filesystem if it
+provides them; otherwise the time templates were built is used, the same as with
+embedded files.
+*/
+
+package templates
+
+import (
+	"io/fs"
+)
+
+//fsys returns the filesystem files are read from. FS is used if set, otherwise
+//EmbeddedFS is used if UseEmbedded is true. Nil is returned if files are read from
+//on-disk.
+func (c *Config) fsys() fs.FS {
+	if c.FS != nil {
+		return c.FS
+	}
+	if c.UseEmbedded {
+		return c.EmbeddedFS
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package templates
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestNewFSConfig(t *testing.T) {
+	modTime := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	fsys := fstest.MapFS{
+		"website/templates/header.html":   {Data: []byte(`{{define "header"}}<h1>Header</h1>{{end}}`), ModTime: modTime},
+		"website/templates/app/page.html": {Data: []byte(`{{template "header"}}<p>{{.InjectedData}}</p>`), ModTime: modTime},
+	}
+
+	c := NewFSConfig(fsys, "website/templates", []string{"app"})
+	err := c.Build()
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Templates in a subdirectory inherit templates from the base path.
+	w := httptest.NewRecorder()
+	c.Show(w, "app", "page", "hello")
+	if w.Code != 200 {
+		t.Fatal("Unexpected status", w.Code)
+		return
+	}
+	if body := w.Body.String(); !strings.Contains(body, "<h1>Header</h1>") || !strings.Contains(body, "<p>hello</p>") {
+		t.Fatal("Unexpected output", body)
+		return
+	}
+
+	//Modification times are read from the filesystem.
+	info, err := c.TemplateInfo("app", "page")
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	if !info.ModTime.Equal(modTime) {
+		t.Fatal("Unexpected mod time", info.ModTime)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Unknown subdirectory in the filesystem.
+	c = NewFSConfig(fsys, "website/templates", []string{"missing"})
+	err = c.Build()
+	if err == nil {
+		t.Fatal("Error should have occured but did not")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,174 @@
+/*
+This file handles keeping the previous set of templates alive, after templates are
+rebuilt, until the renders using them finish. This prevents inconsistencies when
+templates are rebuilt while under heavy load, such as a page rendered from the previous
+templates but provided the version or modification time of the new templates, or a
+template being looked up in the new templates while rendering from the previous
+templates.
+
+When a render starts, everything about the subdirectory's templates needed for the
+render is captured at once and the render holds a reference to the set of templates
+that was active. Rebuilding replaces the active set, but in-flight renders keep using
+the set they started with. The previous set is retired once every render holding a
+reference to it has finished. Use WaitForRetired() to wait for this, for example before
+removing the previous version of your template files from disk.
+*/
+
+package templates
+
+import (
+	"context"
+	"html/template"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+//setRefs counts the renders in progress using a set of templates.
+type setRefs struct {
+	renders int64
+	retired int32
+	once    sync.Once
+	done    chan struct{}
+}
+
+//newSetRefs returns the reference counter for a new set of templates.
+func newSetRefs() *setRefs {
+	return &setRefs{done: make(chan struct{})}
+}
+
+//acquire notes that a render using the set started. This must be called while holding
+//the config's read lock so that the set cannot be retired at the same time.
+func (s *setRefs) acquire() {
+	atomic.AddInt64(&s.renders, 1)
+}
+
+//release notes that a render using the set finished.
+func (s *setRefs) release() {
+	if atomic.AddInt64(&s.renders, -1) == 0 && atomic.LoadInt32(&s.retired) == 1 {
+		s.once.Do(func() { close(s.done) })
+	}
+}
+
+//retire notes that the set was replaced. The set's done channel is closed once no
+//renders are using the set.
+func (s *setRefs) retire() {
+	atomic.StoreInt32(&s.retired, 1)
+	if atomic.LoadInt64(&s.renders) == 0 {
+		s.once.Do(func() { close(s.done) })
+	}
+}
+
+//isDone returns true if the set was retired and no renders are using it.
+func (s *setRefs) isDone() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+//renderView is the state of a subdirectory's templates, captured all at once when a
+//render starts, so that a render uses the same templates throughout even if templates
+//are rebuilt while rendering.
+type renderView struct {
+	t         *template.Template
+	named     map[string]*template.Template
+	requests  *requestPool
+	modTime   time.Time
+	hash      string
+	buildTime time.Time
+
+	//untrusted and untrustedLimits are the subdirectory's templates added with
+	//ParseUntrusted(), if any, and their limits.
+	untrusted       *template.Template
+	untrustedLimits map[string]UntrustedLimits
+
+	//memoizable is true if the output of the subdirectory's templates can be cached,
+	//see memoizable().
+	memoizable bool
+}
+
+//acquireView returns the state of a subdirectory's templates for a render. The
+//returned release func must be called once the render is complete so that the set of
+//templates can be retired if templates were rebuilt while rendering.
+func (c *Config) acquireView(subdir string) (v renderView, ok bool, release func()) {
+	release = func() {}
+	if c.mu == nil {
+		return
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	v.t, ok = c.templates[subdir]
+	if !ok {
+		return
+	}
+	v.named = c.named[subdir]
+	v.requests = c.requestTemplates[subdir]
+	v.modTime = c.modTimes[subdir]
+	v.hash = c.hash
+	v.buildTime = c.buildTime
+	v.untrusted = c.untrustedTemplates[subdir]
+	v.untrustedLimits = c.untrustedLimits[subdir]
+	v.memoizable = c.memoizableSubDirs[subdir]
+
+	if refs := c.refs; refs != nil {
+		refs.acquire()
+		release = refs.release
+	}
+
+	return
+}
+
+//retireRefs retires the reference counter for the set of templates being replaced.
+//This must be called while holding the write lock.
+func (c *Config) retireRefs(refs *setRefs) {
+	//Forget sets that no renders are using anymore.
+	retiring := c.retiring[:0]
+	for _, r := range c.retiring {
+		if !r.isDone() {
+			retiring = append(retiring, r)
+		}
+	}
+
+	if c.refs != nil {
+		c.refs.retire()
+		if !c.refs.isDone() {
+			retiring = append(retiring, c.refs)
+		}
+	}
+
+	c.retiring = retiring
+	c.refs = refs
+}
+
+//WaitForRetired waits until renders using templates that were replaced when templates
+//were rebuilt have finished. An error is returned if ctx is done first.
+func (c *Config) WaitForRetired(ctx context.Context) error {
+	if c.mu == nil {
+		return nil
+	}
+
+	c.mu.RLock()
+	retiring := append([]*setRefs(nil), c.retiring...)
+	c.mu.RUnlock()
+
+	for _, r := range retiring {
+		select {
+		case <-r.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+//WaitForRetired waits until renders using replaced templates have finished using the
+//default package level config.
+func WaitForRetired(ctx context.Context) error {
+	return Default().WaitForRetired(ctx)
+}
@@ -0,0 +1,121 @@
+package templates
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestWaitForRetired(t *testing.T) {
+	fsys := fstest.MapFS{
+		"tpl/app/page.html": {Data: []byte(`{{wait}}old {{.TemplatesVersion}}`)},
+	}
+
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	c := NewFSConfig(fsys, "tpl", []string{"app"})
+	c.FuncMap = map[string]interface{}{
+		"wait": func() string {
+			close(started)
+			<-unblock
+			return ""
+		},
+	}
+	err := c.Build()
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	oldHash := c.Hash()
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//A render started before rebuilding finishes using the previous templates.
+	w := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		c.Show(w, "app", "page", nil)
+		close(done)
+	}()
+	<-started
+
+	fsys["tpl/app/page.html"] = &fstest.MapFile{Data: []byte(`new`)}
+	err = c.Build()
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err = c.WaitForRetired(ctx)
+	if err != context.DeadlineExceeded {
+		t.Fatal("Waiting should have timed out since a render is in progress", err)
+		return
+	}
+
+	close(unblock)
+	<-done
+	if w.Body.String() != "old "+oldHash {
+		t.Fatal("Render should have used the previous templates", w.Body.String())
+		return
+	}
+
+	err = c.WaitForRetired(context.Background())
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//New renders use the new templates.
+	w = httptest.NewRecorder()
+	c.Show(w, "app", "page", nil)
+	if w.Body.String() != "new" {
+		t.Fatal("Render should have used the new templates", w.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestAcquireView(t *testing.T) {
+	fsys := fstest.MapFS{
+		"tpl/app/page.html": {Data: []byte(`page`)},
+	}
+
+	c := NewFSConfig(fsys, "tpl", []string{"app"})
+	err := c.Build()
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//A view keeps the state captured when it was acquired.
+	v, ok, release := c.acquireView("app")
+	defer release()
+	if !ok || !v.memoizable || v.untrusted != nil {
+		t.Fatal("View not captured as expected", v)
+		return
+	}
+
+	err = c.ParseUntrusted("app", "tenant-1/home.html", "hello", UntrustedPolicy{Namespace: "tenant-1/"})
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	if _, ok := v.untrustedLimits["tenant-1/home.html"]; ok || v.untrusted != nil {
+		t.Fatal("View should not have been modified")
+		return
+	}
+
+	v2, _, release2 := c.acquireView("app")
+	defer release2()
+	if _, ok := v2.untrustedLimits["tenant-1/home.html"]; !ok || v2.untrusted == nil {
+		t.Fatal("New view should have the untrusted template")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
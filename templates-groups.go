@@ -0,0 +1,83 @@
+/*
+This file handles groups of subdirectories that are parsed together. Normally, each
+subdirectory is parsed separately and can only use templates from itself and the base
+directory. A group parses the files from each of its subdirectories together, along
+with the files in the base directory, so that the subdirectories can share {{define}}
+blocks with each other. This is useful for a family of subdirectories, such as
+reports/daily and reports/monthly, that share templates not needed elsewhere.
+
+Templates in a group are shown using the group's name as the subdirectory, i.e.:
+Show(w, "reports", "daily-summary", nil). When the same filename exists in more than one
+of a group's subdirectories, the file from the subdirectory listed last is used.
+*/
+
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+//validateGroups checks that each group has a valid name and a list of valid
+//subdirectories. A group's name cannot be the same as a subdirectory's name since
+//both are used to show templates.
+func (c *Config) validateGroups() error {
+	for name, subdirs := range c.Groups {
+		if strings.TrimSpace(name) == "" || len(subdirs) == 0 {
+			return ErrInvalidGroup
+		}
+
+		for _, s := range c.SubDirs {
+			if s == name {
+				return ErrInvalidGroup
+			}
+		}
+
+		for _, s := range subdirs {
+			if strings.TrimSpace(s) == "" {
+				return ErrInvalidGroup
+			}
+
+			//Only check on-disk subdirectories exist, the same as for SubDirs.
+			if c.fsys() == nil {
+				if _, err := os.Stat(filepath.Join(c.BasePath, filepath.FromSlash(strings.TrimSpace(s)))); os.IsNotExist(err) {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+//gatherGroupFiles returns the complete paths to the files in each group's
+//subdirectories, with the base file paths appended for inheritance. Groups without any
+//template files are skipped, the same as subdirectories.
+func (c *Config) gatherGroupFiles(ignore ignoreRules, baseFilePaths []string) (files map[string][]string, err error) {
+	files = make(map[string][]string, len(c.Groups))
+	for name, subdirs := range c.Groups {
+		var groupFilepaths []string
+		for _, subDir := range subdirs {
+			completePathToSubDir := filepath.Join(c.BasePath, filepath.FromSlash(strings.TrimSpace(subDir)))
+			if c.fsys() != nil {
+				completePathToSubDir = filepath.ToSlash(completePathToSubDir)
+			}
+
+			subdirFilepaths, innerErr := c.buildPathsToFiles(completePathToSubDir)
+			if innerErr != nil {
+				return nil, innerErr
+			}
+
+			groupFilepaths = append(groupFilepaths, c.filterIgnored(ignore, subdirFilepaths)...)
+		}
+
+		if len(groupFilepaths) == 0 {
+			continue
+		}
+
+		files[name] = append(groupFilepaths, baseFilePaths...)
+	}
+
+	return
+}
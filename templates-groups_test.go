@@ -0,0 +1,77 @@
+package templates
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGroups(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	subdirs := []string{"help"}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Invalid groups.
+	c := NewOnDiskConfig(base, subdirs)
+	c.Groups = map[string][]string{"help": {"reports/daily"}}
+	err = c.Build()
+	if err != ErrInvalidGroup {
+		t.Fatal("ErrInvalidGroup should have occured but didn't", err)
+		return
+	}
+
+	c = NewOnDiskConfig(base, subdirs)
+	c.Groups = map[string][]string{"reports": {}}
+	err = c.Build()
+	if err != ErrInvalidGroup {
+		t.Fatal("ErrInvalidGroup should have occured but didn't", err)
+		return
+	}
+
+	c = NewOnDiskConfig(base, subdirs)
+	c.Groups = map[string][]string{"reports": {"reports/non-existant"}}
+	err = c.Build()
+	if err == nil {
+		t.Fatal("Error should have occured but didn't")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Subdirectories in a group share templates.
+	c = NewOnDiskConfig(base, subdirs)
+	c.Groups = map[string][]string{"reports": {"reports/daily", "reports/monthly"}}
+	err = c.Build()
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+
+	w := httptest.NewRecorder()
+	c.Show(w, "reports", "daily", nil)
+	if w.Code != http.StatusOK {
+		t.Fatal("Error showing", w.Code, w.Body)
+		return
+	}
+	if !strings.Contains(w.Body.String(), "<footer>Report</footer>") {
+		t.Fatal("Template from other subdirectory in group not used", w.Body.String())
+		return
+	}
+
+	w = httptest.NewRecorder()
+	c.Show(w, "help", "help", nil)
+	if w.Code != http.StatusOK {
+		t.Fatal("Error showing", w.Code, w.Body)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,251 @@
+/*
+This file handles setting HTTP headers on responses when templates are shown. Headers
+are set based upon your config prior to the template being rendered so that each of
+your http handlers does not need to set the same headers.
+*/
+
+package templates
+
+import (
+	"io/fs"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+//setHeaders sets the headers on a response based upon the config and the template
+//being shown. This must be called before anything is written to w.
+func (c *Config) setHeaders(w http.ResponseWriter, subdir, templateName string) {
+	if cc := c.cacheControl(subdir, templateName); cc != "" {
+		w.Header().Set("Cache-Control", cc)
+	}
+
+	if len(c.Vary) > 0 {
+		addVary(w, c.Vary...)
+	}
+
+	if sh := c.securityHeaders(subdir); sh != nil {
+		sh.set(w)
+	}
+}
+
+//SecurityHeaders is a set of security related headers set when a template is shown.
+//Blank fields are not set. See DefaultSecurityHeaders() for a sensible set of values.
+type SecurityHeaders struct {
+	//ContentTypeOptions is the value of the X-Content-Type-Options header.
+	ContentTypeOptions string
+
+	//ReferrerPolicy is the value of the Referrer-Policy header.
+	ReferrerPolicy string
+
+	//FrameOptions is the value of the X-Frame-Options header.
+	FrameOptions string
+
+	//ContentSecurityPolicy is the value of the Content-Security-Policy header. This is
+	//very specific to each app so it is not set by DefaultSecurityHeaders().
+	ContentSecurityPolicy string
+}
+
+//DefaultSecurityHeaders returns a sensible set of security headers for server rendered
+//pages. A Content-Security-Policy is not set since the policy depends on the scripts,
+//styles, and other resources your pages use.
+func DefaultSecurityHeaders() *SecurityHeaders {
+	return &SecurityHeaders{
+		ContentTypeOptions: "nosniff",
+		ReferrerPolicy:     "strict-origin-when-cross-origin",
+		FrameOptions:       "DENY",
+	}
+}
+
+//set sets the security headers on a response. Headers already set on the response, for
+//example by your http handler, are not replaced.
+func (sh *SecurityHeaders) set(w http.ResponseWriter) {
+	headers := [][2]string{
+		{"X-Content-Type-Options", sh.ContentTypeOptions},
+		{"Referrer-Policy", sh.ReferrerPolicy},
+		{"X-Frame-Options", sh.FrameOptions},
+		{"Content-Security-Policy", sh.ContentSecurityPolicy},
+	}
+
+	for _, h := range headers {
+		if h[1] == "" || w.Header().Get(h[0]) != "" {
+			continue
+		}
+
+		w.Header().Set(h[0], h[1])
+	}
+}
+
+//securityHeaders returns the security headers for a subdirectory. The headers for the
+//subdirectory are preferred over the headers set for all subdirectories. nil is
+//returned if no headers are set.
+func (c *Config) securityHeaders(subdir string) *SecurityHeaders {
+	if sh, ok := c.SubDirSecurityHeaders[subdir]; ok {
+		return sh
+	}
+
+	return c.SecurityHeaders
+}
+
+//cacheControl returns the Cache-Control header value for a template. The value for
+//the specific template is preferred over the value for the template's subdirectory. A
+//blank string is returned if no value is set for the template or subdirectory.
+func (c *Config) cacheControl(subdir, templateName string) string {
+	if len(c.CacheControl) == 0 {
+		return ""
+	}
+
+	//Check for a value for the template, with or without the extension. Templates shown
+	//without a subdirectory are keyed with a leading slash so that the key for a
+	//template cannot match the key for a subdirectory with the same name.
+	prefix := subdir + "/"
+	if subdir == "" {
+		prefix = "/"
+	}
+	if v, ok := c.CacheControl[prefix+templateName]; ok {
+		return v
+	}
+	if v, ok := c.CacheControl[prefix+strings.TrimSuffix(templateName, "."+c.Extension)]; ok {
+		return v
+	}
+
+	//Check for a value for the subdirectory.
+	return c.CacheControl[subdir]
+}
+
+//addVary adds header names to the Vary header of a response. Header names that already
+//exist in the Vary header are not added again. This should be used anytime the
+//response differs based upon a request's headers, for example when selecting a
+//template based upon the Accept-Language header, so that caches do not serve the
+//wrong response.
+func addVary(w http.ResponseWriter, headers ...string) {
+	existing := make(map[string]bool)
+	for _, v := range w.Header().Values("Vary") {
+		for _, h := range strings.Split(v, ",") {
+			existing[http.CanonicalHeaderKey(strings.TrimSpace(h))] = true
+		}
+	}
+
+	//A Vary value of "*" already covers every header.
+	if existing["*"] {
+		return
+	}
+
+	for _, h := range headers {
+		h = http.CanonicalHeaderKey(strings.TrimSpace(h))
+		if h == "" || existing[h] {
+			continue
+		}
+
+		w.Header().Add("Vary", h)
+		existing[h] = true
+	}
+}
+
+//LastModifier is implemented by data provided to templates that knows when it was last
+//modified. When ConditionalGET is enabled, data implementing this interface is used,
+//along with the modification time of the template files, to respond to conditional
+//requests.
+type LastModifier interface {
+	LastModified() time.Time
+}
+
+//notModified handles conditional GET requests when ConditionalGET is enabled. This sets
+//the Last-Modified header on the response and, if the request's If-Modified-Since header
+//is not older than the last modification time, writes a 304 Not Modified response and
+//returns true. The last modification time is the newer of lastModified, see
+//outputModTime(), and the injected data's LastModified(). Only nil injected data or
+//injected data implementing LastModifier is handled since the modification time of any
+//other data is not known.
+func (c *Config) notModified(w http.ResponseWriter, r *http.Request, lastModified time.Time, injectedData interface{}) bool {
+	if !c.ConditionalGET || r == nil {
+		return false
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	if injectedData != nil {
+		lm, ok := injectedData.(LastModifier)
+		if !ok {
+			return false
+		}
+
+		if t := lm.LastModified(); t.After(lastModified) {
+			lastModified = t
+		}
+	}
+	if lastModified.IsZero() {
+		return false
+	}
+
+	//HTTP dates only have a resolution of seconds.
+	lastModified = lastModified.Truncate(time.Second)
+	w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+
+	ims, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil || lastModified.After(ims) {
+		return false
+	}
+
+	//Remove headers that should not be sent with a 304 response.
+	h := w.Header()
+	h.Del("Content-Type")
+	h.Del("Content-Length")
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+//newestModTime returns the newest modification time of the files at the provided paths.
+//Embedded files, and files in filesystems that do not provide modification times, do
+//not have a modification time so the current time is returned instead; this results in
+//the time templates were built being used.
+func (c *Config) newestModTime(paths []string) (newest time.Time, err error) {
+	if c.UseEmbedded && c.FS == nil {
+		return time.Now(), nil
+	}
+	defer func() {
+		if err == nil && newest.IsZero() && c.fsys() != nil {
+			newest = time.Now()
+		}
+	}()
+
+	for _, p := range paths {
+		var fi fs.FileInfo
+		fi, err = c.stat(filepath.FromSlash(p))
+		if err != nil {
+			return
+		}
+
+		if fi.ModTime().After(newest) {
+			newest = fi.ModTime()
+		}
+	}
+
+	return
+}
+
+//outputModTime returns the time the output of a template, shown without any data based
+//upon the request, last changed. This is the newest of the modification time of the
+//template files, the time the config fields provided to templates last changed, and,
+//if no data is injected, the time the page's prerendered output last changed.
+func (c *Config) outputModTime(modTime time.Time, subdir, templateName string, injectedData interface{}) time.Time {
+	if c.mu == nil {
+		return modTime
+	}
+
+	c.mu.RLock()
+	configChangedAt := c.configChangedAt
+	prerenderedAt := c.prerenderedAt[PrerenderPage{Subdir: subdir, Name: templateName}]
+	c.mu.RUnlock()
+
+	if configChangedAt.After(modTime) {
+		modTime = configChangedAt
+	}
+	if injectedData == nil && prerenderedAt.After(modTime) {
+		modTime = prerenderedAt
+	}
+
+	return modTime
+}
@@ -0,0 +1,298 @@
+package templates
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCacheControl(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	subdirs := []string{"app", "help"}
+	c := NewOnDiskConfig(base, subdirs)
+	c.CacheControl = map[string]string{
+		"app":          "no-store",
+		"help":         "public, max-age=3600",
+		"help/version": "no-cache",
+	}
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Value for subdirectory.
+	w := httptest.NewRecorder()
+	c.Show(w, "app", "app", nil)
+	if w.Code != http.StatusOK {
+		t.Fatal("Error showing", w.Code, w.Body)
+		return
+	}
+	if v := w.Header().Get("Cache-Control"); v != "no-store" {
+		t.Fatal("Cache-Control not set as expected", v)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Value for template is preferred over subdirectory.
+	w = httptest.NewRecorder()
+	c.Show(w, "help", "version", nil)
+	if v := w.Header().Get("Cache-Control"); v != "no-cache" {
+		t.Fatal("Cache-Control not set as expected", v)
+		return
+	}
+	w = httptest.NewRecorder()
+	c.Show(w, "help", "help", nil)
+	if v := w.Header().Get("Cache-Control"); v != "public, max-age=3600" {
+		t.Fatal("Cache-Control not set as expected", v)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Errors should not be cached.
+	w = httptest.NewRecorder()
+	c.Show(w, "help", "non-existant-template", nil)
+	if v := w.Header().Get("Cache-Control"); v != "" {
+		t.Fatal("Cache-Control should not be set on errors", v)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Templates without a subdirectory do not match a subdirectory with the same name.
+	if v := c.cacheControl("", "app.html"); v != "" {
+		t.Fatal("Cache-Control for subdirectory should not have been used", v)
+		return
+	}
+	c.CacheControl["/app"] = "private"
+	if v := c.cacheControl("", "app.html"); v != "private" {
+		t.Fatal("Cache-Control not returned as expected", v)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestAddVary(t *testing.T) {
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Headers are added without duplicates.
+	w := httptest.NewRecorder()
+	w.Header().Set("Vary", "Accept-Encoding")
+	addVary(w, "accept-encoding", "Accept-Language", "Accept-Language")
+	v := w.Header().Values("Vary")
+	if len(v) != 2 || v[0] != "Accept-Encoding" || v[1] != "Accept-Language" {
+		t.Fatal("Vary not set as expected", v)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Nothing is added when Vary is already "*".
+	w = httptest.NewRecorder()
+	w.Header().Set("Vary", "*")
+	addVary(w, "Accept")
+	v = w.Header().Values("Vary")
+	if len(v) != 1 || v[0] != "*" {
+		t.Fatal("Vary should not have been modified", v)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+type testLastModifiedData struct {
+	t time.Time
+}
+
+func (d testLastModifiedData) LastModified() time.Time {
+	return d.t
+}
+
+func TestConditionalGET(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	subdirs := []string{"app", "help"}
+	c := NewOnDiskConfig(base, subdirs)
+	c.ConditionalGET = true
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Last-Modified is set.
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	c.ShowRequest(w, r, "app", "app", nil)
+	if w.Code != http.StatusOK {
+		t.Fatal("Error showing", w.Code, w.Body)
+		return
+	}
+	lastModified := w.Header().Get("Last-Modified")
+	if lastModified == "" {
+		t.Fatal("Last-Modified not set")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Conditional request returns 304.
+	r.Header.Set("If-Modified-Since", lastModified)
+	w = httptest.NewRecorder()
+	c.ShowRequest(w, r, "app", "app", nil)
+	if w.Code != http.StatusNotModified {
+		t.Fatal("Not modified response not returned", w.Code)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Changing config fields provided to templates returns the page. Last-Modified only
+	//has second precision.
+	time.Sleep(time.Second)
+	c.SetAppVersion("2.0.0")
+	w = httptest.NewRecorder()
+	c.ShowRequest(w, r, "app", "app", nil)
+	if w.Code != http.StatusOK {
+		t.Fatal("Page should have been returned after config changed", w.Code)
+		return
+	}
+	r.Header.Set("If-Modified-Since", w.Header().Get("Last-Modified"))
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Pages using data based upon the request are not handled.
+	c.UserProvider = func(r *http.Request) interface{} { return "user" }
+	w = httptest.NewRecorder()
+	c.ShowRequest(w, r, "app", "app", nil)
+	if w.Code != http.StatusOK {
+		t.Fatal("Page should have been returned", w.Code)
+		return
+	}
+	if w.Header().Get("Last-Modified") != "" {
+		t.Fatal("Last-Modified should not be set")
+		return
+	}
+	c.UserProvider = nil
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Data modified after the templates returns the page.
+	w = httptest.NewRecorder()
+	c.ShowRequest(w, r, "app", "app", testLastModifiedData{time.Now().Add(time.Hour)})
+	if w.Code != http.StatusOK {
+		t.Fatal("Page should have been returned", w.Code)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Data with an unknown modification time is not handled.
+	w = httptest.NewRecorder()
+	c.ShowRequest(w, r, "app", "app", "some data")
+	if w.Code != http.StatusOK {
+		t.Fatal("Page should have been returned", w.Code)
+		return
+	}
+	if w.Header().Get("Last-Modified") != "" {
+		t.Fatal("Last-Modified should not be set")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestSecurityHeaders(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	subdirs := []string{"app", "help"}
+	c := NewOnDiskConfig(base, subdirs)
+	c.SecurityHeaders = DefaultSecurityHeaders()
+	c.SubDirSecurityHeaders = map[string]*SecurityHeaders{
+		"help": {
+			ContentTypeOptions:    "nosniff",
+			FrameOptions:          "SAMEORIGIN",
+			ContentSecurityPolicy: "default-src 'self'",
+		},
+	}
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Default headers.
+	w := httptest.NewRecorder()
+	c.Show(w, "app", "app", nil)
+	if w.Code != http.StatusOK {
+		t.Fatal("Error showing", w.Code, w.Body)
+		return
+	}
+	if v := w.Header().Get("X-Content-Type-Options"); v != "nosniff" {
+		t.Fatal("X-Content-Type-Options not set as expected", v)
+		return
+	}
+	if v := w.Header().Get("Referrer-Policy"); v != "strict-origin-when-cross-origin" {
+		t.Fatal("Referrer-Policy not set as expected", v)
+		return
+	}
+	if v := w.Header().Get("X-Frame-Options"); v != "DENY" {
+		t.Fatal("X-Frame-Options not set as expected", v)
+		return
+	}
+	if v := w.Header().Get("Content-Security-Policy"); v != "" {
+		t.Fatal("Content-Security-Policy should not be set by default", v)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Headers for subdirectory.
+	w = httptest.NewRecorder()
+	c.Show(w, "help", "help", nil)
+	if v := w.Header().Get("X-Frame-Options"); v != "SAMEORIGIN" {
+		t.Fatal("X-Frame-Options not set as expected", v)
+		return
+	}
+	if v := w.Header().Get("Content-Security-Policy"); v != "default-src 'self'" {
+		t.Fatal("Content-Security-Policy not set as expected", v)
+		return
+	}
+	if v := w.Header().Get("Referrer-Policy"); v != "" {
+		t.Fatal("Referrer-Policy should not be set for subdirectory", v)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Headers set by handler are not replaced.
+	w = httptest.NewRecorder()
+	w.Header().Set("X-Frame-Options", "SAMEORIGIN")
+	c.Show(w, "app", "app", nil)
+	if v := w.Header().Get("X-Frame-Options"); v != "SAMEORIGIN" {
+		t.Fatal("X-Frame-Options should not be replaced", v)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
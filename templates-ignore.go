@@ -0,0 +1,195 @@
+/*
+This file handles ignoring template files listed in an ignore file stored at BasePath.
+This allows for keeping drafts or scratch files alongside your templates without them
+being parsed, and therefore without them being able to be shown.
+
+The ignore file uses gitignore-style patterns, one per line, relative to BasePath:
+  - Blank lines and lines beginning with # are ignored.
+  - * matches anything except a /, ? matches any single character except a /, and
+    [abc] matches any character in the set.
+  - ** matches any number of directories, i.e.: docs/** matches every file in docs.
+  - A pattern with a / at the beginning or middle is matched against the full path
+    relative to BasePath, otherwise the pattern is matched against the name of any
+    file or directory.
+  - A pattern ending with / only matches directories.
+  - A pattern beginning with ! re-includes a file ignored by a previous pattern. A
+    file cannot be re-included if its directory is ignored.
+
+Note that when using embedded files, files beginning with a period are not embedded
+unless the all: prefix is used in your go:embed directive, i.e.: //go:embed all:templates.
+*/
+
+package templates
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+//IgnoreFileName is the name of the file, stored at BasePath, that lists patterns of
+//template files to ignore.
+const IgnoreFileName = ".templatesignore"
+
+//ignoreRule is a single pattern from an ignore file.
+type ignoreRule struct {
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+//ignoreRules is the list of patterns from an ignore file, in the order they were
+//listed.
+type ignoreRules []ignoreRule
+
+//readIgnoreFile reads and parses the ignore file at BasePath. No rules are returned if
+//the ignore file does not exist.
+func (c *Config) readIgnoreFile() (rules ignoreRules, err error) {
+	b, err := c.readFile(filepath.Join(c.BasePath, IgnoreFileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return
+	}
+
+	return parseIgnoreRules(b)
+}
+
+//parseIgnoreRules parses the patterns in the contents of an ignore file.
+func parseIgnoreRules(b []byte) (rules ignoreRules, err error) {
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var rule ignoreRule
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+
+		//A pattern without a / is matched against the name of a file or directory at
+		//any depth.
+		anchored := strings.Contains(line, "/")
+		line = strings.TrimPrefix(line, "/")
+		if line == "" {
+			continue
+		}
+
+		expr := "^" + globToRegexp(line) + "$"
+		if !anchored {
+			expr = "^(.*/)?" + globToRegexp(line) + "$"
+		}
+
+		rule.re, err = regexp.Compile(expr)
+		if err != nil {
+			return nil, errors.New("templates: invalid pattern '" + scanner.Text() + "' in " + IgnoreFileName)
+		}
+
+		rules = append(rules, rule)
+	}
+
+	err = scanner.Err()
+	return
+}
+
+//globToRegexp converts a gitignore-style glob pattern to a regular expression.
+func globToRegexp(pattern string) string {
+	var sb strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		ch := pattern[i]
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			sb.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case ch == '*':
+			sb.WriteString("[^/]*")
+		case ch == '?':
+			sb.WriteString("[^/]")
+		case ch == '[':
+			end := strings.IndexByte(pattern[i:], ']')
+			if end < 0 {
+				sb.WriteString(regexp.QuoteMeta(string(ch)))
+				continue
+			}
+
+			class := pattern[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + class + "]")
+			i += end
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(ch)))
+		}
+	}
+
+	return sb.String()
+}
+
+//matches returns if a path, relative to BasePath and using / separators, is ignored
+//by the rules. The last matching rule is used. A path is also ignored if any of its
+//directories are ignored.
+func (rules ignoreRules) matches(relPath string, isDir bool) bool {
+	//Check if any of the path's directories are ignored.
+	dirs := strings.Split(relPath, "/")
+	for i := 1; i < len(dirs); i++ {
+		if rules.match(strings.Join(dirs[:i], "/"), true) {
+			return true
+		}
+	}
+
+	return rules.match(relPath, isDir)
+}
+
+//match returns if a single path is ignored by the rules, not taking into account the
+//path's directories.
+func (rules ignoreRules) match(relPath string, isDir bool) (ignored bool) {
+	for _, r := range rules {
+		if r.dirOnly && !isDir {
+			continue
+		}
+		if r.re.MatchString(relPath) {
+			ignored = !r.negate
+		}
+	}
+
+	return
+}
+
+//filterIgnored removes the paths that are ignored by the rules. Paths are complete paths
+//built from BasePath.
+func (c *Config) filterIgnored(rules ignoreRules, paths []string) (kept []string) {
+	if len(rules) == 0 {
+		return paths
+	}
+
+	for _, p := range paths {
+		rel, err := filepath.Rel(c.BasePath, p)
+		if err != nil {
+			kept = append(kept, p)
+			continue
+		}
+
+		if rules.matches(filepath.ToSlash(rel), false) {
+			continue
+		}
+
+		kept = append(kept, p)
+	}
+
+	return
+}
@@ -0,0 +1,93 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseIgnoreRules(t *testing.T) {
+	rules, err := parseIgnoreRules([]byte("#comment\n\ndrafts/\n*.bak.html\n/root.html\ndocs/**\na/**/b.html\n!keep.bak.html\n"))
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+
+	tests := []struct {
+		path    string
+		ignored bool
+	}{
+		{"drafts/page.html", true},
+		{"pages/drafts/page.html", true},
+		{"drafts.html", false},
+		{"page.bak.html", true},
+		{"pages/page.bak.html", true},
+		{"keep.bak.html", false},
+		{"root.html", true},
+		{"pages/root.html", false},
+		{"docs/faq.html", true},
+		{"a/b.html", true},
+		{"a/x/y/b.html", true},
+		{"page.html", false},
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	for _, tt := range tests {
+		if rules.matches(tt.path, false) != tt.ignored {
+			t.Fatal("Path not matched as expected", tt.path, tt.ignored)
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestBuildIgnoreFile(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "ignore")
+	subdirs := []string{"pages", "drafts"}
+	c := NewOnDiskConfig(base, subdirs)
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Ignored files are not parsed.
+	expected := map[string]bool{
+		"header.html":       true,
+		"index.html":        true,
+		"scratch-keep.html": true,
+	}
+	for _, p := range c.files["pages"] {
+		if !expected[filepath.Base(p)] {
+			t.Fatal("File should have been ignored", p)
+			return
+		}
+		delete(expected, filepath.Base(p))
+	}
+	if len(expected) != 0 {
+		t.Fatal("Files should not have been ignored", expected)
+		return
+	}
+	for _, p := range c.files[""] {
+		if filepath.Base(p) == "scratch-1.html" {
+			t.Fatal("File should have been ignored", p)
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Ignored subdirectories are not built.
+	if _, ok := c.files["drafts"]; ok {
+		t.Fatal("Subdirectory should have been ignored")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,221 @@
+/*
+This file handles providing information about the source files templates were built
+from. This is useful for tooling and debugging pages to determine which file a page
+was built from and which files were parsed alongside it.
+
+This file also handles estimating the memory used by each subdirectory's parsed
+templates. Parsed templates are kept in memory for the life of your app and, since
+files in the base directory are parsed into each subdirectory, a large number of
+subdirectories or templates can use a surprising amount of memory.
+*/
+
+package templates
+
+import (
+	"html/template"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/template/parse"
+	"time"
+)
+
+//SourceInfo is information about the source file a template was parsed from.
+type SourceInfo struct {
+	//Subdir is the subdirectory the template was shown from.
+	Subdir string
+
+	//Name is the name of the template, including the extension.
+	Name string
+
+	//Path is the complete path to the source file the template was parsed from.
+	Path string
+
+	//Size is the size of the source file in bytes.
+	Size int64
+
+	//ModTime is the modification time of the source file. This will be the zero time
+	//for embedded files, and files in filesystems that do not provide modification
+	//times, since these files do not have a modification time.
+	ModTime time.Time
+
+	//Files is the list of complete paths to each file parsed into the subdirectory's
+	//templates, including files inherited from the base directory. These are the files
+	//that the template could reference.
+	Files []string
+}
+
+//TemplateInfo returns information about the source file for a template. The template
+//is looked up using the subdirectory and template name the same as Show(). If the same
+//filename exists in the subdirectory and in the base directory, the information for the
+//file that is actually used is returned.
+func (c *Config) TemplateInfo(subdir, templateName string) (info SourceInfo, err error) {
+	if c.mu == nil {
+		return info, ErrUnknownSubDir
+	}
+
+	//Add the extension to the template name if needed, the same as Show().
+	if filepath.Ext(templateName) == "" {
+		templateName += "." + c.Extension
+	}
+
+	c.mu.RLock()
+	paths, ok := c.files[subdir]
+	c.mu.RUnlock()
+	if !ok {
+		return info, ErrUnknownSubDir
+	}
+
+	//Find the file for the template. The last matching file is used since that is the
+	//file that would be used when parsing; files parsed later replace earlier files
+	//with the same name.
+	var path string
+	for _, p := range paths {
+		if filepath.Base(p) == templateName {
+			path = p
+		}
+	}
+	if path == "" {
+		return info, ErrTemplateNotFound
+	}
+
+	fi, err := c.stat(path)
+	if err != nil {
+		return
+	}
+
+	info = SourceInfo{
+		Subdir:  subdir,
+		Name:    templateName,
+		Path:    path,
+		Size:    fi.Size(),
+		ModTime: fi.ModTime(),
+		Files:   append([]string(nil), paths...),
+	}
+	return
+}
+
+//TemplateInfo returns information about the source file for a template using the
+//default package level config.
+func TemplateInfo(subdir, templateName string) (info SourceInfo, err error) {
+	return Default().TemplateInfo(subdir, templateName)
+}
+
+//stat returns information about a file from on-disk files or the config's filesystem,
+//see fsys().
+func (c *Config) stat(path string) (fs.FileInfo, error) {
+	if fsys := c.fsys(); fsys != nil {
+		return fs.Stat(fsys, filepath.ToSlash(path))
+	}
+
+	return os.Stat(path)
+}
+
+//MemoryInfo is an estimate of the memory used by a subdirectory's parsed templates.
+//This is not an exact measurement of memory used but is useful for comparing
+//subdirectories to determine which use the most memory.
+type MemoryInfo struct {
+	//Subdir is the subdirectory. The base directory is "".
+	Subdir string
+
+	//Files is the number of files parsed into the subdirectory's templates, including
+	//files inherited from the base directory.
+	Files int
+
+	//SourceBytes is the total size of the files parsed into the subdirectory's
+	//templates.
+	SourceBytes int64
+
+	//Nodes is the number of nodes in the parse trees of the subdirectory's templates.
+	//Each node is an action, text, or other part of a template and memory used grows
+	//with the number of nodes.
+	Nodes int
+
+	//RequestScoped is true if the subdirectory's templates use request-scoped funcs.
+	//These subdirectories keep extra copies of their templates for binding the funcs
+	//to requests, an unexecuted copy plus one copy per request being rendered at once,
+	//so memory used is at least doubled.
+	RequestScoped bool
+}
+
+//MemoryReport returns an estimate of the memory used by each subdirectory's parsed
+//templates. Subdirectories are sorted by the size of their source files, largest first,
+//so that the subdirectories using the most memory are listed first.
+func (c *Config) MemoryReport() (report []MemoryInfo, err error) {
+	if c.mu == nil {
+		return
+	}
+
+	c.mu.RLock()
+	files := make(map[string][]string, len(c.files))
+	nodeCounts := make(map[string]int, len(c.files))
+	for subdir, paths := range c.files {
+		files[subdir] = paths
+		nodeCounts[subdir] = c.nodeCounts[subdir]
+	}
+	requestScoped := make(map[string]bool, len(c.requestTemplates))
+	for subdir := range c.requestTemplates {
+		requestScoped[subdir] = true
+	}
+	c.mu.RUnlock()
+
+	//The same file is listed for many subdirectories so cache the size of each file.
+	sizes := make(map[string]int64)
+	for subdir, paths := range files {
+		info := MemoryInfo{
+			Subdir:        subdir,
+			Files:         len(paths),
+			Nodes:         nodeCounts[subdir],
+			RequestScoped: requestScoped[subdir],
+		}
+
+		for _, p := range paths {
+			size, ok := sizes[p]
+			if !ok {
+				fi, innerErr := c.stat(p)
+				if innerErr != nil {
+					return nil, innerErr
+				}
+				size = fi.Size()
+				sizes[p] = size
+			}
+
+			info.SourceBytes += size
+		}
+
+		report = append(report, info)
+	}
+
+	sort.Slice(report, func(i, j int) bool {
+		if report[i].SourceBytes != report[j].SourceBytes {
+			return report[i].SourceBytes > report[j].SourceBytes
+		}
+		return report[i].Subdir < report[j].Subdir
+	})
+
+	return
+}
+
+//MemoryReport returns an estimate of the memory used by each subdirectory's parsed
+//templates using the default package level config.
+func MemoryReport() (report []MemoryInfo, err error) {
+	return Default().MemoryReport()
+}
+
+//countNodes returns the number of nodes in the parse trees of a set of templates. This
+//must be called before the templates are executed since executing html templates
+//modifies the parse trees.
+func countNodes(t *template.Template) (count int) {
+	for _, tmpl := range t.Templates() {
+		if tmpl.Tree == nil {
+			continue
+		}
+
+		walkNodes(tmpl.Tree.Root, func(parse.Node) {
+			count++
+		})
+	}
+
+	return
+}
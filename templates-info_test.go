@@ -0,0 +1,130 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTemplateInfo(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	subdirs := []string{"app", "help"}
+	c := NewOnDiskConfig(base, subdirs)
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Info for template in subdirectory.
+	info, err := c.TemplateInfo("app", "app")
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	if info.Path != filepath.Join(base, "app", "app.html") {
+		t.Fatal("Path not returned as expected", info.Path)
+		return
+	}
+	if info.ModTime.IsZero() {
+		t.Fatal("ModTime not returned as expected")
+		return
+	}
+	if len(info.Files) < 2 {
+		t.Fatal("Files should include subdirectory and inherited files", info.Files)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Info for inherited template.
+	info, err = c.TemplateInfo("app", "header.html")
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	if info.Path != filepath.Join(base, "header.html") {
+		t.Fatal("Path not returned as expected", info.Path)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Unknown template and subdirectory.
+	_, err = c.TemplateInfo("app", "non-existant-template")
+	if err != ErrTemplateNotFound {
+		t.Fatal("ErrTemplateNotFound should have occured but didn't", err)
+		return
+	}
+	_, err = c.TemplateInfo("non-existant-subdir", "app")
+	if err != ErrUnknownSubDir {
+		t.Fatal("ErrUnknownSubDir should have occured but didn't", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestMemoryReport(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	subdirs := []string{"app", "help"}
+	c := NewOnDiskConfig(base, subdirs)
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//No report before building.
+	report, err := c.MemoryReport()
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	if len(report) != 0 {
+		t.Fatal("Report should be empty before building", report)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Report for each subdirectory, largest first.
+	report, err = c.MemoryReport()
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	if len(report) != len(c.files) {
+		t.Fatal("Report should include each subdirectory", report)
+		return
+	}
+	for i, info := range report {
+		if info.Files == 0 {
+			t.Fatal("Memory info not populated as expected", info)
+			return
+		}
+		if info.Subdir == "help" && (info.SourceBytes == 0 || info.Nodes == 0) {
+			t.Fatal("Memory info not populated as expected", info)
+			return
+		}
+		if i > 0 && info.SourceBytes > report[i-1].SourceBytes {
+			t.Fatal("Report not sorted largest first", report)
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,261 @@
+/*
+This file handles limits that safeguard against templates consuming too many resources
+when rendered. A template that includes itself, directly or via other templates, or a
+range over unexpectedly large data can result in huge output or exhaust memory. These
+limits are optional and are not enforced unless set in your config.
+
+MaxIncludeDepth limits how deeply templates can be nested via {{template "name"}}. This
+is checked when templates are parsed, rather than when templates are rendered, so that
+an issue is found when your app starts rather than when a user views a page. Since
+recursion cannot be bounded when parsing, recursive templates are not allowed when this
+limit is set.
+
+MaxOutputBytes limits the size of the output of a single render. When this limit is
+set, Show() renders the template fully before writing anything so that an error
+response can be returned if the limit is exceeded.
+
+MaxFiles, MaxFileSize, and MaxTotalBytes limit the template files that are parsed when
+templates are built. These protect against accidentally parsing huge or runaway
+inputs, for example when templates are loaded from a remote source.
+
+MaxConcurrentRenders limits the number of templates being rendered at once. Renders
+beyond the limit wait for a render to complete, for up to RenderQueueTimeout, so that
+a burst of requests for heavy templates degrades gracefully rather than spiking
+memory. A request that waits too long is responded to with 503 Service Unavailable.
+*/
+
+package templates
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"strconv"
+	"text/template/parse"
+)
+
+//Errors returned when a limit is exceeded.
+var (
+	//ErrMaxOutputBytes is returned when rendering a template results in more output
+	//than allowed by MaxOutputBytes.
+	ErrMaxOutputBytes = errors.New("templates: output exceeds max output bytes")
+
+	//ErrMaxFiles is returned when more template files are found than allowed by
+	//MaxFiles.
+	ErrMaxFiles = errors.New("templates: number of files exceeds max files")
+
+	//ErrMaxFileSize is returned when a template file is larger than allowed by
+	//MaxFileSize.
+	ErrMaxFileSize = errors.New("templates: file exceeds max file size")
+
+	//ErrMaxTotalBytes is returned when the total size of the template files is larger
+	//than allowed by MaxTotalBytes.
+	ErrMaxTotalBytes = errors.New("templates: total size of files exceeds max total bytes")
+
+	//ErrRenderQueueTimeout is returned when a render waited longer than allowed by
+	//RenderQueueTimeout to start because MaxConcurrentRenders renders were in progress.
+	ErrRenderQueueTimeout = errors.New("templates: timeout waiting to render, too many renders in progress")
+)
+
+//limitWriter is an io.Writer that returns an error once more than max bytes have been
+//written. Bytes up to the limit are written to w.
+type limitWriter struct {
+	w       io.Writer
+	max     int64
+	written int64
+}
+
+//Write implements io.Writer.
+func (lw *limitWriter) Write(p []byte) (n int, err error) {
+	remaining := lw.max - lw.written
+	if int64(len(p)) > remaining {
+		if remaining > 0 {
+			n, err = lw.w.Write(p[:remaining])
+			lw.written += int64(n)
+			if err != nil {
+				return
+			}
+		}
+		return n, ErrMaxOutputBytes
+	}
+
+	n, err = lw.w.Write(p)
+	lw.written += int64(n)
+	return
+}
+
+//limitOutput wraps w to enforce MaxOutputBytes. w is returned as is if no limit is set.
+func (c *Config) limitOutput(w io.Writer) io.Writer {
+	if c.MaxOutputBytes <= 0 {
+		return w
+	}
+
+	return &limitWriter{w: w, max: c.MaxOutputBytes}
+}
+
+//checkIncludeDepth returns an error if any template, in the provided parse trees keyed
+//by template name, nests other templates more deeply than MaxIncludeDepth or includes
+//itself. Nothing is checked if no limit is set.
+func (c *Config) checkIncludeDepth(trees map[string]*parse.Tree) error {
+	if c.MaxIncludeDepth <= 0 {
+		return nil
+	}
+
+	//Find the templates each template includes.
+	includes := make(map[string][]string, len(trees))
+	for name, tree := range trees {
+		if tree == nil {
+			continue
+		}
+
+		walkNodes(tree.Root, func(n parse.Node) {
+			if tn, ok := n.(*parse.TemplateNode); ok {
+				includes[name] = append(includes[name], tn.Name)
+			}
+		})
+	}
+
+	//Calculate the depth of each template. A template that includes no other templates
+	//has a depth of 0. Templates currently being calculated are tracked to find
+	//recursion.
+	depths := make(map[string]int, len(trees))
+	visiting := make(map[string]bool)
+	var depth func(name string) (int, error)
+	depth = func(name string) (int, error) {
+		if d, ok := depths[name]; ok {
+			return d, nil
+		}
+		if visiting[name] {
+			return 0, errors.New("templates: template '" + name + "' includes itself, recursion is not allowed when MaxIncludeDepth is set")
+		}
+
+		visiting[name] = true
+		max := 0
+		for _, inc := range includes[name] {
+			//Missing templates are reported when executing.
+			if _, ok := trees[inc]; !ok {
+				continue
+			}
+
+			d, err := depth(inc)
+			if err != nil {
+				return 0, err
+			}
+			if d+1 > max {
+				max = d + 1
+			}
+		}
+		visiting[name] = false
+
+		if max > c.MaxIncludeDepth {
+			return 0, errors.New("templates: template '" + name + "' includes templates " + strconv.Itoa(max) + " levels deep, more than max include depth of " + strconv.Itoa(c.MaxIncludeDepth))
+		}
+
+		depths[name] = max
+		return max, nil
+	}
+
+	for name := range trees {
+		if _, err := depth(name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//checkFileLimits returns an error if the files to be parsed exceed MaxFiles,
+//MaxFileSize, or MaxTotalBytes. Each file is only counted once, even though files in
+//the base directory are listed for each subdirectory. The size of each file is
+//retrieved without reading the file so that a huge file is never read.
+func (c *Config) checkFileLimits(files map[string][]string) error {
+	if c.MaxFiles <= 0 && c.MaxFileSize <= 0 && c.MaxTotalBytes <= 0 {
+		return nil
+	}
+
+	unique := make(map[string]struct{})
+	for _, paths := range files {
+		for _, p := range paths {
+			unique[p] = struct{}{}
+		}
+	}
+
+	if c.MaxFiles > 0 && len(unique) > c.MaxFiles {
+		log.Println("templates.Build", "found "+strconv.Itoa(len(unique))+" files, max is "+strconv.Itoa(c.MaxFiles))
+		return ErrMaxFiles
+	}
+
+	if c.MaxFileSize <= 0 && c.MaxTotalBytes <= 0 {
+		return nil
+	}
+
+	var total int64
+	for p := range unique {
+		fi, err := c.stat(p)
+		if err != nil {
+			return err
+		}
+		size := fi.Size()
+
+		if c.MaxFileSize > 0 && size > c.MaxFileSize {
+			log.Println("templates.Build", "file '"+p+"' is "+strconv.FormatInt(size, 10)+" bytes, max is "+strconv.FormatInt(c.MaxFileSize, 10))
+			return ErrMaxFileSize
+		}
+
+		total += size
+		if c.MaxTotalBytes > 0 && total > c.MaxTotalBytes {
+			log.Println("templates.Build", "files are more than "+strconv.FormatInt(c.MaxTotalBytes, 10)+" bytes in total")
+			return ErrMaxTotalBytes
+		}
+	}
+
+	return nil
+}
+
+//acquireRender waits until a template can be rendered based upon MaxConcurrentRenders.
+//The returned func must be called once rendering is complete. An error is returned if
+//waiting takes longer than RenderQueueTimeout or ctx is done.
+func (c *Config) acquireRender(ctx context.Context) (release func(), err error) {
+	release = func() {}
+	if c.mu == nil {
+		return
+	}
+
+	c.mu.RLock()
+	slots := c.renderSlots
+	c.mu.RUnlock()
+	if slots == nil {
+		return
+	}
+
+	//Don't bother with a timer if a render can start now.
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	default:
+	}
+
+	if c.RenderQueueTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.RenderQueueTimeout)
+		defer cancel()
+	}
+
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	case <-ctx.Done():
+		return release, ErrRenderQueueTimeout
+	}
+}
+
+//newRenderSlots returns the channel used to limit the number of concurrent renders based
+//upon MaxConcurrentRenders. nil is returned if renders are not limited.
+func (c *Config) newRenderSlots() chan struct{} {
+	if c.MaxConcurrentRenders <= 0 {
+		return nil
+	}
+
+	return make(chan struct{}, c.MaxConcurrentRenders)
+}
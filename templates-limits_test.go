@@ -0,0 +1,269 @@
+package templates
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template/parse"
+	"time"
+)
+
+func TestLimitWriter(t *testing.T) {
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Writes up to the limit succeed, then an error is returned.
+	var b bytes.Buffer
+	lw := &limitWriter{w: &b, max: 5}
+	if _, err := lw.Write([]byte("abc")); err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	n, err := lw.Write([]byte("defg"))
+	if !errors.Is(err, ErrMaxOutputBytes) {
+		t.Fatal("ErrMaxOutputBytes should have occured but didn't", err)
+		return
+	}
+	if n != 2 || b.String() != "abcde" {
+		t.Fatal("Bytes up to limit not written", n, b.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestMaxOutputBytes(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	subdirs := []string{"limits"}
+	c := NewOnDiskConfig(base, subdirs)
+	c.MaxOutputBytes = 100
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Output under the limit is shown.
+	w := httptest.NewRecorder()
+	c.Show(w, "limits", "page", []string{"one"})
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "<p>one</p>") {
+		t.Fatal("Error showing", w.Code, w.Body)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Output over the limit returns an error without partial output.
+	w = httptest.NewRecorder()
+	c.Show(w, "limits", "page", strings.Split(strings.Repeat("x,", 50), ","))
+	if w.Code != http.StatusInternalServerError {
+		t.Fatal("Wrong status code", w.Code)
+		return
+	}
+	if strings.Contains(w.Body.String(), "<header>") {
+		t.Fatal("Partial output should not have been written", w.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Text output is limited too.
+	var b bytes.Buffer
+	err = c.RenderText(&b, "limits", "page", strings.Split(strings.Repeat("x,", 50), ","))
+	if !errors.Is(err, ErrMaxOutputBytes) {
+		t.Fatal("ErrMaxOutputBytes should have occured but didn't", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestMaxIncludeDepth(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	subdirs := []string{"limits"}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Nesting within the limit builds.
+	c := NewOnDiskConfig(base, subdirs)
+	c.MaxIncludeDepth = 2
+	err = c.Build()
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Nesting over the limit fails to build.
+	c = NewOnDiskConfig(base, subdirs)
+	c.MaxIncludeDepth = 1
+	log.SetOutput(io.Discard)
+	err = c.Build()
+	log.SetOutput(os.Stderr)
+	if err == nil || !strings.Contains(err.Error(), "max include depth") {
+		t.Fatal("Error about include depth should have occured but didn't", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Recursion is not allowed.
+	trees, err := parse.Parse("a", `{{define "a"}}{{template "b"}}{{end}}{{define "b"}}{{template "a"}}{{end}}`, "", "", nil)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	c.MaxIncludeDepth = 10
+	err = c.checkIncludeDepth(trees)
+	if err == nil || !strings.Contains(err.Error(), "recursion") {
+		t.Fatal("Error about recursion should have occured but didn't", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestFileLimits(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	subdirs := []string{"limits"}
+
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Files within the limits build.
+	c := NewOnDiskConfig(base, subdirs)
+	c.MaxFiles = 100
+	c.MaxFileSize = 10000
+	c.MaxTotalBytes = 100000
+	err = c.Build()
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Too many files.
+	c = NewOnDiskConfig(base, subdirs)
+	c.MaxFiles = 1
+	err = c.Build()
+	if !errors.Is(err, ErrMaxFiles) {
+		t.Fatal("ErrMaxFiles should have occured but didn't", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//File too large.
+	c = NewOnDiskConfig(base, subdirs)
+	c.MaxFileSize = 10
+	err = c.Build()
+	if !errors.Is(err, ErrMaxFileSize) {
+		t.Fatal("ErrMaxFileSize should have occured but didn't", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Files too large in total.
+	c = NewOnDiskConfig(base, subdirs)
+	c.MaxTotalBytes = 50
+	err = c.Build()
+	if !errors.Is(err, ErrMaxTotalBytes) {
+		t.Fatal("ErrMaxTotalBytes should have occured but didn't", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestMaxConcurrentRenders(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	subdirs := []string{"limits"}
+	c := NewOnDiskConfig(base, subdirs)
+	c.MaxConcurrentRenders = 1
+	c.RenderQueueTimeout = 10 * time.Millisecond
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Render shown when under the limit.
+	w := httptest.NewRecorder()
+	c.Show(w, "limits", "page", []string{"one"})
+	if w.Code != http.StatusOK {
+		t.Fatal("Error showing", w.Code, w.Body)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Render times out waiting when at the limit.
+	release, err := c.acquireRender(context.Background())
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+
+	w = httptest.NewRecorder()
+	c.Show(w, "limits", "page", []string{"one"})
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatal("Wrong status code", w.Code)
+		return
+	}
+
+	var b bytes.Buffer
+	err = c.RenderText(&b, "limits", "page", nil)
+	if !errors.Is(err, ErrRenderQueueTimeout) {
+		t.Fatal("ErrRenderQueueTimeout should have occured but didn't", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Render shown once a render completes.
+	release()
+	w = httptest.NewRecorder()
+	c.Show(w, "limits", "page", []string{"one"})
+	if w.Code != http.StatusOK {
+		t.Fatal("Error showing", w.Code, w.Body)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
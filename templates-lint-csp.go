@@ -0,0 +1,107 @@
+/*
+This file handles checking template files for markup that is blocked by a strict
+Content-Security-Policy. A strict policy, one without 'unsafe-inline', blocks inline
+<script> and <style> elements and inline event handlers such as onclick. Moving an
+existing app to a strict policy requires finding each of these in your templates; this
+check lists each one by file and line so that they can be migrated incrementally.
+
+This check is performed when templates are built and StrictCSP is set. Problems are
+logged, or provided to LintFunc if set, and do not cause building to fail.
+*/
+
+package templates
+
+import (
+	"sort"
+	"strings"
+)
+
+//lintCSP checks each template file for markup that is blocked by a strict
+//Content-Security-Policy and reports any problems found. Nothing is checked if
+//StrictCSP is not set.
+func (c *Config) lintCSP(files map[string][]string) error {
+	if !c.StrictCSP {
+		return nil
+	}
+
+	//The same file is parsed into many subdirectories so only check each file once.
+	unique := make(map[string]bool)
+	for _, paths := range files {
+		for _, p := range paths {
+			unique[p] = true
+		}
+	}
+	paths := make([]string, 0, len(unique))
+	for p := range unique {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	var problems []LintProblem
+	for _, p := range paths {
+		b, err := c.readFile(p)
+		if err != nil {
+			return err
+		}
+
+		for _, problem := range lintCSPSource(b) {
+			problem.File = p
+			problems = append(problems, problem)
+		}
+	}
+
+	c.reportLint("", "", "", problems)
+	return nil
+}
+
+//lintCSPSource returns the inline scripts, inline styles, and inline event handlers in
+//the source of a template file. Elements with a nonce are allowed since a strict policy
+//can allow these using the nonce.
+func lintCSPSource(b []byte) (problems []LintProblem) {
+	for _, t := range scanTags(b) {
+		if t.end {
+			continue
+		}
+
+		_, hasNonce := t.attrs["nonce"]
+		switch {
+		case t.name == "script" && !hasNonce:
+			//External scripts and data blocks, such as JSON-LD, are not blocked.
+			if _, ok := t.attrs["src"]; ok || strings.Contains(strings.ToLower(t.attrs["type"]), "json") {
+				break
+			}
+
+			problems = append(problems, LintProblem{
+				Rule:    "csp-inline-script",
+				Message: "inline <script> is blocked, move it to a file or add a nonce",
+				Line:    t.line,
+			})
+
+		case t.name == "style" && !hasNonce:
+			problems = append(problems, LintProblem{
+				Rule:    "csp-inline-style",
+				Message: "inline <style> is blocked, move it to a file or add a nonce",
+				Line:    t.line,
+			})
+		}
+
+		//Sort the attribute names so that problems are listed in a consistent order.
+		names := make([]string, 0, len(t.attrs))
+		for name := range t.attrs {
+			if strings.HasPrefix(name, "on") {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			problems = append(problems, LintProblem{
+				Rule:    "csp-inline-handler",
+				Message: name + " on <" + t.name + "> is blocked, use addEventListener in a script file",
+				Line:    t.line,
+			})
+		}
+	}
+
+	return
+}
@@ -0,0 +1,161 @@
+/*
+This file handles checking that the output of templates is well-formed HTML when in
+development. html/template escapes data based on context but does not check that tags
+are closed or nested properly. Browsers silently repair unclosed or mis-nested tags,
+often differently than intended, which results in subtle layout bugs.
+
+Output is tokenized, rather than parsed, since parsing HTML repairs the same problems
+this check is meant to find.
+*/
+
+package templates
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+
+	"golang.org/x/net/html"
+)
+
+//voidElements are elements that cannot have content and do not have an end tag.
+var voidElements = map[string]bool{
+	"area":   true,
+	"base":   true,
+	"br":     true,
+	"col":    true,
+	"embed":  true,
+	"hr":     true,
+	"img":    true,
+	"input":  true,
+	"link":   true,
+	"meta":   true,
+	"param":  true,
+	"source": true,
+	"track":  true,
+	"wbr":    true,
+}
+
+//optionalEndTagElements are elements whose end tag can be omitted. These elements are
+//closed implicitly and are not reported when left unclosed.
+var optionalEndTagElements = map[string]bool{
+	"html":     true,
+	"head":     true,
+	"body":     true,
+	"p":        true,
+	"li":       true,
+	"dt":       true,
+	"dd":       true,
+	"option":   true,
+	"optgroup": true,
+	"colgroup": true,
+	"thead":    true,
+	"tbody":    true,
+	"tfoot":    true,
+	"tr":       true,
+	"td":       true,
+	"th":       true,
+	"rt":       true,
+	"rp":       true,
+}
+
+//openElement is an element whose end tag has not been found yet.
+type openElement struct {
+	name string
+	line int
+}
+
+//lintHTML checks the output of a template for unclosed, mis-nested, and stray tags.
+func lintHTML(b []byte) (problems []LintProblem) {
+	z := html.NewTokenizer(bytes.NewReader(b))
+
+	var open []openElement
+	line := 1
+	for {
+		tt := z.Next()
+		tokenLine := line
+		line += bytes.Count(z.Raw(), []byte("\n"))
+
+		switch tt {
+		case html.ErrorToken:
+			if z.Err() != io.EOF {
+				problems = append(problems, LintProblem{
+					Rule:    "html-syntax",
+					Message: z.Err().Error(),
+					Line:    tokenLine,
+				})
+			}
+
+			for _, e := range open {
+				if optionalEndTagElements[e.name] {
+					continue
+				}
+
+				problems = append(problems, LintProblem{
+					Rule:    "unclosed-tag",
+					Message: "<" + e.name + "> is never closed",
+					Line:    e.line,
+				})
+			}
+			return
+
+		case html.SelfClosingTagToken:
+			name, _ := z.TagName()
+			if !voidElements[string(name)] {
+				problems = append(problems, LintProblem{
+					Rule:    "self-closing-tag",
+					Message: "<" + string(name) + "/> is not a void element, the / is ignored and the element is left open",
+					Line:    tokenLine,
+				})
+				open = append(open, openElement{name: string(name), line: tokenLine})
+			}
+
+		case html.StartTagToken:
+			name, _ := z.TagName()
+			if !voidElements[string(name)] {
+				open = append(open, openElement{name: string(name), line: tokenLine})
+			}
+
+		case html.EndTagToken:
+			nameBytes, _ := z.TagName()
+			name := string(nameBytes)
+			if voidElements[name] {
+				problems = append(problems, LintProblem{
+					Rule:    "stray-end-tag",
+					Message: "</" + name + "> is a void element and cannot have an end tag",
+					Line:    tokenLine,
+				})
+				continue
+			}
+
+			//Find the element being closed.
+			i := len(open) - 1
+			for i >= 0 && open[i].name != name {
+				i--
+			}
+			if i < 0 {
+				problems = append(problems, LintProblem{
+					Rule:    "stray-end-tag",
+					Message: "</" + name + "> has no matching start tag",
+					Line:    tokenLine,
+				})
+				continue
+			}
+
+			//Elements opened after the element being closed, other than elements that
+			//are closed implicitly, are mis-nested.
+			for _, e := range open[i+1:] {
+				if optionalEndTagElements[e.name] {
+					continue
+				}
+
+				problems = append(problems, LintProblem{
+					Rule:    "misnested-tag",
+					Message: "</" + name + "> closes <" + name + "> before <" + e.name + "> opened on line " + strconv.Itoa(e.line) + " is closed",
+					Line:    tokenLine,
+				})
+			}
+			open = open[:i]
+		}
+	}
+}
@@ -0,0 +1,340 @@
+/*
+This file handles checking the output of templates for common problems when in
+development. Since problems in the output of templates, such as an image without alt
+text, are not errors and do not stop the page from being shown, they are easy to miss
+until an audit or a user reports them. Checking the output each time a template is shown
+during development catches these problems as they are introduced.
+
+Checks are only performed when Development is true and the check is enabled in your
+config. Since the output must be checked before it is written, output is rendered fully
+before anything is written and is not cached, the same as when MaxOutputBytes is set.
+Problems are logged, or provided to LintFunc if set, and the page is still shown.
+*/
+
+package templates
+
+import (
+	"bytes"
+	"html"
+	"log"
+	"strconv"
+	"strings"
+)
+
+//LintProblem is a problem found in the output of a template.
+type LintProblem struct {
+	//Rule is the name of the check that found the problem, for example "img-alt".
+	Rule string
+
+	//Message describes the problem.
+	Message string
+
+	//Line is the line in the output of the template, or in File, the problem was found
+	//at.
+	Line int
+
+	//File is the complete path to the template file the problem was found in. This is
+	//blank for problems found in the output of a template.
+	File string
+}
+
+//String returns the problem formatted for logging.
+func (p LintProblem) String() string {
+	s := "line " + strconv.Itoa(p.Line) + ": " + p.Rule + ": " + p.Message
+	if p.File != "" {
+		s = p.File + ": " + s
+	}
+
+	return s
+}
+
+//linting returns if the output of templates should be checked for problems.
+func (c *Config) linting() bool {
+	return c.Development && (c.LintAccessibility || c.LintHTML)
+}
+
+//lint checks the output of a template for problems and reports any problems found
+//using LintFunc or by logging them.
+func (c *Config) lint(subdir, templateName, requestID string, b []byte) {
+	var problems []LintProblem
+	if c.LintAccessibility {
+		problems = append(problems, lintAccessibility(b)...)
+	}
+	if c.LintHTML {
+		problems = append(problems, lintHTML(b)...)
+	}
+
+	c.reportLint(subdir, templateName, requestID, problems)
+}
+
+//reportLint reports problems using LintFunc or by logging them. subdir, templateName,
+//and requestID are blank for problems found in template files when building.
+func (c *Config) reportLint(subdir, templateName, requestID string, problems []LintProblem) {
+	if len(problems) == 0 {
+		return
+	}
+
+	if c.LintFunc != nil {
+		c.LintFunc(subdir, templateName, problems)
+		return
+	}
+
+	for _, p := range problems {
+		if p.File != "" {
+			log.Println("templates.Build", "lint", p.String())
+			continue
+		}
+
+		log.Println("templates.Show", "lint", subdir, templateName, p.String(), "request_id="+requestID)
+	}
+}
+
+//lintAccessibility checks the output of a template for common accessibility problems:
+//images without alt text, form fields without labels, and duplicate IDs.
+func lintAccessibility(b []byte) (problems []LintProblem) {
+	tags := scanTags(b)
+
+	//Find the IDs of elements that have a label.
+	labeled := make(map[string]bool)
+	for _, t := range tags {
+		if t.name == "label" && !t.end && t.attrs["for"] != "" {
+			labeled[t.attrs["for"]] = true
+		}
+	}
+
+	ids := make(map[string]int)
+	labelDepth := 0
+	for _, t := range tags {
+		if t.name == "label" {
+			if t.end && labelDepth > 0 {
+				labelDepth--
+			} else if !t.end {
+				labelDepth++
+			}
+			continue
+		}
+		if t.end {
+			continue
+		}
+
+		if id, ok := t.attrs["id"]; ok && id != "" {
+			if line, ok := ids[id]; ok {
+				problems = append(problems, LintProblem{
+					Rule:    "duplicate-id",
+					Message: "id \"" + id + "\" is also used on line " + strconv.Itoa(line),
+					Line:    t.line,
+				})
+			} else {
+				ids[id] = t.line
+			}
+		}
+
+		switch t.name {
+		case "img":
+			//An empty alt is allowed for decorative images.
+			if _, ok := t.attrs["alt"]; !ok {
+				problems = append(problems, LintProblem{
+					Rule:    "img-alt",
+					Message: "<img> has no alt attribute",
+					Line:    t.line,
+				})
+			}
+
+		case "input", "select", "textarea":
+			if t.name == "input" {
+				switch strings.ToLower(t.attrs["type"]) {
+				case "hidden", "submit", "button", "reset", "image":
+					continue
+				}
+			}
+
+			if labelDepth > 0 || labeled[t.attrs["id"]] || t.attrs["aria-label"] != "" || t.attrs["aria-labelledby"] != "" || t.attrs["title"] != "" {
+				continue
+			}
+
+			problems = append(problems, LintProblem{
+				Rule:    "form-label",
+				Message: "<" + t.name + "> has no label",
+				Line:    t.line,
+			})
+		}
+	}
+
+	return
+}
+
+//htmlTag is a start or end tag found in HTML.
+type htmlTag struct {
+	//name is the lowercased name of the tag.
+	name string
+
+	//end is true for end tags.
+	end bool
+
+	//attrs are the tag's attributes keyed by lowercased name, with values unescaped.
+	attrs map[string]string
+
+	//line is the line the tag starts on.
+	line int
+}
+
+//rawTextTags are tags whose contents are not parsed as HTML.
+var rawTextTags = map[string]bool{
+	"script":   true,
+	"style":    true,
+	"textarea": true,
+	"title":    true,
+}
+
+//scanTags returns the start and end tags in HTML. Comments, doctypes, and the contents
+//of raw text elements, such as <script>, are skipped. This is a lenient scanner meant
+//for finding problems in output, not a complete HTML parser.
+func scanTags(b []byte) (tags []htmlTag) {
+	line := 1
+	lineAt := 0
+	lineOf := func(i int) int {
+		line += bytes.Count(b[lineAt:i], []byte("\n"))
+		lineAt = i
+		return line
+	}
+
+	for i := 0; i < len(b); {
+		start := bytes.IndexByte(b[i:], '<')
+		if start == -1 {
+			break
+		}
+		i += start
+
+		rest := b[i:]
+		switch {
+		case bytes.HasPrefix(rest, []byte("<!--")):
+			end := bytes.Index(rest[4:], []byte("-->"))
+			if end == -1 {
+				return
+			}
+			i += 4 + end + 3
+			continue
+
+		case bytes.HasPrefix(rest, []byte("<!")), bytes.HasPrefix(rest, []byte("<?")):
+			end := bytes.IndexByte(rest, '>')
+			if end == -1 {
+				return
+			}
+			i += end + 1
+			continue
+		}
+
+		t := htmlTag{line: lineOf(i)}
+		j := 1
+		if j < len(rest) && rest[j] == '/' {
+			t.end = true
+			j++
+		}
+		if j >= len(rest) || !isASCIILetter(rest[j]) {
+			i++
+			continue
+		}
+
+		nameStart := j
+		for j < len(rest) && !isHTMLSpace(rest[j]) && rest[j] != '>' && rest[j] != '/' {
+			j++
+		}
+		t.name = strings.ToLower(string(rest[nameStart:j]))
+
+		var n int
+		t.attrs, n = scanAttrs(rest[j:])
+		i += j + n
+		tags = append(tags, t)
+
+		//Skip the contents of raw text elements to their end tag.
+		if !t.end && rawTextTags[t.name] {
+			end := indexFold(b[i:], "</"+t.name)
+			if end == -1 {
+				return
+			}
+			i += end
+		}
+	}
+
+	return
+}
+
+//scanAttrs parses the attributes of a tag up to and including the closing >. The
+//number of bytes read is returned.
+func scanAttrs(b []byte) (attrs map[string]string, n int) {
+	attrs = make(map[string]string)
+	i := 0
+	for i < len(b) {
+		for i < len(b) && (isHTMLSpace(b[i]) || b[i] == '/') {
+			i++
+		}
+		if i >= len(b) {
+			break
+		}
+		if b[i] == '>' {
+			return attrs, i + 1
+		}
+
+		nameStart := i
+		for i < len(b) && !isHTMLSpace(b[i]) && b[i] != '=' && b[i] != '>' && b[i] != '/' {
+			i++
+		}
+		name := strings.ToLower(string(b[nameStart:i]))
+
+		for i < len(b) && isHTMLSpace(b[i]) {
+			i++
+		}
+		if i >= len(b) || b[i] != '=' {
+			attrs[name] = ""
+			continue
+		}
+		i++
+		for i < len(b) && isHTMLSpace(b[i]) {
+			i++
+		}
+
+		var value []byte
+		if i < len(b) && (b[i] == '"' || b[i] == '\'') {
+			quote := b[i]
+			end := bytes.IndexByte(b[i+1:], quote)
+			if end == -1 {
+				return attrs, len(b)
+			}
+			value = b[i+1 : i+1+end]
+			i += end + 2
+		} else {
+			valueStart := i
+			for i < len(b) && !isHTMLSpace(b[i]) && b[i] != '>' {
+				i++
+			}
+			value = b[valueStart:i]
+		}
+
+		attrs[name] = html.UnescapeString(string(value))
+	}
+
+	return attrs, len(b)
+}
+
+//isHTMLSpace returns if c is whitespace per the HTML spec.
+func isHTMLSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+//isASCIILetter returns if c is an ASCII letter.
+func isASCIILetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+//indexFold returns the index of the first case-insensitive match of substr in b, or
+//-1 if substr is not found. substr must be lowercase ASCII.
+func indexFold(b []byte, substr string) int {
+	sub := []byte(substr)
+	for i := 0; i+len(sub) <= len(b); i++ {
+		if bytes.EqualFold(b[i:i+len(sub)], sub) {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,209 @@
+package templates
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLintAccessibility(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	b, err := os.ReadFile(filepath.Join(dir, "_testdata", "templates", "lint", "a11y.html"))
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Problems found, commented out and script contents ignored.
+	problems := lintAccessibility(b)
+	expected := []LintProblem{
+		{Rule: "img-alt", Line: 5},
+		{Rule: "form-label", Line: 10},
+		{Rule: "duplicate-id", Line: 12},
+	}
+	if len(problems) != len(expected) {
+		t.Fatal("Problems not found as expected", problems)
+		return
+	}
+	for i, p := range problems {
+		if p.Rule != expected[i].Rule || p.Line != expected[i].Line {
+			t.Fatal("Problem not found as expected", p, expected[i])
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestLintFunc(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	subdirs := []string{"lint"}
+	c := NewOnDiskConfig(base, subdirs)
+	c.LintAccessibility = true
+	var found []LintProblem
+	c.LintFunc = func(subdir, templateName string, problems []LintProblem) {
+		found = append(found, problems...)
+	}
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Not checked when not in development.
+	w := httptest.NewRecorder()
+	c.Show(w, "lint", "a11y", nil)
+	if len(found) != 0 {
+		t.Fatal("Output should not be checked when not in development", found)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	c.Development = true
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Problems reported and page still shown, each time page is shown.
+	for i := 1; i <= 2; i++ {
+		w = httptest.NewRecorder()
+		c.Show(w, "lint", "a11y", nil)
+		if w.Code != http.StatusOK || w.Body.Len() == 0 {
+			t.Fatal("Page should still be shown", w.Code)
+			return
+		}
+		if len(found) != 3*i {
+			t.Fatal("Problems not reported as expected", found)
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//No problems.
+	found = nil
+	w = httptest.NewRecorder()
+	c.Show(w, "lint", "clean", nil)
+	if len(found) != 0 {
+		t.Fatal("No problems should have been found", found)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestLintHTML(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Problems found, implicitly closed elements and raw text ignored.
+	b, err := os.ReadFile(filepath.Join(dir, "_testdata", "templates", "lint", "malformed.html"))
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	problems := lintHTML(b)
+	expected := []LintProblem{
+		{Rule: "misnested-tag", Line: 6},
+		{Rule: "stray-end-tag", Line: 6},
+		{Rule: "self-closing-tag", Line: 7},
+		{Rule: "stray-end-tag", Line: 8},
+		{Rule: "misnested-tag", Line: 10},
+		{Rule: "stray-end-tag", Line: 11},
+		{Rule: "unclosed-tag", Line: 12},
+	}
+	if len(problems) != len(expected) {
+		t.Fatal("Problems not found as expected", problems)
+		return
+	}
+	for i, p := range problems {
+		if p.Rule != expected[i].Rule || p.Line != expected[i].Line {
+			t.Fatal("Problem not found as expected", p, expected[i])
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Well-formed.
+	b, err = os.ReadFile(filepath.Join(dir, "_testdata", "templates", "lint", "a11y.html"))
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if problems := lintHTML(b); len(problems) != 0 {
+		t.Fatal("No problems should have been found", problems)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestStrictCSP(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	subdirs := []string{"csp"}
+	c := NewOnDiskConfig(base, subdirs)
+	var found []LintProblem
+	c.LintFunc = func(subdir, templateName string, problems []LintProblem) {
+		found = append(found, problems...)
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Not checked unless enabled.
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+	if len(found) != 0 {
+		t.Fatal("Files should not be checked unless enabled", found)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Problems found by file and line, building does not fail.
+	c.StrictCSP = true
+	err = c.Build()
+	if err != nil {
+		t.Fatal("Building should not fail", err)
+		return
+	}
+	expected := []LintProblem{
+		{Rule: "csp-inline-script", Line: 4},
+		{Rule: "csp-inline-style", Line: 7},
+		{Rule: "csp-inline-handler", Line: 8},
+		{Rule: "csp-inline-handler", Line: 8},
+	}
+	if len(found) != len(expected) {
+		t.Fatal("Problems not found as expected", found)
+		return
+	}
+	for i, p := range found {
+		if p.Rule != expected[i].Rule || p.Line != expected[i].Line || filepath.Base(p.File) != "page.html" {
+			t.Fatal("Problem not found as expected", p, expected[i])
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,97 @@
+/*
+This file handles maintenance mode, where every request is responded to with a
+maintenance page rather than being handled by your app, for example while migrating a
+database. Wrap your app's handler with MaintenanceHandler() and turn maintenance mode on
+and off at runtime with SetMaintenance():
+	http.ListenAndServe(":8080", c.MaintenanceHandler("Back soon!", 10*time.Minute)(mux))
+	...
+	c.SetMaintenance(true)
+
+While maintenance mode is on, requests are responded to with 503 Service Unavailable
+and a Retry-After header. The page shown is the template set in StatusTemplates for 503
+Service Unavailable, provided a MaintenanceData, so that it uses the same layout as the
+rest of your pages. If no template is set, the message is written as plain text.
+*/
+
+package templates
+
+import (
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+//MaintenanceData is the data provided to the maintenance page template.
+type MaintenanceData struct {
+	//Message is the message shown to users.
+	Message string
+
+	//RetryAfter is how long until users should try again. This is 0 if unknown.
+	RetryAfter time.Duration
+}
+
+//SetMaintenance turns maintenance mode on or off. This is safe to call while requests
+//are being handled.
+func (c *Config) SetMaintenance(on bool) {
+	var v int32
+	if on {
+		v = 1
+	}
+
+	atomic.StoreInt32(&c.maintenance, v)
+}
+
+//SetMaintenance turns maintenance mode on or off using the default package level
+//config.
+func SetMaintenance(on bool) {
+	Default().SetMaintenance(on)
+}
+
+//InMaintenance returns if maintenance mode is on.
+func (c *Config) InMaintenance() bool {
+	return atomic.LoadInt32(&c.maintenance) == 1
+}
+
+//InMaintenance returns if maintenance mode is on using the default package level
+//config.
+func InMaintenance() bool {
+	return Default().InMaintenance()
+}
+
+//MaintenanceHandler returns middleware that, while maintenance mode is on, responds to
+//every request with the maintenance page instead of calling the next handler. The
+//message is provided to the maintenance page template and retryAfter is used for the
+//Retry-After header, rounded up to whole seconds. The header is not set if retryAfter
+//is 0.
+func (c *Config) MaintenanceHandler(message string, retryAfter time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !c.InMaintenance() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if retryAfter > 0 {
+				seconds := (retryAfter + time.Second - 1) / time.Second
+				w.Header().Set("Retry-After", strconv.FormatInt(int64(seconds), 10))
+			}
+
+			if _, ok := c.StatusTemplates[http.StatusServiceUnavailable]; !ok {
+				http.Error(w, message, http.StatusServiceUnavailable)
+				return
+			}
+
+			c.ShowStatusPage(w, r, http.StatusServiceUnavailable, MaintenanceData{
+				Message:    message,
+				RetryAfter: retryAfter,
+			})
+		})
+	}
+}
+
+//MaintenanceHandler returns maintenance mode middleware using the default package level
+//config.
+func MaintenanceHandler(message string, retryAfter time.Duration) func(http.Handler) http.Handler {
+	return Default().MaintenanceHandler(message, retryAfter)
+}
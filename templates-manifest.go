@@ -0,0 +1,248 @@
+/*
+This file handles writing a manifest of the templates found when Build() was called
+and verifying the built templates against a manifest. This is useful for guaranteeing
+that the templates embedded in your app's executable are the templates that were
+reviewed. For example, CI can write a manifest after templates are reviewed and your app
+can verify its embedded templates against the manifest at startup, refusing to start
+if the templates differ.
+
+The manifest lists the path, relative to BasePath, hash, and size of each source file,
+and the templates each template includes via {{template}} in each subdirectory. The
+manifest is written as JSON so that it can be reviewed and stored alongside your
+templates.
+
+Templates added with ParseUntrusted() are not included, and files added with
+ParseExtra() are not listed as source files.
+*/
+
+package templates
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	"text/template/parse"
+)
+
+//ErrManifestMismatch is returned when the built templates do not match a manifest. The
+//returned error wraps this error and lists the differences.
+var ErrManifestMismatch = errors.New("templates: templates do not match manifest")
+
+//TemplateManifest lists the source files and templates found when Build() was called.
+type TemplateManifest struct {
+	//Files is the list of source files, sorted by path.
+	Files []ManifestFile `json:"files"`
+
+	//Templates is the list of templates in each subdirectory, sorted by subdirectory
+	//and name.
+	Templates []ManifestTemplate `json:"templates"`
+}
+
+//ManifestFile is a source file in a manifest.
+type ManifestFile struct {
+	//Path is the path to the file relative to BasePath, using forward slash separators.
+	Path string `json:"path"`
+
+	//Hash is the hex encoded SHA-256 hash of the file's contents.
+	Hash string `json:"hash"`
+
+	//Size is the size of the file in bytes.
+	Size int64 `json:"size"`
+}
+
+//ManifestTemplate is a template in a manifest. Templates defined with {{define}} are
+//listed alongside templates named after their file.
+type ManifestTemplate struct {
+	//SubDir is the subdirectory the template was built for. This is blank for the
+	//base directory.
+	SubDir string `json:"subdir"`
+
+	//Name is the name of the template.
+	Name string `json:"name"`
+
+	//Includes is the sorted list of templates this template includes via {{template}}.
+	Includes []string `json:"includes,omitempty"`
+}
+
+//Manifest returns the manifest of the templates found when Build() was called.
+//ErrNotBuilt is returned if templates have not been built.
+func (c *Config) Manifest() (m TemplateManifest, err error) {
+	if c.mu == nil {
+		return m, ErrNotBuilt
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.hash == "" {
+		return m, ErrNotBuilt
+	}
+
+	m.Files = make([]ManifestFile, 0, len(c.fileHashes))
+	for _, f := range c.fileHashes {
+		m.Files = append(m.Files, f)
+	}
+	sort.Slice(m.Files, func(i, j int) bool {
+		return m.Files[i].Path < m.Files[j].Path
+	})
+
+	for subdir, includes := range c.includes {
+		for name, names := range includes {
+			m.Templates = append(m.Templates, ManifestTemplate{
+				SubDir:   subdir,
+				Name:     name,
+				Includes: names,
+			})
+		}
+	}
+	sort.Slice(m.Templates, func(i, j int) bool {
+		if m.Templates[i].SubDir != m.Templates[j].SubDir {
+			return m.Templates[i].SubDir < m.Templates[j].SubDir
+		}
+		return m.Templates[i].Name < m.Templates[j].Name
+	})
+
+	return
+}
+
+//Manifest returns the manifest of the templates using the default package level config.
+func Manifest() (m TemplateManifest, err error) {
+	m, err = Default().Manifest()
+	return
+}
+
+//WriteManifest writes the manifest of the templates found when Build() was called to
+//w as indented JSON.
+func (c *Config) WriteManifest(w io.Writer) (err error) {
+	m, err := c.Manifest()
+	if err != nil {
+		return
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	err = enc.Encode(m)
+	return
+}
+
+//WriteManifest writes the manifest of the templates using the default package level
+//config.
+func WriteManifest(w io.Writer) (err error) {
+	err = Default().WriteManifest(w)
+	return
+}
+
+//VerifyManifest reads a manifest, as written by WriteManifest(), from r and checks that
+//the templates found when Build() was called match it. An error wrapping
+//ErrManifestMismatch, and listing each difference, is returned if the templates do not
+//match.
+func (c *Config) VerifyManifest(r io.Reader) (err error) {
+	var expected TemplateManifest
+	err = json.NewDecoder(r).Decode(&expected)
+	if err != nil {
+		return
+	}
+
+	actual, err := c.Manifest()
+	if err != nil {
+		return
+	}
+
+	diffs := compareManifests(expected, actual)
+	if len(diffs) > 0 {
+		return fmt.Errorf("%w: %s", ErrManifestMismatch, strings.Join(diffs, ", "))
+	}
+
+	return
+}
+
+//VerifyManifest checks the templates against a manifest using the default package level
+//config.
+func VerifyManifest(r io.Reader) (err error) {
+	err = Default().VerifyManifest(r)
+	return
+}
+
+//compareManifests returns a description of each difference from expected to actual.
+func compareManifests(expected, actual TemplateManifest) (diffs []string) {
+	expectedFiles := make(map[string]ManifestFile, len(expected.Files))
+	for _, f := range expected.Files {
+		expectedFiles[f.Path] = f
+	}
+	actualFiles := make(map[string]ManifestFile, len(actual.Files))
+	for _, f := range actual.Files {
+		actualFiles[f.Path] = f
+	}
+
+	d := diffFiles(expectedFiles, actualFiles)
+	for _, p := range d.Added {
+		diffs = append(diffs, "file '"+p+"' added")
+	}
+	for _, p := range d.Removed {
+		diffs = append(diffs, "file '"+p+"' removed")
+	}
+	for _, p := range d.Changed {
+		diffs = append(diffs, "file '"+p+"' changed")
+	}
+
+	//Templates are compared by the templates they include.
+	expectedIncludes := make(map[string]string, len(expected.Templates))
+	for _, t := range expected.Templates {
+		expectedIncludes[t.SubDir+"/"+t.Name] = strings.Join(t.Includes, ",")
+	}
+	for _, t := range actual.Templates {
+		key := t.SubDir + "/" + t.Name
+		includes, ok := expectedIncludes[key]
+		if !ok {
+			diffs = append(diffs, "template '"+key+"' added")
+		} else if includes != strings.Join(t.Includes, ",") {
+			diffs = append(diffs, "template '"+key+"' includes changed")
+		}
+		delete(expectedIncludes, key)
+	}
+
+	removed := make([]string, 0, len(expectedIncludes))
+	for key := range expectedIncludes {
+		removed = append(removed, key)
+	}
+	sort.Strings(removed)
+	for _, key := range removed {
+		diffs = append(diffs, "template '"+key+"' removed")
+	}
+
+	return
+}
+
+//templateIncludes returns the sorted list of templates each template in t includes via
+//{{template}}, keyed by template name.
+func templateIncludes(t *template.Template) map[string][]string {
+	includes := make(map[string][]string)
+	for _, tmpl := range t.Templates() {
+		if tmpl.Name() == "" || tmpl.Tree == nil {
+			continue
+		}
+
+		unique := make(map[string]bool)
+		walkNodes(tmpl.Tree.Root, func(n parse.Node) {
+			if tn, ok := n.(*parse.TemplateNode); ok {
+				unique[tn.Name] = true
+			}
+		})
+
+		names := make([]string, 0, len(unique))
+		for name := range unique {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		if len(names) == 0 {
+			names = nil
+		}
+		includes[tmpl.Name()] = names
+	}
+
+	return includes
+}
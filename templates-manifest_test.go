@@ -0,0 +1,92 @@
+package templates
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestManifest(t *testing.T) {
+	fsys := fstest.MapFS{
+		"tpl/header.html":   {Data: []byte(`{{define "header"}}<h1>Header</h1>{{end}}`)},
+		"tpl/app/page.html": {Data: []byte(`{{template "header"}}<p>page</p>`)},
+	}
+
+	c := NewFSConfig(fsys, "tpl", []string{"app"})
+	err := c.Build()
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Files and includes are listed.
+	m, err := c.Manifest()
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	if len(m.Files) != 2 || m.Files[0].Path != "app/page.html" || m.Files[1].Path != "header.html" {
+		t.Fatal("Unexpected files", m.Files)
+		return
+	}
+	if m.Files[0].Size != int64(len(fsys["tpl/app/page.html"].Data)) || m.Files[0].Hash == "" {
+		t.Fatal("Unexpected file", m.Files[0])
+		return
+	}
+
+	found := false
+	for _, tmpl := range m.Templates {
+		if tmpl.SubDir == "app" && tmpl.Name == "page.html" {
+			found = true
+			if len(tmpl.Includes) != 1 || tmpl.Includes[0] != "header" {
+				t.Fatal("Unexpected includes", tmpl.Includes)
+				return
+			}
+		}
+	}
+	if !found {
+		t.Fatal("Template missing from manifest", m.Templates)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Verify against the written manifest.
+	var b bytes.Buffer
+	err = c.WriteManifest(&b)
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	manifest := b.String()
+
+	err = c.VerifyManifest(strings.NewReader(manifest))
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Verify after a file changed.
+	fsys["tpl/app/page.html"] = &fstest.MapFile{Data: []byte(`<p>page</p>`)}
+	err = c.Build()
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+
+	err = c.VerifyManifest(strings.NewReader(manifest))
+	if !errors.Is(err, ErrManifestMismatch) {
+		t.Fatal("ErrManifestMismatch should have occured but did not", err)
+		return
+	}
+	if !strings.Contains(err.Error(), "file 'app/page.html' changed") || !strings.Contains(err.Error(), "template 'app/page.html' includes changed") {
+		t.Fatal("Unexpected error", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
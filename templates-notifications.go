@@ -0,0 +1,53 @@
+/*
+This file handles rendering short, plain text notifications, such as SMS messages, from
+templates. Notification templates are stored and parsed the same as any other template,
+typically in a "notifications" subdirectory, and are rendered as text so that no HTML
+escaping is performed.
+
+Since notifications are usually limited in length, the length of each rendered
+notification is checked and a warning is logged when the length is over the maximum.
+The notification is still returned so that you can decide how to handle long messages
+(i.e.: send as multiple messages or truncate).
+*/
+
+package templates
+
+import (
+	"bytes"
+	"log"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+//defaultNotificationMaxLength is the maximum length of a notification, the length of a
+//single SMS message.
+const defaultNotificationMaxLength = 160
+
+//RenderNotification renders a template as plain text and returns it for sending as a
+//notification, such as an SMS message. Leading and trailing whitespace is removed. A
+//warning is logged if the notification is longer than NotificationMaxLength characters.
+func (c *Config) RenderNotification(subdir, templateName string, injectedData interface{}) (msg string, err error) {
+	var b bytes.Buffer
+	err = c.RenderText(&b, subdir, templateName, injectedData)
+	if err != nil {
+		return
+	}
+
+	msg = strings.TrimSpace(b.String())
+
+	max := c.NotificationMaxLength
+	if max == 0 {
+		max = defaultNotificationMaxLength
+	}
+	if length := utf8.RuneCountInString(msg); max > 0 && length > max {
+		log.Println("templates.RenderNotification", "notification '"+subdir+"/"+templateName+"' is longer than max length", strconv.Itoa(length)+" > "+strconv.Itoa(max))
+	}
+
+	return
+}
+
+//RenderNotification renders a notification using the default package level config.
+func RenderNotification(subdir, templateName string, injectedData interface{}) (msg string, err error) {
+	return Default().RenderNotification(subdir, templateName, injectedData)
+}
@@ -0,0 +1,63 @@
+package templates
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenderNotification(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	subdirs := []string{"notifications"}
+	c := NewOnDiskConfig(base, subdirs)
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	//Capture logging to check for warnings.
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Notification rendered without escaping.
+	msg, err := c.RenderNotification("notifications", "code", "<123>")
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	if msg != "Your code is <123> & expires in 10 minutes." {
+		t.Fatal("Notification not rendered as expected", msg)
+		return
+	}
+	if logged.Len() != 0 {
+		t.Fatal("Warning should not have been logged", logged.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Warning logged for long notification.
+	c.NotificationMaxLength = 20
+	_, err = c.RenderNotification("notifications", "code", "123")
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	if !strings.Contains(logged.String(), "longer than max length") {
+		t.Fatal("Warning not logged", logged.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
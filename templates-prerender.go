@@ -0,0 +1,155 @@
+/*
+This file handles rendering pages on a schedule, rather than when a page is requested.
+This is useful for pages, such as landing pages, that are backed by data that is slow
+to retrieve; the data is retrieved and the page rendered in the background so that
+showing the page has no cost.
+
+Once a page is prerendered, calling Show() for the page's subdirectory and template
+with nil injected data returns the prerendered output. The output is refreshed each
+interval. If retrieving data or rendering fails, the error is logged and the previously
+rendered output continues to be shown. Note that prerendered output is shown for all
+requests, regardless of the device variant that would otherwise be used.
+*/
+
+package templates
+
+import (
+	"bytes"
+	"log"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+//PrerenderPage is a page to render on a schedule.
+type PrerenderPage struct {
+	//Subdir is the subdirectory of the template.
+	Subdir string
+
+	//Name is the name of the template, with or without the extension.
+	Name string
+}
+
+//key returns the key for storing the page's prerendered output. The extension is added
+//to the name, if needed, so that the page is found the same as Show() finds templates.
+func (p PrerenderPage) key(extension string) PrerenderPage {
+	if filepath.Ext(p.Name) == "" {
+		p.Name += "." + extension
+	}
+
+	return p
+}
+
+//SchedulePrerender renders each page now and then again each interval. The data for
+//each page is retrieved with dataProvider; dataProvider may be nil if the pages do not
+//need any data. Call the returned func to stop rendering the pages. Prerendered output
+//is kept, and shown, after stopping.
+func (c *Config) SchedulePrerender(interval time.Duration, pages []PrerenderPage, dataProvider func(p PrerenderPage) (interface{}, error)) (stop func()) {
+	done := make(chan struct{})
+	var once sync.Once
+	stop = func() {
+		once.Do(func() {
+			close(done)
+		})
+	}
+
+	c.prerender(pages, dataProvider)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				c.prerender(pages, dataProvider)
+			}
+		}
+	}()
+
+	return
+}
+
+//SchedulePrerender renders pages on a schedule using the default package level config.
+func SchedulePrerender(interval time.Duration, pages []PrerenderPage, dataProvider func(p PrerenderPage) (interface{}, error)) (stop func()) {
+	return Default().SchedulePrerender(interval, pages, dataProvider)
+}
+
+//prerender renders each page and saves the output. Errors are logged and the previous
+//output for the page is kept.
+func (c *Config) prerender(pages []PrerenderPage, dataProvider func(p PrerenderPage) (interface{}, error)) {
+	for _, p := range pages {
+		var data interface{}
+		if dataProvider != nil {
+			d, err := dataProvider(p)
+			if err != nil {
+				log.Println("templates.SchedulePrerender", "error getting data for '"+p.Subdir+"/"+p.Name+"'", err)
+				continue
+			}
+			data = d
+		}
+
+		//Note the templates being rendered so that output is not stored if the templates
+		//are rebuilt while rendering.
+		var generation uint64
+		if c.mu != nil {
+			c.mu.RLock()
+			generation = c.generation
+			c.mu.RUnlock()
+		}
+
+		var b bytes.Buffer
+		err := c.render(&b, p.Subdir, p.Name, data)
+		if err != nil {
+			log.Println("templates.SchedulePrerender", "error rendering '"+p.Subdir+"/"+p.Name+"'", err)
+			continue
+		}
+
+		if c.mu == nil {
+			continue
+		}
+
+		c.mu.Lock()
+		if c.generation != generation {
+			c.mu.Unlock()
+			continue
+		}
+		if c.prerendered == nil {
+			c.prerendered = make(map[PrerenderPage][]byte)
+			c.prerenderedAt = make(map[PrerenderPage]time.Time)
+		}
+		key := p.key(c.Extension)
+		if existing, ok := c.prerendered[key]; !ok || !bytes.Equal(existing, b.Bytes()) {
+			c.prerenderedAt[key] = time.Now()
+		}
+		c.prerendered[key] = b.Bytes()
+		c.mu.Unlock()
+	}
+}
+
+//prerenderedPage returns the prerendered output of a template, if it exists.
+func (c *Config) prerenderedPage(subdir, templateName string) (b []byte, ok bool) {
+	if c.mu == nil {
+		return
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	b, ok = c.prerendered[PrerenderPage{Subdir: subdir, Name: templateName}]
+	return
+}
+
+//clearPrerendered discards the prerendered output of the pages in a subdirectory since
+//the subdirectory's templates changed. Output is prerendered again at the next
+//interval. This must be called while holding the write lock.
+func (c *Config) clearPrerendered(subdir string) {
+	for p := range c.prerendered {
+		if p.Subdir == subdir {
+			delete(c.prerendered, p)
+			delete(c.prerenderedAt, p)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package templates
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSchedulePrerender(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	subdirs := []string{"help"}
+	c := NewOnDiskConfig(base, subdirs)
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Page rendered immediately and shown with provided data.
+	var calls int32
+	pages := []PrerenderPage{{Subdir: "help", Name: "version"}}
+	stop := c.SchedulePrerender(10*time.Millisecond, pages, func(p PrerenderPage) (interface{}, error) {
+		if atomic.AddInt32(&calls, 1) > 1 {
+			return nil, errors.New("upstream unavailable")
+		}
+		return "data", nil
+	})
+	defer stop()
+
+	b, ok := c.prerenderedPage("help", "version.html")
+	if !ok || !strings.Contains(string(b), c.Hash()) {
+		t.Fatal("Page not prerendered as expected", string(b))
+		return
+	}
+
+	w := httptest.NewRecorder()
+	c.Show(w, "help", "version", nil)
+	if w.Body.String() != string(b) {
+		t.Fatal("Prerendered page not shown", w.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Page refreshed each interval, keeping the previous output on error.
+	time.Sleep(50 * time.Millisecond)
+	if atomic.LoadInt32(&calls) < 2 {
+		t.Fatal("Page not refreshed", calls)
+		return
+	}
+	if _, ok := c.prerenderedPage("help", "version.html"); !ok {
+		t.Fatal("Previous output should have been kept")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Refreshing stops.
+	stop()
+	time.Sleep(20 * time.Millisecond)
+	n := atomic.LoadInt32(&calls)
+	time.Sleep(50 * time.Millisecond)
+	if atomic.LoadInt32(&calls) != n {
+		t.Fatal("Refreshing should have stopped")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Prerendered output discarded when templates are rebuilt.
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+	if _, ok := c.prerenderedPage("help", "version.html"); ok {
+		t.Fatal("Prerendered output should have been discarded")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,242 @@
+/*
+This file handles previewing a change to a template before it is activated. This is
+useful for apps that allow editing templates stored in a database or other remote
+source, such as an in-app template editor, so that an author can see how their change
+will look, and what changed, before saving.
+
+PreviewChange() parses the changed source along with the subdirectory's existing files
+and sources, in isolation from the templates being shown, and renders it with sample
+data. The templates being shown are not modified. The output is compared, line by line,
+to the output of the current version of the template rendered with the same data.
+
+Since the changed source has not been checked yet, the preview is rendered with the
+UntrustedLimits of the template's current source, or of the policy when previewing with
+PreviewUntrusted(). Outputs too long to compare efficiently are compared by removing
+the lines common to the start and end of both outputs and showing the remaining lines
+as removed and added.
+*/
+
+package templates
+
+import (
+	"bytes"
+	"strings"
+)
+
+//Preview is the result of previewing a change to a template.
+type Preview struct {
+	//Output is the output of the changed template rendered with the sample data.
+	Output string
+
+	//Current is the output of the current version of the template rendered with the
+	//sample data. This is blank if the template does not exist yet or could not be
+	//rendered with the sample data.
+	Current string
+
+	//Diff is the line by line difference from Current to Output. Each line is prefixed
+	//with "- " if it was removed, "+ " if it was added, or "  " if it is unchanged.
+	Diff string
+
+	//Changed is true if Output is different from Current.
+	Changed bool
+}
+
+//PreviewChange parses newSource as the template name in an already built subdirectory
+//and renders it with sampleData, returning the output and a diff against the output of
+//the current version of the template. The changed template is parsed the same as with
+//ParseUntrusted() but is not activated; use ParseUntrusted() to activate the change.
+//An error is returned if the changed template cannot be parsed or rendered.
+//
+//The source is not checked against an UntrustedPolicy. Use PreviewUntrusted() to
+//preview sources written by untrusted authors.
+func (c *Config) PreviewChange(subdir, name, newSource string, sampleData interface{}) (p Preview, err error) {
+	return c.previewChange(subdir, name, newSource, sampleData, nil)
+}
+
+//PreviewChange previews a change to a template using the default package level config.
+func PreviewChange(subdir, name, newSource string, sampleData interface{}) (p Preview, err error) {
+	p, err = Default().PreviewChange(subdir, name, newSource, sampleData)
+	return
+}
+
+//PreviewUntrusted checks newSource against the policy, the same as CheckUntrusted(),
+//and previews it the same as PreviewChange(). The preview is rendered with the policy's
+//Limits.
+func (c *Config) PreviewUntrusted(subdir, name, newSource string, sampleData interface{}, policy UntrustedPolicy) (p Preview, err error) {
+	err = CheckUntrusted(name, newSource, policy)
+	if err != nil {
+		return
+	}
+
+	return c.previewChange(subdir, name, newSource, sampleData, &policy.Limits)
+}
+
+//PreviewUntrusted previews a change to an untrusted template using the default package
+//level config.
+func PreviewUntrusted(subdir, name, newSource string, sampleData interface{}, policy UntrustedPolicy) (p Preview, err error) {
+	p, err = Default().PreviewUntrusted(subdir, name, newSource, sampleData, policy)
+	return
+}
+
+//previewChange handles PreviewChange() and PreviewUntrusted(). The changed template is
+//rendered with limits, or the limits of the template's current source if limits is nil.
+func (c *Config) previewChange(subdir, name, newSource string, sampleData interface{}, limits *UntrustedLimits) (p Preview, err error) {
+	if c.mu == nil {
+		return p, ErrUnknownSubDir
+	}
+
+	c.mu.RLock()
+	paths, ok := c.files[subdir]
+	existing := c.sources[subdir]
+	current := c.templates[subdir]
+	currentUntrusted := c.untrustedTemplates[subdir]
+	currentLimits, isUntrusted := c.untrustedLimits[subdir][name]
+	c.mu.RUnlock()
+	if !ok {
+		return p, ErrUnknownSubDir
+	}
+	if limits == nil {
+		l := existing[name].limits
+		limits = &l
+	}
+
+	//Parse the changed template in isolation from the templates being shown.
+	sources := make(map[string]untrustedSource, len(existing)+1)
+	for n, s := range existing {
+		sources[n] = s
+	}
+	sources[name] = untrustedSource{src: newSource, limits: *limits}
+
+	t, err := c.parseFiles(paths, nil)
+	if err != nil {
+		return
+	}
+	_, err = parseSources(t, sources)
+	if err != nil {
+		return
+	}
+
+	v, _, releaseView := c.acquireView(subdir)
+	defer releaseView()
+
+	data := c.renderData(v, subdir, "", sampleData)
+
+	var b bytes.Buffer
+	err = c.executeLimited(&b, t, name, c.templateData(&data), *limits)
+	if err != nil {
+		return
+	}
+	p.Output = b.String()
+
+	//Render the current version, if it exists. Errors are ignored since the sample
+	//data may only suit the changed template. Untrusted templates are rendered from a
+	//copy, with their limits, the same as when shown.
+	if isUntrusted && currentUntrusted != nil {
+		var cb bytes.Buffer
+		if clone, err := currentUntrusted.Clone(); err == nil && c.executeLimited(&cb, clone, name, c.templateData(&data), currentLimits) == nil {
+			p.Current = cb.String()
+		}
+	} else if tmpl := current.Lookup(name); tmpl != nil {
+		var cb bytes.Buffer
+		if tmpl.Execute(c.limitOutput(&cb), c.templateData(&data)) == nil {
+			p.Current = cb.String()
+		}
+	}
+
+	p.Diff = lineDiff(p.Current, p.Output)
+	p.Changed = p.Current != p.Output
+	return
+}
+
+//maxDiffCells is the largest number of pairs of lines, the number of lines that differ
+//in one output times the number that differ in the other, compared when finding the
+//longest common subsequence of lines. This limits the memory used for large outputs.
+const maxDiffCells = 1 << 20
+
+//lineDiff returns the line by line difference from a to b, based upon the longest
+//common subsequence of lines. See Preview.Diff for the format. Lines common to the
+//start and end of a and b are found first; if the remaining lines are too many to
+//compare, see maxDiffCells, they are shown as removed and added instead.
+func lineDiff(a, b string) string {
+	if a == "" && b == "" {
+		return ""
+	}
+
+	al := splitLines(a)
+	bl := splitLines(b)
+
+	var sb strings.Builder
+	prefix := 0
+	for prefix < len(al) && prefix < len(bl) && al[prefix] == bl[prefix] {
+		sb.WriteString("  " + al[prefix] + "\n")
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(al)-prefix && suffix < len(bl)-prefix && al[len(al)-1-suffix] == bl[len(bl)-1-suffix] {
+		suffix++
+	}
+
+	lcsDiff(&sb, al[prefix:len(al)-suffix], bl[prefix:len(bl)-suffix])
+
+	for _, l := range al[len(al)-suffix:] {
+		sb.WriteString("  " + l + "\n")
+	}
+
+	return sb.String()
+}
+
+//lcsDiff writes the line by line difference from al to bl to sb, based upon the longest
+//common subsequence of lines.
+func lcsDiff(sb *strings.Builder, al, bl []string) {
+	if len(al)*len(bl) > maxDiffCells {
+		for _, l := range al {
+			sb.WriteString("- " + l + "\n")
+		}
+		for _, l := range bl {
+			sb.WriteString("+ " + l + "\n")
+		}
+		return
+	}
+
+	//lcs[i][j] is the length of the longest common subsequence of al[i:] and bl[j:].
+	lcs := make([][]int, len(al)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(bl)+1)
+	}
+	for i := len(al) - 1; i >= 0; i-- {
+		for j := len(bl) - 1; j >= 0; j-- {
+			if al[i] == bl[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(al) || j < len(bl) {
+		switch {
+		case i < len(al) && j < len(bl) && al[i] == bl[j]:
+			sb.WriteString("  " + al[i] + "\n")
+			i++
+			j++
+		case i < len(al) && (j == len(bl) || lcs[i+1][j] >= lcs[i][j+1]):
+			sb.WriteString("- " + al[i] + "\n")
+			i++
+		default:
+			sb.WriteString("+ " + bl[j] + "\n")
+			j++
+		}
+	}
+}
+
+//splitLines splits s into lines, ignoring a trailing newline.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
@@ -0,0 +1,127 @@
+package templates
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"path/filepath"
+	"testing"
+)
+
+func TestPreviewChange(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	c := NewOnDiskConfig(base, []string{"app"})
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	p := UntrustedPolicy{Namespace: "tenant-1/"}
+	err = c.ParseUntrusted("app", "tenant-1/home.html", "Hello\n{{.InjectedData}}\n", p)
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	prev, err := c.PreviewChange("app", "tenant-1/home.html", "Hi\n{{.InjectedData}}\n", "Bobby")
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	if prev.Output != "Hi\nBobby\n" || prev.Current != "Hello\nBobby\n" || !prev.Changed {
+		t.Fatal("Preview not as expected", prev)
+		return
+	}
+	if prev.Diff != "- Hello\n+ Hi\n  Bobby\n" {
+		t.Fatal("Diff not as expected", prev.Diff)
+		return
+	}
+
+	//The change is not activated.
+	c.mu.RLock()
+	src := c.sources["app"]["tenant-1/home.html"].src
+	c.mu.RUnlock()
+	if src != "Hello\n{{.InjectedData}}\n" {
+		t.Fatal("Change should not have been activated", src)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//New template.
+	prev, err = c.PreviewChange("app", "tenant-1/new.html", "New", nil)
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	if prev.Current != "" || prev.Diff != "+ New\n" || !prev.Changed {
+		t.Fatal("Preview not as expected", prev)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	_, err = c.PreviewChange("app", "tenant-1/bad.html", "{{if}}", nil)
+	if err == nil {
+		t.Fatal("Error should have occured for invalid source but didn't")
+		return
+	}
+
+	//Preview rendered with the policy's limits.
+	p.Limits = UntrustedLimits{MaxRangeIterations: 2}
+	_, err = c.PreviewUntrusted("app", "tenant-1/range.html", "{{range .InjectedData}}{{.}}{{end}}", []int{1, 2, 3}, p)
+	if !errors.Is(err, ErrMaxRangeIterations) {
+		t.Fatal("ErrMaxRangeIterations should have occured but didn't", err)
+		return
+	}
+
+	_, err = c.PreviewUntrusted("app", "tenant-2/home.html", "Hi", nil, p)
+	if !errors.Is(err, ErrUntrustedTemplate) {
+		t.Fatal("ErrUntrustedTemplate should have occured but didn't", err)
+		return
+	}
+
+	_, err = c.PreviewChange("not-built", "tenant-1/home.html", "Hi", nil)
+	if err != ErrUnknownSubDir {
+		t.Fatal("ErrUnknownSubDir should have occured but didn't", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestLineDiff(t *testing.T) {
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	tests := map[[2]string]string{
+		{"", ""}:            "",
+		{"a\nb\nc", "a\nc"}: "  a\n- b\n  c\n",
+		{"a\nc", "a\nb\nc"}: "  a\n+ b\n  c\n",
+		{"a", ""}:           "- a\n",
+		{"a\nb", "a\nb\n"}:  "  a\n  b\n",
+		{"x\ny", "y\nz"}:    "- x\n  y\n+ z\n",
+	}
+	for in, expected := range tests {
+		if got := lineDiff(in[0], in[1]); got != expected {
+			t.Fatalf("lineDiff(%q, %q) = %q, expected %q", in[0], in[1], got, expected)
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Outputs too long to compare are shown as removed and added.
+	long := strings.Repeat("a\n", maxDiffCells/1000) + strings.Repeat("b\n", 1001)
+	diff := lineDiff("x\n"+long+"y", "x\n"+strings.Repeat("c\n", 1001)+"y")
+	if !strings.HasPrefix(diff, "  x\n- a\n") || !strings.HasSuffix(diff, "+ c\n  y\n") {
+		t.Fatal("Diff not as expected", diff[:20], diff[len(diff)-20:])
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,386 @@
+/*
+This file handles rebuilding templates after Build() was called. Rebuilding is useful
+for picking up changes to on-disk template files without restarting your app, for
+example on a schedule or upon receiving a SIGHUP.
+
+Rebuilding is skipped when the contents of the source files have not changed since
+the last build. This is determined by comparing a hash of the contents of every source
+file, calculated each time templates are built, to avoid reparsing identical templates.
+Rebuilding is not skipped if config fields that affect how templates are parsed, such
+as BuildTags or FuncMap, changed since the last build.
+*/
+
+package templates
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+//hashFiles calculates a hash of the contents of the provided files. Each file is only
+//used once, even though files in the base directory are listed for each subdirectory,
+//and files are used in a sorted order so that the same files always result in the
+//same hash. The path to each file, relative to BasePath, is included in the hash so
+//that renaming or moving a file results in a different hash.
+//
+//The hash and size of each file is also returned, keyed by the file's path relative to
+//BasePath, for comparing builds with Diff() and Manifest(). The hash of all files is
+//calculated from these, see combinedHash().
+func (c *Config) hashFiles(files map[string][]string) (hash string, fileHashes map[string]ManifestFile, err error) {
+	//Get the unique list of files.
+	unique := make(map[string]struct{})
+	for _, paths := range files {
+		for _, p := range paths {
+			unique[p] = struct{}{}
+		}
+	}
+
+	sorted := make([]string, 0, len(unique))
+	for p := range unique {
+		sorted = append(sorted, p)
+	}
+	sort.Strings(sorted)
+
+	//Calculate the hash of each file.
+	fileHashes = make(map[string]ManifestFile, len(sorted))
+	for _, p := range sorted {
+		b, innerErr := c.readFile(p)
+		if innerErr != nil {
+			return "", nil, innerErr
+		}
+
+		rel := c.relPath(p)
+		fh := sha256.Sum256(b)
+		fileHashes[rel] = ManifestFile{
+			Path: rel,
+			Hash: hex.EncodeToString(fh[:]),
+			Size: int64(len(b)),
+		}
+	}
+
+	hash = combinedHash(fileHashes)
+	return
+}
+
+//combinedHash calculates a single hash from the hash of each file. This allows the
+//hash of all files to be recalculated when only some files are reread, see
+//RebuildSubDir().
+func combinedHash(fileHashes map[string]ManifestFile) string {
+	sorted := make([]string, 0, len(fileHashes))
+	for p := range fileHashes {
+		sorted = append(sorted, p)
+	}
+	sort.Strings(sorted)
+
+	h := sha256.New()
+	for _, p := range sorted {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+		h.Write([]byte(fileHashes[p].Hash))
+		h.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+//parseFingerprint returns a fingerprint of the config fields that affect how templates
+//are parsed. Funcs are identified by their code, so replacing a func with a closure
+//created by the same code, such as a closure capturing a different value, is not
+//noticed; replace the FuncMap itself in this case.
+func (c *Config) parseFingerprint() string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%q|%s|%t|%t|%t|%p\n", c.BuildTags, c.Environment, c.Development, c.TrimBlocks, c.ContribPartials, c.FuncMap)
+
+	//Maps are printed sorted by key so the same groups always result in the same
+	//fingerprint.
+	fmt.Fprintf(h, "%q|%d\n", c.Groups, c.MaxIncludeDepth)
+
+	names := make([]string, 0, len(c.FuncMap))
+	for name := range c.FuncMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(h, "%s=%x\n", name, funcPointer(c.FuncMap[name]))
+	}
+
+	for _, t := range c.SourceTransforms {
+		fmt.Fprintf(h, "transform=%x\n", funcPointer(t))
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+//funcPointer returns the pointer to the code of a func, or 0 if f is not a func.
+func funcPointer(f interface{}) uintptr {
+	v := reflect.ValueOf(f)
+	if v.Kind() != reflect.Func {
+		return 0
+	}
+
+	return v.Pointer()
+}
+
+//Hash returns the hash of the contents of the source files found when templates were
+//last built. This will be blank if templates have not been built.
+func (c *Config) Hash() string {
+	if c.mu == nil {
+		return ""
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.hash
+}
+
+//Hash returns the hash of the source files using the default package level config.
+func Hash() string {
+	return Default().Hash()
+}
+
+//BuildTime returns the time when templates were last built. This will be the zero
+//time if templates have not been built.
+func (c *Config) BuildTime() time.Time {
+	if c.mu == nil {
+		return time.Time{}
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.buildTime
+}
+
+//BuildTime returns the time when templates were last built using the default package
+//level config.
+func BuildTime() time.Time {
+	return Default().BuildTime()
+}
+
+//Rebuild rebuilds the templates if the contents of the source files have changed since
+//templates were last built. If no files have changed, rebuilding is skipped. This is
+//useful for rebuilding often, such as on a schedule, without reparsing identical
+//templates each time. Note that files added with ParseExtra() are kept if rebuilding
+//is skipped.
+func (c *Config) Rebuild() (err error) {
+	//Templates have never been built so just build them.
+	if c.Hash() == "" {
+		return c.Build()
+	}
+
+	err = c.validate()
+	if err != nil {
+		return
+	}
+
+	files, err := c.gatherFiles()
+	if err != nil {
+		return
+	}
+
+	err = c.checkFileLimits(files)
+	if err != nil {
+		return
+	}
+
+	hash, _, err := c.hashFiles(files)
+	if err != nil {
+		return
+	}
+
+	//Nothing changed, skip rebuilding.
+	c.mu.RLock()
+	unchanged := hash == c.hash && c.parseFingerprint() == c.builtParseFingerprint
+	c.mu.RUnlock()
+	if unchanged {
+		return
+	}
+
+	return c.Build()
+}
+
+//Rebuild rebuilds the templates, if needed, using the default package level config.
+func Rebuild() (err error) {
+	err = Default().Rebuild()
+	return
+}
+
+//RebuildSubDir reparses the templates for a single subdirectory, along with the files
+//in the base directory, rather than rebuilding every subdirectory. This is useful for
+//rebuilding quickly when a change is detected in one subdirectory, no matter how many
+//subdirectories exist. Any group, see Groups, that includes the subdirectory is
+//reparsed as well. A group's name can also be provided to reparse just the group.
+//
+//Files added with ParseExtra() and templates added with ParseUntrusted() to the
+//reparsed subdirectories are removed, the same as with Build(). Changes to files in
+//the base directory are only used by the reparsed subdirectories; use Build() or
+//Rebuild() when files in the base directory change. Providing a blank subdirectory
+//rebuilds all templates with Build().
+func (c *Config) RebuildSubDir(subdir string) (err error) {
+	if subdir == "" || c.Hash() == "" {
+		return c.Build()
+	}
+
+	//Find the subdirectories and groups to reparse.
+	subDirs, err := c.subDirs()
+	if err != nil {
+		return
+	}
+	isSubDir := false
+	for _, s := range subDirs {
+		if s == subdir {
+			isSubDir = true
+			break
+		}
+	}
+
+	var targets []string
+	if _, isGroup := c.Groups[subdir]; isGroup || isSubDir {
+		targets = append(targets, subdir)
+	}
+	if isSubDir {
+		for name, groupSubDirs := range c.Groups {
+			for _, s := range groupSubDirs {
+				if strings.TrimSpace(s) == subdir {
+					targets = append(targets, name)
+					break
+				}
+			}
+		}
+	}
+	if len(targets) == 0 {
+		return ErrUnknownSubDir
+	}
+
+	//Find the files for the subdirectory and each group.
+	ignore, err := c.readIgnoreFile()
+	if err != nil {
+		return
+	}
+	baseFilePaths, err := c.buildPathsToFiles(c.BasePath)
+	if err != nil {
+		return
+	}
+	baseFilePaths = c.filterIgnored(ignore, baseFilePaths)
+
+	files, err := c.gatherGroupFiles(ignore, baseFilePaths)
+	if err != nil {
+		return
+	}
+	var subdirFilepaths []string
+	if isSubDir {
+		completePathToSubDir := filepath.Join(c.BasePath, subdir)
+		if c.fsys() != nil {
+			completePathToSubDir = filepath.ToSlash(completePathToSubDir)
+		}
+
+		subdirFilepaths, err = c.buildPathsToFiles(completePathToSubDir)
+		if err != nil {
+			return
+		}
+		subdirFilepaths = c.filterIgnored(ignore, subdirFilepaths)
+		if len(subdirFilepaths) > 0 {
+			files[subdir] = append(subdirFilepaths, baseFilePaths...)
+		}
+	}
+
+	err = c.checkFileLimits(files)
+	if err != nil {
+		return
+	}
+
+	//Hash the files in the subdirectory only, files in the base directory are not
+	//reread.
+	_, subdirHashes, err := c.hashFiles(map[string][]string{subdir: subdirFilepaths})
+	if err != nil {
+		return
+	}
+
+	var hash string
+	defer func() {
+		if err == nil {
+			c.audit(AuditEvent{Action: AuditRebuild, SubDir: subdir, Hash: hash})
+		}
+	}()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, name := range targets {
+		paths, ok := files[name]
+		if !ok {
+			//No template files exist anymore, remove the templates the same as if
+			//Build() was called.
+			c.removeSubDir(name)
+			continue
+		}
+
+		err = c.reparse(name, paths, nil)
+		if err != nil {
+			log.Println("templates.RebuildSubDir", "error parsing files at subdir '"+name+"'", err)
+			return
+		}
+		delete(c.sources, name)
+		delete(c.previousSources, name)
+	}
+
+	//Replace the hashes of the subdirectory's files.
+	if isSubDir {
+		fileHashes := make(map[string]ManifestFile, len(c.fileHashes))
+		for p, f := range c.fileHashes {
+			if path.Dir(p) != filepath.ToSlash(subdir) {
+				fileHashes[p] = f
+			}
+		}
+		for p, f := range subdirHashes {
+			fileHashes[p] = f
+		}
+		c.fileHashes = fileHashes
+
+		//Cached output of every subdirectory includes the hash, as TemplatesVersion, so
+		//it must be rendered again if the hash changed.
+		if newHash := combinedHash(fileHashes); newHash != c.hash {
+			c.hash = newHash
+			c.rendered = nil
+		}
+	}
+	hash = c.hash
+
+	return
+}
+
+//RebuildSubDir reparses the templates for a single subdirectory using the default
+//package level config.
+func RebuildSubDir(subdir string) (err error) {
+	err = Default().RebuildSubDir(subdir)
+	return
+}
+
+//removeSubDir removes the templates for a subdirectory. This must be called while
+//holding the write lock.
+func (c *Config) removeSubDir(subdir string) {
+	delete(c.templates, subdir)
+	delete(c.requestTemplates, subdir)
+	delete(c.named, subdir)
+	delete(c.files, subdir)
+	delete(c.modTimes, subdir)
+	delete(c.nodeCounts, subdir)
+	delete(c.memoizableSubDirs, subdir)
+	delete(c.includes, subdir)
+	delete(c.textTemplates, subdir)
+	delete(c.sources, subdir)
+	delete(c.previousSources, subdir)
+	delete(c.untrustedTemplates, subdir)
+	delete(c.untrustedLimits, subdir)
+	c.clearPrerendered(subdir)
+	c.generation++
+}
@@ -0,0 +1,203 @@
+package templates
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestRebuild(t *testing.T) {
+	//Copy template files to a temporary directory so that they can be modified.
+	base := t.TempDir()
+	err := os.Mkdir(filepath.Join(base, "app"), 0755)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	err = os.WriteFile(filepath.Join(base, "header.html"), []byte("header"), 0644)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	err = os.WriteFile(filepath.Join(base, "app", "app.html"), []byte("app"), 0644)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	c := NewOnDiskConfig(base, []string{"app"})
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Hash should be blank prior to building.
+	if c.Hash() != "" {
+		t.Fatal("Hash should be blank prior to building")
+		return
+	}
+
+	err = c.Rebuild()
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	hash := c.Hash()
+	if hash == "" {
+		t.Fatal("Hash not set after building")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Rebuild without changes should be skipped.
+	before := c.templates["app"]
+	err = c.Rebuild()
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	if c.templates["app"] != before {
+		t.Fatal("Templates were rebuilt but should not have been")
+		return
+	}
+	if c.Hash() != hash {
+		t.Fatal("Hash changed but should not have")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Rebuild after changing a file.
+	err = os.WriteFile(filepath.Join(base, "app", "app.html"), []byte("app changed"), 0644)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	err = c.Rebuild()
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	if c.templates["app"] == before {
+		t.Fatal("Templates were not rebuilt but should have been")
+		return
+	}
+	if c.Hash() == hash {
+		t.Fatal("Hash did not change but should have")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Rebuild after changing fields that affect parsing, even though no files changed.
+	for _, change := range []func(){
+		func() { c.BuildTags = []string{"beta"} },
+		func() { c.Environment = "staging" },
+		func() { c.TrimBlocks = true },
+		func() { c.FuncMap = map[string]interface{}{"upper": strings.ToUpper} },
+		func() { c.Groups = map[string][]string{"all": {"app"}} },
+		func() { c.Groups["other"] = []string{"app"} },
+		func() { c.MaxIncludeDepth = 5 },
+	} {
+		change()
+		before = c.templates["app"]
+		err = c.Rebuild()
+		if err != nil {
+			t.Fatal("Error should not have occured but did", err)
+			return
+		}
+		if c.templates["app"] == before {
+			t.Fatal("Templates were not rebuilt but should have been")
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestRebuildSubDir(t *testing.T) {
+	fsys := fstest.MapFS{
+		"tpl/header.html":    {Data: []byte(`header`)},
+		"tpl/app/app.html":   {Data: []byte(`app`)},
+		"tpl/help/help.html": {Data: []byte(`help`)},
+	}
+
+	c := NewFSConfig(fsys, "tpl", []string{"app", "help"})
+	c.Groups = map[string][]string{"all": {"app", "help"}}
+	err := c.Build()
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	hash := c.Hash()
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Only the rebuilt subdirectory, and groups including it, are reparsed. Cached
+	//output is discarded since the hash changed.
+	c.Show(httptest.NewRecorder(), "help", "help", nil)
+	if len(c.rendered) != 1 {
+		t.Fatal("Output should have been cached", len(c.rendered))
+		return
+	}
+
+	fsys["tpl/app/app.html"] = &fstest.MapFile{Data: []byte(`app changed`)}
+	fsys["tpl/help/help.html"] = &fstest.MapFile{Data: []byte(`help changed`)}
+
+	err = c.RebuildSubDir("app")
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	if len(c.rendered) != 0 {
+		t.Fatal("Cached output should have been discarded", len(c.rendered))
+		return
+	}
+
+	for _, tc := range []struct{ subdir, name, expected string }{
+		{"app", "app", "app changed"},
+		{"all", "app", "app changed"},
+		{"help", "help", "help"},
+	} {
+		var b bytes.Buffer
+		err = c.render(&b, tc.subdir, tc.name, nil)
+		if err != nil {
+			t.Fatal("Error should not have occured but did", err)
+			return
+		}
+		if b.String() != tc.expected {
+			t.Fatal("Unexpected output", tc.subdir, b.String())
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//The hash is updated the same as if all templates were built.
+	if c.Hash() == hash {
+		t.Fatal("Hash should have changed")
+		return
+	}
+
+	fsys["tpl/help/help.html"] = &fstest.MapFile{Data: []byte(`help`)}
+	expected := NewFSConfig(fsys, "tpl", []string{"app", "help"})
+	err = expected.Build()
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	if c.Hash() != expected.Hash() {
+		t.Fatal("Hash should match a full build", c.Hash(), expected.Hash())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Unknown subdirectory.
+	err = c.RebuildSubDir("missing")
+	if err != ErrUnknownSubDir {
+		t.Fatal("ErrUnknownSubDir should have occured but did not", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,227 @@
+/*
+This file handles caching the rendered output of templates shown without any injected
+data. Since such templates only use data from your config, the output is the same each
+time the template is shown so the template does not need to be executed again. This
+effectively makes static pages, such as an about or help page, free to show after the
+first time they are shown.
+
+Output is only cached when it is known to be the same each time a template is shown:
+  - No injected data was provided.
+  - The request's ID is not known, since the ID is provided to templates.
+  - No request-scoped funcs were bound to a request.
+  - No data was retrieved from the request using a provider, such as SessionProvider.
+  - The subdirectory's templates do not use any funcs from the config's FuncMap, since
+    these funcs may return a different value each time they are called, or the signURL
+    func, since signed URLs expire.
+
+Cached output is keyed by the template and the config fields provided to templates,
+including the contents of the cache busting file pairs, so that changing a config
+field, such as Development, results in the template being rendered again. Cached output is discarded when templates are rebuilt. Set
+DisableRenderCache to never cache output. Output is also never cached when output is
+checked for problems, i.e. LintAccessibility or LintHTML, so that each render is
+checked.
+
+When many requests show the same template before its output is cached, for example
+right after templates are rebuilt, only one request renders the template and the other
+requests wait for and share its output. This prevents a popular page from being
+rendered many times at once.
+*/
+
+package templates
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"html/template"
+	"sync"
+)
+
+//renderCacheKey is the key for the cached output of a template. The config fields
+//provided to templates are included so that changes to these fields are reflected.
+type renderCacheKey struct {
+	tmpl          *template.Template
+	development   bool
+	environment   string
+	useLocalFiles bool
+	appVersion    string
+	dataKey       string
+	flatten       bool
+	merge         bool
+
+	//cacheBustFiles and subDirCacheBustFiles are fingerprints of the contents of the
+	//cache busting file pairs so that replacing, or modifying, either map results in the
+	//template being rendered again.
+	cacheBustFiles       uint64
+	subDirCacheBustFiles uint64
+}
+
+//renderCacheKey returns the key for the cached output of a template.
+func (c *Config) renderCacheKey(subdir string, tmpl *template.Template) renderCacheKey {
+	return renderCacheKey{
+		tmpl:                 tmpl,
+		development:          c.Development,
+		environment:          c.Environment,
+		useLocalFiles:        c.UseLocalFiles,
+		appVersion:           c.AppVersion,
+		dataKey:              c.DataKey,
+		flatten:              c.FlattenInjectedData,
+		merge:                c.MergeInjectedMap,
+		cacheBustFiles:       pairsFingerprint(c.CacheBustingFilePairs),
+		subDirCacheBustFiles: pairsFingerprint(c.SubDirCacheBustingFilePairs[subdir]),
+	}
+}
+
+//pairsFingerprint returns a fingerprint of the contents of a map of strings. The
+//fingerprint does not depend on the order the map is iterated in, and nothing is
+//allocated, since this is calculated each time a cached template is shown.
+func pairsFingerprint(m map[string]string) (sum uint64) {
+	const (
+		offset = 14695981039346656037
+		prime  = 1099511628211
+	)
+
+	for k, v := range m {
+		//FNV-1a of the key and value, separated so that "ab"+"c" and "a"+"bc" differ.
+		h := uint64(offset)
+		for i := 0; i < len(k); i++ {
+			h = (h ^ uint64(k[i])) * prime
+		}
+		h = (h ^ 0xff) * prime
+		for i := 0; i < len(v); i++ {
+			h = (h ^ uint64(v[i])) * prime
+		}
+
+		//Mix the bits so that summing the pairs does not cancel out similar pairs.
+		h ^= h >> 33
+		h *= 0xff51afd7ed558ccd
+		h ^= h >> 33
+		sum += h
+	}
+
+	return sum + uint64(len(m))
+}
+
+//memoizable returns if the output of rendering a template can be cached, based upon the
+//conditions described at the top of this file.
+func (c *Config) memoizable(v renderView, requestID string, injectedData interface{}, perRequest bool) bool {
+	if c.DisableRenderCache || c.linting() || injectedData != nil || requestID != "" || perRequest || c.mu == nil {
+		return false
+	}
+
+	return v.memoizable
+}
+
+//cachedRender returns the cached output of a template, if it exists.
+func (c *Config) cachedRender(key renderCacheKey) (b []byte, ok bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	b, ok = c.rendered[key]
+	return
+}
+
+//storeRender caches the output of a template. The output is only cached if the
+//template still belongs to the built templates; the templates may have been rebuilt
+//while rendering.
+func (c *Config) storeRender(subdir string, key renderCacheKey, b []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.named[subdir][key.tmpl.Name()] != key.tmpl {
+		return
+	}
+
+	if c.rendered == nil {
+		c.rendered = make(map[renderCacheKey][]byte)
+	}
+	c.rendered[key] = b
+}
+
+//renderCall is a render of a template that is in progress. Requests for the same
+//template wait for the render to complete and use its output.
+type renderCall struct {
+	wg  sync.WaitGroup
+	b   []byte
+	err error
+}
+
+//renderOnce returns the cached output of a template or, if the output is not cached,
+//renders the template and caches the output. If the template is already being
+//rendered, the output of that render is waited for and returned instead of rendering
+//the template again.
+//
+//The shared render is not tied to the context of the request that started it, so one
+//client disconnecting does not fail the render for every request waiting on it. If the
+//shared render could not start in time, each waiting request renders the template
+//itself using its own context.
+func (c *Config) renderOnce(ctx context.Context, subdir string, key renderCacheKey, data interface{}) ([]byte, error) {
+	c.mu.Lock()
+	if b, ok := c.rendered[key]; ok {
+		c.mu.Unlock()
+		return b, nil
+	}
+	if call, ok := c.inflight[key]; ok {
+		c.mu.Unlock()
+		call.wg.Wait()
+		if errors.Is(call.err, ErrRenderQueueTimeout) {
+			return c.renderUncached(ctx, key, data)
+		}
+		return call.b, call.err
+	}
+
+	call := &renderCall{}
+	call.wg.Add(1)
+	if c.inflight == nil {
+		c.inflight = make(map[renderCacheKey]*renderCall)
+	}
+	c.inflight[key] = call
+	c.mu.Unlock()
+
+	//Always release waiting requests, even if rendering panics.
+	call.err = errRenderPanicked
+	defer func() {
+		c.mu.Lock()
+		delete(c.inflight, key)
+		c.mu.Unlock()
+		call.wg.Done()
+	}()
+
+	call.b, call.err = c.renderUncached(context.Background(), key, data)
+	if call.err == nil {
+		c.storeRender(subdir, key, call.b)
+	}
+
+	return call.b, call.err
+}
+
+//errRenderPanicked is provided to requests waiting on a shared render that panicked.
+var errRenderPanicked = errors.New("templates: shared render panicked")
+
+//renderUncached renders a template without using or storing cached output.
+func (c *Config) renderUncached(ctx context.Context, key renderCacheKey, data interface{}) ([]byte, error) {
+	release, err := c.acquireRender(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	var b bytes.Buffer
+	err = key.tmpl.Execute(c.limitOutput(&b), data)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+//configFuncNames returns the names of the funcs in the config's FuncMap plus the funcs
+//this package provides that return a different value each time they are called.
+func (c *Config) configFuncNames() (names []string) {
+	for name := range c.FuncMap {
+		names = append(names, name)
+	}
+	names = append(names, "signURL")
+
+	return
+}
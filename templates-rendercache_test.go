@@ -0,0 +1,239 @@
+package templates
+
+import (
+	"context"
+	"html/template"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestRenderCache(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	subdirs := []string{"help"}
+	c := NewOnDiskConfig(base, subdirs)
+	c.Environment = "staging"
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Output cached when shown without injected data.
+	w := httptest.NewRecorder()
+	c.Show(w, "help", "env", nil)
+	if strings.TrimSpace(w.Body.String()) != "staging|staging" {
+		t.Fatal("Template not shown as expected", w.Body.String())
+		return
+	}
+	if len(c.rendered) != 1 {
+		t.Fatal("Output should have been cached", len(c.rendered))
+		return
+	}
+
+	w = httptest.NewRecorder()
+	c.Show(w, "help", "env", nil)
+	if strings.TrimSpace(w.Body.String()) != "staging|staging" {
+		t.Fatal("Cached output not shown as expected", w.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Changing a config field renders again.
+	c.Environment = "production"
+	w = httptest.NewRecorder()
+	c.Show(w, "help", "env", nil)
+	if strings.TrimSpace(w.Body.String()) != "production|" {
+		t.Fatal("Template not rendered again after config changed", w.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Modifying the cache busting file pairs in place renders again.
+	bc := NewFSConfig(fstest.MapFS{
+		"tpl/app/app.html": {Data: []byte(`{{index .CacheBustFiles "app.js"}}`)},
+	}, "tpl", []string{"app"})
+	bc.CacheBustingFilePairs = map[string]string{"app.js": "A1.app.js"}
+	bc.SubDirCacheBustingFilePairs = map[string]map[string]string{"app": {"x.js": "x.js"}}
+	err = bc.Build()
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	for _, tc := range []struct {
+		change   func()
+		expected string
+	}{
+		{func() {}, "A1.app.js"},
+		{func() { bc.CacheBustingFilePairs["app.js"] = "B2.app.js" }, "B2.app.js"},
+		{func() { bc.SubDirCacheBustingFilePairs["app"]["app.js"] = "C3.app.js" }, "C3.app.js"},
+		{func() { delete(bc.SubDirCacheBustingFilePairs["app"], "app.js") }, "B2.app.js"},
+	} {
+		tc.change()
+		w = httptest.NewRecorder()
+		bc.Show(w, "app", "app", nil)
+		if w.Body.String() != tc.expected {
+			t.Fatal("Cached output not rendered again after pairs changed", w.Body.String(), tc.expected)
+			return
+		}
+	}
+	if len(bc.rendered) == 0 {
+		t.Fatal("Output should have been cached")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Output not cached when injected data is provided.
+	c.rendered = nil
+	w = httptest.NewRecorder()
+	c.Show(w, "help", "env", "data")
+	if len(c.rendered) != 0 {
+		t.Fatal("Output should not have been cached", len(c.rendered))
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Output not cached when disabled.
+	c.DisableRenderCache = true
+	w = httptest.NewRecorder()
+	c.Show(w, "help", "env", nil)
+	if len(c.rendered) != 0 {
+		t.Fatal("Output should not have been cached", len(c.rendered))
+		return
+	}
+	c.DisableRenderCache = false
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Output not cached when templates use funcs from the config's FuncMap.
+	c = NewOnDiskConfig(base, subdirs)
+	c.FuncMap = template.FuncMap{
+		"isEnv": func(string) bool { return true },
+	}
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	w = httptest.NewRecorder()
+	c.Show(w, "help", "env", nil)
+	if strings.TrimSpace(w.Body.String()) != "|staging" {
+		t.Fatal("Template not shown as expected", w.Body.String())
+		return
+	}
+	if len(c.rendered) != 0 {
+		t.Fatal("Output should not have been cached", len(c.rendered))
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestRenderOnce(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	subdirs := []string{"help"}
+	c := NewOnDiskConfig(base, subdirs)
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	v, _, release := c.acquireView("help")
+	release()
+	key := c.renderCacheKey("help", v.named["env.html"])
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Requests made while rendering wait for and share the output.
+	call := &renderCall{}
+	call.wg.Add(1)
+	c.inflight = map[renderCacheKey]*renderCall{key: call}
+
+	done := make(chan []byte)
+	go func() {
+		b, _ := c.renderOnce(context.Background(), "help", key, c.renderData(renderView{}, "help", "", nil))
+		done <- b
+	}()
+
+	call.b = []byte("shared")
+	call.wg.Done()
+	if b := <-done; string(b) != "shared" {
+		t.Fatal("Output of in progress render not shared", string(b))
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Template rendered and cached when not in progress.
+	c.inflight = nil
+	b, err := c.renderOnce(context.Background(), "help", key, c.renderData(renderView{}, "help", "", nil))
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	if strings.TrimSpace(string(b)) != "|" {
+		t.Fatal("Template not rendered as expected", string(b))
+		return
+	}
+	if _, ok := c.rendered[key]; !ok || len(c.inflight) != 0 {
+		t.Fatal("Output should have been cached and render removed from in progress")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Requests render the template themselves if the shared render could not start.
+	c.rendered = nil
+	call = &renderCall{err: ErrRenderQueueTimeout}
+	c.inflight = map[renderCacheKey]*renderCall{key: call}
+	b, err = c.renderOnce(context.Background(), "help", key, c.renderData(renderView{}, "help", "", nil))
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	if strings.TrimSpace(string(b)) != "|" {
+		t.Fatal("Template not rendered as expected", string(b))
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//The shared render is not stopped by the context of the request that started it.
+	c.inflight = nil
+	c.renderSlots = make(chan struct{}, 1)
+	c.renderSlots <- struct{}{}
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		<-c.renderSlots
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = c.renderOnce(ctx, "help", key, c.renderData(renderView{}, "help", "", nil))
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,163 @@
+/*
+This file handles rendering templates to strings, writers, and files rather than to a
+user's browser. This is useful for rendering emails and webhook payloads, generating
+reports, cached HTML snapshots, or email archives, or testing your templates, using the
+same templates used to build your pages, outside of an http handler.
+
+Files are written atomically; the template is rendered to a temporary file in the same
+directory which is then renamed to the destination path. This way a partially written
+file is never seen at the destination path, even if rendering fails or your app exits
+while writing.
+*/
+
+package templates
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+//render renders a template as HTML to w. This works the same as Show() but without
+//any handling of HTTP requests or responses. Templates added with ParseUntrusted() are
+//rendered with their limits. An error is returned if the subdirectory or template
+//cannot be found or if an error occurs while executing the template.
+func (c *Config) render(w io.Writer, subdir, templateName string, injectedData interface{}) (err error) {
+	//Add the extension to the template name if needed, the same as Show().
+	if filepath.Ext(templateName) == "" {
+		templateName += "." + c.Extension
+	}
+
+	v, ok, releaseView := c.acquireView(subdir)
+	defer releaseView()
+	if !ok {
+		return ErrUnknownSubDir
+	}
+	tmpl, ok := v.named[templateName]
+	if !ok {
+		return ErrTemplateNotFound
+	}
+
+	err = c.validateData(subdir, templateName, injectedData)
+	if err != nil {
+		return
+	}
+
+	release, err := c.acquireRender(context.Background())
+	if err != nil {
+		return
+	}
+	defer release()
+
+	data := c.renderData(v, subdir, "", injectedData)
+
+	err = c.checkRequires(subdir, templateName, c.templateData(&data))
+	if err != nil {
+		return
+	}
+
+	//Render templates written by untrusted authors with their limits, from a copy, the
+	//same as when shown.
+	if limits, ok := v.untrustedLimits[templateName]; ok {
+		if v.untrusted == nil {
+			return ErrTemplateNotFound
+		}
+
+		clone, err := v.untrusted.Clone()
+		if err != nil {
+			return err
+		}
+
+		return c.executeLimited(w, clone, templateName, c.templateData(&data), limits)
+	}
+
+	return tmpl.Execute(c.limitOutput(w), c.templateData(&data))
+}
+
+//RenderToWriter renders a template as HTML to w. This works the same as Show() but
+//without an http.ResponseWriter, for example to render an email. An error is returned
+//if the subdirectory or template cannot be found or if an error occurs while executing
+//the template; part of the template may have been written to w if an error occurs.
+func (c *Config) RenderToWriter(w io.Writer, subdir, templateName string, injectedData interface{}) (err error) {
+	return c.render(w, subdir, templateName, injectedData)
+}
+
+//RenderToWriter renders a template as HTML to w using the default package level
+//config.
+func RenderToWriter(w io.Writer, subdir, templateName string, injectedData interface{}) (err error) {
+	return Default().RenderToWriter(w, subdir, templateName, injectedData)
+}
+
+//RenderToString renders a template as HTML and returns the output. This works the same
+//as RenderToWriter().
+func (c *Config) RenderToString(subdir, templateName string, injectedData interface{}) (s string, err error) {
+	var b bytes.Buffer
+	err = c.render(&b, subdir, templateName, injectedData)
+	if err != nil {
+		return
+	}
+
+	return b.String(), nil
+}
+
+//RenderToString renders a template as HTML and returns the output using the default
+//package level config.
+func RenderToString(subdir, templateName string, injectedData interface{}) (s string, err error) {
+	return Default().RenderToString(subdir, templateName, injectedData)
+}
+
+//RenderToFile renders a template as HTML and saves it to a file at path. The file is
+//written atomically so a partially rendered file is never saved at path. Any existing
+//file at path is replaced. The directory the file is saved to must already exist.
+func (c *Config) RenderToFile(path, subdir, templateName string, injectedData interface{}) (err error) {
+	//Create the temporary file in the same directory as the destination so that the
+	//rename is atomic; renaming across filesystems is not.
+	dir, name := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+	f, err := os.CreateTemp(dir, "."+name+".*.tmp")
+	if err != nil {
+		return
+	}
+
+	//Remove the temporary file if an error occurs.
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+
+	err = c.render(f, subdir, templateName, injectedData)
+	if err != nil {
+		return
+	}
+
+	//Make sure the file is written to disk before renaming.
+	err = f.Sync()
+	if err != nil {
+		return
+	}
+	err = f.Close()
+	if err != nil {
+		return
+	}
+
+	//Temporary files are created with permissions only allowing the owner to read
+	//the file. Use the typical permissions for a created file instead.
+	err = os.Chmod(f.Name(), 0644)
+	if err != nil {
+		return
+	}
+
+	return os.Rename(f.Name(), path)
+}
+
+//RenderToFile renders a template as HTML and saves it to a file using the default
+//package level config.
+func RenderToFile(path, subdir, templateName string, injectedData interface{}) (err error) {
+	return Default().RenderToFile(path, subdir, templateName, injectedData)
+}
@@ -0,0 +1,128 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenderToFile(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	subdirs := []string{"help"}
+	c := NewOnDiskConfig(base, subdirs)
+	c.Environment = "staging"
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	out := t.TempDir()
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Template rendered to file.
+	path := filepath.Join(out, "env.html")
+	err = c.RenderToFile(path, "help", "env", nil)
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if strings.TrimSpace(string(b)) != "staging|staging" {
+		t.Fatal("File not rendered as expected", string(b))
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Existing file not replaced, and no temporary file left, on error.
+	err = c.RenderToFile(path, "help", "not-found", nil)
+	if err != ErrTemplateNotFound {
+		t.Fatal("ErrTemplateNotFound should have occured but didn't", err)
+		return
+	}
+	entries, err := os.ReadDir(out)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if len(entries) != 1 {
+		t.Fatal("Temporary file should have been removed", entries)
+		return
+	}
+	b, err = os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if strings.TrimSpace(string(b)) != "staging|staging" {
+		t.Fatal("Existing file should not have been replaced", string(b))
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestRenderToString(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	subdirs := []string{"help"}
+	c := NewOnDiskConfig(base, subdirs)
+	c.Environment = "staging"
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Template rendered to string.
+	s, err := c.RenderToString("help", "env", nil)
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	if strings.TrimSpace(s) != "staging|staging" {
+		t.Fatal("Unexpected output", s)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Template rendered to writer.
+	var b strings.Builder
+	err = c.RenderToWriter(&b, "help", "env", nil)
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	if b.String() != s {
+		t.Fatal("Unexpected output", b.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Template does not exist.
+	_, err = c.RenderToString("help", "missing", nil)
+	if err != ErrTemplateNotFound {
+		t.Fatal("ErrTemplateNotFound should have occured but did not", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
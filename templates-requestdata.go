@@ -0,0 +1,167 @@
+/*
+This file handles providing data retrieved from the request being responded to, such
+as the user's session, to templates. This data is retrieved using providers set in
+your config so that each of your http handlers does not need to retrieve the same data
+and add it to the data provided when showing a template.
+
+Data from providers is only available when using ShowRequest(), since the request is
+needed to retrieve the data. Since this data differs per request, the output of
+templates shown to a request with providers set is never cached.
+
+The user making the request, retrieved using UserProvider, and the user's preferences,
+retrieved using PreferenceProvider, are retrieved once per request and stored in the
+request's context so that {{.User}}, {{.Prefs}}, and funcs such as isAuthenticated and
+prefersDark do not each retrieve the same data.
+*/
+
+package templates
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+//usesRequestData returns if data will be retrieved from the request using a provider.
+func (c *Config) usesRequestData(r *http.Request) bool {
+	if r == nil {
+		return false
+	}
+
+	return c.SessionProvider != nil || c.UserProvider != nil || c.PreferenceProvider != nil
+}
+
+//setRequestData retrieves data from the request using the providers set in the config
+//and saves the data to d. Errors are logged and the data is left blank so that the
+//page can still be shown.
+func (c *Config) setRequestData(d *RenderData, r *http.Request) {
+	if r == nil {
+		return
+	}
+
+	if c.SessionProvider != nil {
+		session, err := c.SessionProvider(r)
+		if err != nil {
+			log.Println("templates.Show: error getting session", "request_id="+d.RequestID, err)
+		} else {
+			d.Session = session
+		}
+	}
+
+	d.User = c.user(r)
+	d.Prefs = c.prefs(r)
+}
+
+//requestValuesContextKey is the key the values retrieved using providers are stored at
+//in a request's context.
+type requestValuesContextKey struct{}
+
+//requestValues are the values retrieved from a request using providers that are used
+//by both templates and request-scoped funcs. These are stored in the request's context
+//so that each provider is only called once per request.
+type requestValues struct {
+	user  interface{}
+	prefs map[string]string
+}
+
+//withRequestValues retrieves the values used by templates and request-scoped funcs
+//using the providers set in the config and returns a copy of the request with the
+//values stored in the request's context. The request is returned as is if no request
+//is known or no providers are set.
+func (c *Config) withRequestValues(r *http.Request) *http.Request {
+	if r == nil || (c.UserProvider == nil && c.PreferenceProvider == nil) {
+		return r
+	}
+	if _, ok := r.Context().Value(requestValuesContextKey{}).(requestValues); ok {
+		return r
+	}
+
+	v := requestValues{}
+	if c.UserProvider != nil {
+		v.user = c.UserProvider(r)
+	}
+	if c.PreferenceProvider != nil {
+		v.prefs = c.PreferenceProvider(r)
+	}
+
+	ctx := context.WithValue(r.Context(), requestValuesContextKey{}, v)
+	return r.WithContext(ctx)
+}
+
+//user returns the user making the request, retrieved using UserProvider. nil is
+//returned if no request is known or UserProvider is not set.
+func (c *Config) user(r *http.Request) interface{} {
+	if r == nil || c.UserProvider == nil {
+		return nil
+	}
+
+	if v, ok := r.Context().Value(requestValuesContextKey{}).(requestValues); ok {
+		return v.user
+	}
+
+	return c.UserProvider(r)
+}
+
+//prefs returns the preferences of the user making the request, retrieved using
+//PreferenceProvider. nil is returned if no request is known or PreferenceProvider is
+//not set.
+func (c *Config) prefs(r *http.Request) map[string]string {
+	if r == nil || c.PreferenceProvider == nil {
+		return nil
+	}
+
+	if v, ok := r.Context().Value(requestValuesContextKey{}).(requestValues); ok {
+		return v.prefs
+	}
+
+	return c.PreferenceProvider(r)
+}
+
+//RoleChecker is implemented by users, returned by UserProvider, that have roles. This
+//is used by the hasRole func to check if a user has a role.
+type RoleChecker interface {
+	HasRole(role string) bool
+}
+
+//FuncIsAuthenticated returns if a user is logged in. A user is not logged in if user is
+//nil, including a nil pointer or map.
+func FuncIsAuthenticated(user interface{}) bool {
+	if user == nil {
+		return false
+	}
+
+	v := reflect.ValueOf(user)
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface, reflect.Func, reflect.Chan:
+		return !v.IsNil()
+	}
+
+	return true
+}
+
+//FuncHasRole returns if a user has a role. The user must implement RoleChecker,
+//otherwise false is returned.
+func FuncHasRole(user interface{}, role string) bool {
+	if !FuncIsAuthenticated(user) {
+		return false
+	}
+
+	rc, ok := user.(RoleChecker)
+	if !ok {
+		return false
+	}
+
+	return rc.HasRole(role)
+}
+
+//ThemePreference is the key in the preferences returned by PreferenceProvider used by
+//the prefersDark func to determine the user's theme.
+const ThemePreference = "theme"
+
+//FuncPrefersDark returns if the user prefers a dark theme based on their preferences.
+//This checks if the ThemePreference key is set to "dark".
+func FuncPrefersDark(prefs map[string]string) bool {
+	return strings.EqualFold(prefs[ThemePreference], "dark")
+}
@@ -0,0 +1,257 @@
+package templates
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSessionProvider(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	subdirs := []string{"data"}
+	c := NewOnDiskConfig(base, subdirs)
+	c.SessionProvider = func(r *http.Request) (map[string]interface{}, error) {
+		if r.URL.Path == "/error" {
+			return nil, errors.New("invalid session cookie")
+		}
+		return map[string]interface{}{"user": r.URL.Query().Get("user")}, nil
+	}
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Session provided for each request.
+	for _, user := range []string{"jane", "john"} {
+		r := httptest.NewRequest(http.MethodGet, "/?user="+user, nil)
+		w := httptest.NewRecorder()
+		c.ShowRequest(w, r, "data", "session", nil)
+		if strings.TrimSpace(w.Body.String()) != user {
+			t.Fatal("Session not provided as expected", w.Body.String())
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Page shown without session on error.
+	r := httptest.NewRequest(http.MethodGet, "/error", nil)
+	w := httptest.NewRecorder()
+	c.ShowRequest(w, r, "data", "session", nil)
+	if w.Code != http.StatusOK {
+		t.Fatal("Error showing", w.Code, w.Body)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+type testUser struct {
+	Name  string
+	Roles []string
+}
+
+func (u *testUser) HasRole(role string) bool {
+	for _, r := range u.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+func TestUserProvider(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	subdirs := []string{"data"}
+	c := NewOnDiskConfig(base, subdirs)
+	calls := 0
+	c.UserProvider = func(r *http.Request) interface{} {
+		calls++
+		switch r.URL.Query().Get("user") {
+		case "jane":
+			return &testUser{Name: "jane", Roles: []string{"admin"}}
+		case "john":
+			return &testUser{Name: "john"}
+		}
+
+		var u *testUser
+		return u
+	}
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//User and roles provided for each request, user retrieved once per request.
+	tests := map[string]string{
+		"jane": "jane (admin)",
+		"john": "john",
+		"":     "guest",
+	}
+	for user, expected := range tests {
+		calls = 0
+		r := httptest.NewRequest(http.MethodGet, "/?user="+user, nil)
+		w := httptest.NewRecorder()
+		c.ShowRequest(w, r, "data", "user", nil)
+		if strings.TrimSpace(w.Body.String()) != expected {
+			t.Fatal("User not provided as expected", user, w.Body.String())
+			return
+		}
+		if calls != 1 {
+			t.Fatal("User should be retrieved once per request", calls)
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//No user when no request is known.
+	w := httptest.NewRecorder()
+	c.Show(w, "data", "user", nil)
+	if strings.TrimSpace(w.Body.String()) != "guest" {
+		t.Fatal("User should not be provided without a request", w.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestAuthorizer(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	subdirs := []string{"data"}
+	c := NewOnDiskConfig(base, subdirs)
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Denied when no authorizer is set.
+	r := httptest.NewRequest(http.MethodGet, "/?user=jane", nil)
+	w := httptest.NewRecorder()
+	c.ShowRequest(w, r, "data", "can", nil)
+	if strings.TrimSpace(w.Body.String()) != "denied" {
+		t.Fatal("Permission should be denied without an authorizer", w.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	c.UserProvider = func(r *http.Request) interface{} {
+		if r.URL.Query().Get("user") == "jane" {
+			return &testUser{Name: "jane", Roles: []string{"admin"}}
+		}
+		return nil
+	}
+	c.Authorizer = func(r *http.Request, user interface{}, permission string) bool {
+		return permission == "invoices:delete" && FuncHasRole(user, "admin")
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Allowed and denied per user.
+	tests := map[string]string{
+		"jane": "delete",
+		"john": "denied",
+	}
+	for user, expected := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/?user="+user, nil)
+		w := httptest.NewRecorder()
+		c.ShowRequest(w, r, "data", "can", nil)
+		if strings.TrimSpace(w.Body.String()) != expected {
+			t.Fatal("Permission not checked as expected", user, w.Body.String())
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Denied when no request is known.
+	w = httptest.NewRecorder()
+	c.Show(w, "data", "can", nil)
+	if strings.TrimSpace(w.Body.String()) != "denied" {
+		t.Fatal("Permission should be denied without a request", w.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestPreferenceProvider(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	subdirs := []string{"data"}
+	c := NewOnDiskConfig(base, subdirs)
+	calls := 0
+	c.PreferenceProvider = func(r *http.Request) map[string]string {
+		calls++
+		cookie, err := r.Cookie("theme")
+		if err != nil {
+			return map[string]string{"lang": "en"}
+		}
+		return map[string]string{ThemePreference: cookie.Value, "lang": "en"}
+	}
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Preferences provided for each request, retrieved once per request.
+	tests := map[string]string{
+		"dark":  "dark en",
+		"light": "light en",
+		"":      "light en",
+	}
+	for theme, expected := range tests {
+		calls = 0
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if theme != "" {
+			r.AddCookie(&http.Cookie{Name: "theme", Value: theme})
+		}
+		w := httptest.NewRecorder()
+		c.ShowRequest(w, r, "data", "prefs", nil)
+		if strings.TrimSpace(w.Body.String()) != expected {
+			t.Fatal("Preferences not provided as expected", theme, w.Body.String())
+			return
+		}
+		if calls != 1 {
+			t.Fatal("Preferences should be retrieved once per request", calls)
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,283 @@
+/*
+This file handles funcs that are bound to the request being responded to, request-scoped
+funcs. These funcs can use data from the request, such as the request's URL or context,
+without the data having to be provided to the template via InjectedData.
+
+Request-scoped funcs are only bound to a request when using ShowRequest(). When using
+Show(), or any other func that is not aware of the request, these funcs still exist but
+behave as if no request was provided.
+
+Since funcs are shared by every render of a set of templates, and golang templates
+cannot be copied once they have been executed, templates that use a request-scoped func
+are handed out to requests from a pool of copies. The request-scoped funcs are set once
+on each copy, when the copy is made, and read the request from the copy's binding which
+is set for each request. Copies are only made when no unused copy is available, not for
+each request. Templates are only copied if they use a request-scoped func.
+*/
+
+package templates
+
+import (
+	"context"
+	"html/template"
+	"net/http"
+	"sync"
+	"text/template/parse"
+)
+
+//requestBinding is the request read by the request-scoped funcs of a set of templates.
+//The request is nil when no request is known.
+type requestBinding struct {
+	r *http.Request
+}
+
+//requestFuncMap returns the request-scoped funcs reading the request from b each time a
+//func is called. Provide a blank binding when no request is known; this is used when
+//parsing templates so that the func names are known.
+func (c *Config) requestFuncMap(b *requestBinding) template.FuncMap {
+	return template.FuncMap{
+		"absoluteURL": func(path string) string {
+			return FuncAbsoluteURL(b.r, path, c.TrustProxyHeaders)
+		},
+		"flag": func(flag string) bool {
+			return c.flag(b.r, flag)
+		},
+		"isActive": func(href string) bool {
+			return FuncIsActive(requestPath(b.r), href)
+		},
+		"isActivePrefix": func(href string) bool {
+			return FuncIsActivePrefix(requestPath(b.r), href)
+		},
+		"nav": func(items []Nav) template.HTML {
+			return FuncNav(items, requestPath(b.r))
+		},
+		"isAuthenticated": func() bool {
+			return FuncIsAuthenticated(c.user(b.r))
+		},
+		"hasRole": func(role string) bool {
+			return FuncHasRole(c.user(b.r), role)
+		},
+		"can": func(permission string) bool {
+			return c.can(b.r, permission)
+		},
+		"prefersDark": func() bool {
+			return FuncPrefersDark(c.prefs(b.r))
+		},
+	}
+}
+
+//flag returns if a feature flag is enabled using the config's FeatureFlagFunc. The
+//request's context is provided to FeatureFlagFunc, or a background context if no
+//request is known. Flags are disabled if no FeatureFlagFunc is set.
+func (c *Config) flag(r *http.Request, flag string) bool {
+	if c.FeatureFlagFunc == nil {
+		return false
+	}
+
+	ctx := context.Background()
+	if r != nil {
+		ctx = r.Context()
+	}
+
+	return c.FeatureFlagFunc(ctx, flag)
+}
+
+//can returns if the user making the request has a permission using the config's
+//Authorizer. Permissions are denied if no Authorizer is set or no request is known.
+func (c *Config) can(r *http.Request, permission string) bool {
+	if c.Authorizer == nil || r == nil {
+		return false
+	}
+
+	return c.Authorizer(r, c.user(r), permission)
+}
+
+//requestPath returns the path of the request's URL. A blank string is returned if no
+//request is known.
+func requestPath(r *http.Request) string {
+	if r == nil || r.URL == nil {
+		return ""
+	}
+
+	return r.URL.Path
+}
+
+//requestFuncNames returns the names of request-scoped funcs that are not replaced by a
+//func in the config's FuncMap.
+func (c *Config) requestFuncNames() (names []string) {
+	for name := range c.requestFuncMap(&requestBinding{}) {
+		if _, ok := c.FuncMap[name]; ok {
+			continue
+		}
+
+		names = append(names, name)
+	}
+
+	return
+}
+
+//splitForRequestFuncs handles keeping a pool of copies of templates that use request-
+//scoped funcs. If t uses a request-scoped func, a copy of t is returned as executable,
+//to be used when no request is known, and a pool of copies of t for requests is
+//returned. If t does not use a request-scoped func, t is returned as executable and
+//requests is nil.
+func (c *Config) splitForRequestFuncs(t *template.Template) (executable *template.Template, requests *requestPool, err error) {
+	if !usesFuncs(t, c.requestFuncNames()) {
+		return t, nil, nil
+	}
+
+	executable, err = t.Clone()
+	if err != nil {
+		return
+	}
+
+	return c.bindTryTemplate(executable), &requestPool{c: c, unexecuted: t}, nil
+}
+
+//requestPool is a pool of copies of a subdirectory's templates that use request-scoped
+//funcs. Each copy is only used by one render at a time.
+type requestPool struct {
+	//c is the config the request-scoped funcs read values from.
+	c *Config
+
+	//unexecuted is the copy of the templates that is never executed so that it can be
+	//copied.
+	unexecuted *template.Template
+
+	//pool holds the copies not being used by a render.
+	pool sync.Pool
+}
+
+//boundTemplates is a copy of a subdirectory's templates with the request-scoped funcs
+//reading the request from binding.
+type boundTemplates struct {
+	t       *template.Template
+	binding *requestBinding
+}
+
+//get returns a copy of the templates with the request-scoped funcs bound to r. A new
+//copy is made if no unused copy is available. put must be called with the returned
+//copy once the render is complete.
+func (p *requestPool) get(r *http.Request) (*boundTemplates, error) {
+	if b, ok := p.pool.Get().(*boundTemplates); ok {
+		b.binding.r = r
+		return b, nil
+	}
+
+	clone, err := p.unexecuted.Clone()
+	if err != nil {
+		return nil, err
+	}
+
+	//Only set funcs that were not replaced by a func in the config's FuncMap.
+	binding := &requestBinding{r: r}
+	rfm := p.c.requestFuncMap(binding)
+	fm := make(template.FuncMap, len(rfm))
+	for _, name := range p.c.requestFuncNames() {
+		fm[name] = rfm[name]
+	}
+
+	return &boundTemplates{
+		t:       p.c.bindTryTemplate(clone.Funcs(fm)),
+		binding: binding,
+	}, nil
+}
+
+//put returns a copy of the templates to the pool once a render is complete.
+func (p *requestPool) put(b *boundTemplates) {
+	b.binding.r = nil
+	p.pool.Put(b)
+}
+
+//usesFuncs returns true if any template in t calls a func with one of the provided
+//names.
+func usesFuncs(t *template.Template, names []string) bool {
+	if len(names) == 0 {
+		return false
+	}
+
+	lookup := make(map[string]bool, len(names))
+	for _, n := range names {
+		lookup[n] = true
+	}
+
+	found := false
+	for _, tmpl := range t.Templates() {
+		if tmpl.Tree == nil {
+			continue
+		}
+
+		walkNodes(tmpl.Tree.Root, func(n parse.Node) {
+			if ident, ok := n.(*parse.IdentifierNode); ok && lookup[ident.Ident] {
+				found = true
+			}
+		})
+		if found {
+			return true
+		}
+	}
+
+	return false
+}
+
+//walkNodes calls fn for node and each node beneath it in a template's parse tree.
+func walkNodes(node parse.Node, fn func(parse.Node)) {
+	if node == nil {
+		return
+	}
+
+	//Nil pointers wrapped in the parse.Node interface are not nil so they must be
+	//checked for each type.
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		fn(n)
+		for _, child := range n.Nodes {
+			walkNodes(child, fn)
+		}
+	case *parse.ActionNode:
+		fn(n)
+		walkNodes(n.Pipe, fn)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		fn(n)
+		for _, v := range n.Decl {
+			walkNodes(v, fn)
+		}
+		for _, cmd := range n.Cmds {
+			walkNodes(cmd, fn)
+		}
+	case *parse.CommandNode:
+		fn(n)
+		for _, arg := range n.Args {
+			walkNodes(arg, fn)
+		}
+	case *parse.ChainNode:
+		fn(n)
+		walkNodes(n.Node, fn)
+	case *parse.IfNode:
+		fn(n)
+		walkNodes(n.Pipe, fn)
+		walkNodes(n.List, fn)
+		walkNodes(n.ElseList, fn)
+	case *parse.RangeNode:
+		fn(n)
+		walkNodes(n.Pipe, fn)
+		walkNodes(n.List, fn)
+		walkNodes(n.ElseList, fn)
+	case *parse.WithNode:
+		fn(n)
+		walkNodes(n.Pipe, fn)
+		walkNodes(n.List, fn)
+		walkNodes(n.ElseList, fn)
+	case *parse.TemplateNode:
+		fn(n)
+		walkNodes(n.Pipe, fn)
+	default:
+		fn(n)
+	}
+}
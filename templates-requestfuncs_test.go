@@ -0,0 +1,143 @@
+package templates
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFlagRequestFunc(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	type ctxKey string
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	subdirs := []string{"app", "help"}
+	c := NewOnDiskConfig(base, subdirs)
+	c.FeatureFlagFunc = func(ctx context.Context, flag string) bool {
+		return flag == "new-nav" && ctx.Value(ctxKey("beta")) == true
+	}
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Flag enabled based on request's context.
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r = r.WithContext(context.WithValue(r.Context(), ctxKey("beta"), true))
+	w := httptest.NewRecorder()
+	c.ShowRequest(w, r, "help", "flag", nil)
+	if strings.TrimSpace(w.Body.String()) != "new" {
+		t.Fatal("Flag should have been enabled", w.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Flag disabled for other requests.
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	w = httptest.NewRecorder()
+	c.ShowRequest(w, r, "help", "flag", nil)
+	if strings.TrimSpace(w.Body.String()) != "old" {
+		t.Fatal("Flag should have been disabled", w.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestIsActiveRequestFunc(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	subdirs := []string{"app", "help"}
+	c := NewOnDiskConfig(base, subdirs)
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Active page and section.
+	r := httptest.NewRequest(http.MethodGet, "/help/nav", nil)
+	w := httptest.NewRecorder()
+	c.ShowRequest(w, r, "help", "nav", nil)
+	if strings.TrimSpace(w.Body.String()) != "page|section" {
+		t.Fatal("Active state not determined as expected", w.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Nothing is active without a request.
+	w = httptest.NewRecorder()
+	c.Show(w, "help", "nav", nil)
+	if strings.TrimSpace(w.Body.String()) != "|" {
+		t.Fatal("Nothing should have been active", w.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestNavRequestFunc(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	subdirs := []string{"app", "help"}
+	c := NewOnDiskConfig(base, subdirs)
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	menu := []Nav{
+		{Label: "Home", Href: "/"},
+		{Label: "Help", Href: "/help", Children: []Nav{
+			{Label: "FAQ", Href: "/help/faq"},
+		}},
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Current page and its parents are marked active.
+	r := httptest.NewRequest(http.MethodGet, "/help/faq", nil)
+	w := httptest.NewRecorder()
+	c.ShowRequest(w, r, "help", "menu", menu)
+	if !strings.Contains(w.Body.String(), `<a href="/help/faq" aria-current="page">FAQ</a>`) {
+		t.Fatal("Current page not marked as expected", w.Body.String())
+		return
+	}
+	if strings.Count(w.Body.String(), `class="active"`) != 2 {
+		t.Fatal("Active section not marked as expected", w.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Nothing is active without a request.
+	w = httptest.NewRecorder()
+	c.Show(w, "help", "menu", menu)
+	if strings.Contains(w.Body.String(), "active") || !strings.Contains(w.Body.String(), `<a href="/help/faq">FAQ</a>`) {
+		t.Fatal("Nothing should have been active", w.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,142 @@
+/*
+This file handles checking that a template is provided the data it requires before it
+is rendered, in Development mode. Rendering a template that is missing data results in
+errors such as "executing at <.User.Name>: nil pointer evaluating *User.Name" that do
+not say what is missing or why. Instead, a template can declare the data it requires
+in a comment:
+	{{/* @requires .User .InjectedData.Items *\/}}
+Each path is relative to the data provided to the template, the same as within the
+template, i.e. the RenderData. Before rendering, each path is checked and, if a value
+along the path is missing or nil, an error naming the template and the path is
+returned instead of rendering.
+
+Requirements are only checked when Development is true since checking requires
+reading the template's file on each render. Since requirements are comments, they are
+ignored otherwise.
+*/
+
+package templates
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+//ErrMissingRequiredData is returned when data required by a template, declared with a
+//@requires comment, is missing or nil. The returned error wraps this error and names
+//the template and path to the missing data.
+var ErrMissingRequiredData = errors.New("templates: missing data required by template")
+
+//requiresDirective matches a comment declaring the data a template requires.
+var requiresDirective = regexp.MustCompile(`\{\{-?\s*/\*\s*@requires\s+([^*]*?)\s*\*/\s*-?\}\}`)
+
+//checkRequires checks that the data provided to a template includes each path the
+//template declares it requires. Nothing is checked unless Development is true.
+func (c *Config) checkRequires(subdir, templateName string, data interface{}) error {
+	if !c.Development {
+		return nil
+	}
+
+	src, err := c.templateSource(subdir, templateName)
+	if err != nil || src == "" {
+		return err
+	}
+
+	root := reflect.ValueOf(data)
+	for _, m := range requiresDirective.FindAllStringSubmatch(src, -1) {
+		for _, p := range strings.Fields(m[1]) {
+			if err := requirePath(root, p); err != nil {
+				return fmt.Errorf("%w: template '%s' in subdirectory '%s' requires %s but %v", ErrMissingRequiredData, templateName, subdir, p, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+//templateSource returns the source of a template, from the last file parsed into the
+//subdirectory's templates with the template's name or from the source added with
+//ParseUntrusted(). A blank string is returned if the template was not parsed from a
+//file or source, such as a template defined with {{define}}.
+func (c *Config) templateSource(subdir, templateName string) (src string, err error) {
+	if c.mu == nil {
+		return
+	}
+
+	c.mu.RLock()
+	paths := c.files[subdir]
+	s, isSource := c.sources[subdir][templateName]
+	c.mu.RUnlock()
+	if isSource {
+		return s.src, nil
+	}
+
+	for i := len(paths) - 1; i >= 0; i-- {
+		if filepath.Base(paths[i]) != templateName {
+			continue
+		}
+
+		b, err := c.readFile(paths[i])
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	}
+
+	return
+}
+
+//requirePath returns an error if a value along the path, i.e. .User.Name, in v is
+//missing or nil. Each name in the path must be a field of a struct or a key of a map;
+//methods are not called.
+func requirePath(v reflect.Value, path string) error {
+	path = strings.TrimPrefix(path, ".")
+	if path == "" {
+		if !isSet(v) {
+			return errors.New("data is nil")
+		}
+		return nil
+	}
+
+	walked := ""
+	for _, name := range strings.Split(path, ".") {
+		if !isSet(v) {
+			if walked == "" {
+				return errors.New("data is nil")
+			}
+			return errors.New(walked + " is nil")
+		}
+		v = indirect(v)
+		walked += "." + name
+
+		switch v.Kind() {
+		case reflect.Struct:
+			f := v.FieldByName(name)
+			if !f.IsValid() || !f.CanInterface() {
+				return errors.New(walked + " does not exist")
+			}
+			v = f
+		case reflect.Map:
+			if v.Type().Key().Kind() != reflect.String {
+				return errors.New(walked + " cannot be looked up, map keys are not strings")
+			}
+			f := v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key()))
+			if !f.IsValid() {
+				return errors.New(walked + " does not exist")
+			}
+			v = f
+		default:
+			return errors.New(walked + " cannot be looked up in " + v.Kind().String())
+		}
+	}
+
+	if !isSet(v) {
+		return errors.New(walked + " is nil")
+	}
+
+	return nil
+}
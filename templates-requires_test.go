@@ -0,0 +1,104 @@
+package templates
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type requiresUser struct {
+	Name string
+}
+
+type requiresData struct {
+	User  *requiresUser
+	Items []string
+}
+
+func TestCheckRequires(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	c := NewOnDiskConfig(base, []string{"requires"})
+	c.Development = true
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	w := httptest.NewRecorder()
+	c.Show(w, "requires", "profile", requiresData{User: &requiresUser{Name: "Bobby"}, Items: []string{"a"}})
+	if w.Code != http.StatusOK || strings.TrimSpace(w.Body.String()) != "Bobby: 1" {
+		t.Fatal("Template not shown as expected", w.Code, w.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	w = httptest.NewRecorder()
+	c.Show(w, "requires", "profile", requiresData{Items: []string{"a"}})
+	if w.Code != http.StatusInternalServerError || !strings.Contains(w.Body.String(), "requires .InjectedData.User but .InjectedData.User is nil") {
+		t.Fatal("Missing data not reported as expected", w.Code, w.Body.String())
+		return
+	}
+
+	var b strings.Builder
+	err = c.render(&b, "requires", "profile", requiresData{User: &requiresUser{}})
+	if !errors.Is(err, ErrMissingRequiredData) {
+		t.Fatal("ErrMissingRequiredData should have occured but didn't", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Not checked outside of Development.
+	c.Development = false
+	err = c.render(&b, "requires", "profile", requiresData{User: &requiresUser{}})
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestRequirePath(t *testing.T) {
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	data := map[string]interface{}{
+		"User":  &requiresUser{Name: "Bobby"},
+		"Empty": nil,
+	}
+	tests := map[string]string{
+		".User":         "",
+		".User.Name":    "",
+		".":             "",
+		".Empty":        ".Empty is nil",
+		".Empty.Name":   ".Empty is nil",
+		".Missing":      ".Missing does not exist",
+		".User.Missing": ".User.Missing does not exist",
+		".User.Name.X":  ".User.Name.X cannot be looked up in string",
+	}
+	for path, expected := range tests {
+		err := requirePath(reflect.ValueOf(data), path)
+		if (expected == "" && err != nil) || (expected != "" && (err == nil || err.Error() != expected)) {
+			t.Fatal("Result not as expected", path, err, expected)
+			return
+		}
+	}
+
+	if err := requirePath(reflect.ValueOf(nil), ".User"); err == nil || err.Error() != "data is nil" {
+		t.Fatal("Result not as expected", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
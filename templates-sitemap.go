@@ -0,0 +1,130 @@
+/*
+This file handles generating a sitemap.xml from the templates that were built. This is
+useful for documentation or marketing sites that are served by this package since each
+template in a subdirectory is a page that can be shown.
+
+A template can be excluded from the sitemap by adding the following comment anywhere in
+the template's file. This is useful for partials or pages that should not be indexed.
+	{{/* sitemap: false *\/}}
+*/
+
+package templates
+
+import (
+	"encoding/xml"
+	"io"
+	"net/url"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+//sitemapExcludeDirective matches the comment used to exclude a template from the
+//sitemap. Whitespace trimming markers are allowed.
+var sitemapExcludeDirective = regexp.MustCompile(`{{-?\s*/\*\s*sitemap:\s*false\s*\*/\s*-?}}`)
+
+//sitemapURLSet is the root element of a sitemap.
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+//sitemapURL is a page in a sitemap.
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+//GenerateSitemap writes a sitemap to w listing each page that can be shown. A page is
+//each template file in each subdirectory; files inherited from the base directory are
+//not pages. The URL to each page is built as baseURL/subdir/template, without the
+//extension, matching the arguments you would provide to Show(). The modification time
+//of each file is used as the page's last modification date for on-disk files.
+func (c *Config) GenerateSitemap(baseURL string, w io.Writer) (err error) {
+	if c.mu == nil {
+		return ErrUnknownSubDir
+	}
+
+	base, err := url.Parse(strings.TrimSpace(baseURL))
+	if err != nil {
+		return
+	}
+
+	//Get the list of pages. Inherited files are skipped.
+	c.mu.RLock()
+	inherited := make(map[string]bool)
+	for _, p := range c.files[""] {
+		inherited[p] = true
+	}
+
+	type page struct {
+		subdir string
+		path   string
+	}
+	var pages []page
+	for subdir, paths := range c.files {
+		if subdir == "" {
+			continue
+		}
+
+		for _, p := range paths {
+			if inherited[p] {
+				continue
+			}
+			pages = append(pages, page{subdir, p})
+		}
+	}
+	c.mu.RUnlock()
+
+	//Sort pages so that the sitemap is the same each time it is generated.
+	sort.Slice(pages, func(i, j int) bool {
+		if pages[i].subdir != pages[j].subdir {
+			return pages[i].subdir < pages[j].subdir
+		}
+		return pages[i].path < pages[j].path
+	})
+
+	set := sitemapURLSet{
+		XMLNS: "http://www.sitemaps.org/schemas/sitemap/0.9",
+	}
+	for _, p := range pages {
+		b, innerErr := c.readFile(p.path)
+		if innerErr != nil {
+			return innerErr
+		}
+		if sitemapExcludeDirective.Match(b) {
+			continue
+		}
+
+		name := strings.TrimSuffix(filepath.Base(p.path), "."+c.Extension)
+		loc := *base
+		loc.Path = path.Join("/", base.Path, filepath.ToSlash(p.subdir), name)
+
+		u := sitemapURL{
+			Loc: loc.String(),
+		}
+		if fi, innerErr := c.stat(p.path); innerErr == nil && !fi.ModTime().IsZero() {
+			u.LastMod = fi.ModTime().UTC().Format("2006-01-02")
+		}
+
+		set.URLs = append(set.URLs, u)
+	}
+
+	_, err = io.WriteString(w, xml.Header)
+	if err != nil {
+		return
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	err = enc.Encode(set)
+	return
+}
+
+//GenerateSitemap writes a sitemap using the default package level config.
+func GenerateSitemap(baseURL string, w io.Writer) (err error) {
+	return Default().GenerateSitemap(baseURL, w)
+}
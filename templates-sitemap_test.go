@@ -0,0 +1,47 @@
+package templates
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateSitemap(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	subdirs := []string{"app", "help"}
+	c := NewOnDiskConfig(base, subdirs)
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	b := bytes.Buffer{}
+	err = c.GenerateSitemap("https://example.com/site", &b)
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+
+	sitemap := b.String()
+	if !strings.Contains(sitemap, "<loc>https://example.com/site/app/app</loc>") {
+		t.Fatal("Page missing from sitemap", sitemap)
+		return
+	}
+	if strings.Contains(sitemap, "header") {
+		t.Fatal("Inherited file should not be in sitemap", sitemap)
+		return
+	}
+	if strings.Contains(sitemap, "help/request") {
+		t.Fatal("Excluded page should not be in sitemap", sitemap)
+		return
+	}
+}
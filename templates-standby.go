@@ -0,0 +1,202 @@
+/*
+This file handles building a standby set of templates alongside the templates being
+shown, the live set, for blue/green style deploys of templates. Rather than rebuilding
+straight into the templates being shown, new templates are built into a standby set,
+checked by rendering a list of smoke tests, and then promoted to be shown all at once.
+If building or a smoke test fails, the live set is never touched.
+
+A typical deploy:
+	//Write the new template files to BasePath, then...
+	err := c.BuildStandby()
+	if err != nil {
+		//handle error, live templates are unchanged
+	}
+	err = c.Promote()
+	if err != nil {
+		//a smoke test failed, live templates are unchanged
+	}
+
+SmokeTests in your config lists the templates, and data, to render against the standby
+set. A smoke test fails if the template does not exist or returns an error when
+rendered; output is not checked. Use templates that cover your most important pages.
+
+Promoting a standby set is the same as calling Build(): templates added with
+ParseExtra() or ParseUntrusted() are not kept.
+*/
+
+package templates
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+)
+
+//ErrNoStandby is returned when Promote() is called without a standby set of templates
+//having been built with BuildStandby().
+var ErrNoStandby = errors.New("templates: no standby templates, call BuildStandby() first")
+
+//SmokeTest is a template rendered against a standby set of templates before it is
+//promoted.
+type SmokeTest struct {
+	//SubDir is the subdirectory the template is in.
+	SubDir string
+
+	//Template is the name of the template, with or without the extension, the same as
+	//provided to Show().
+	Template string
+
+	//Data is the data to render the template with, the same as provided to Show().
+	Data interface{}
+}
+
+//BuildStandby builds the templates into a standby set that is not shown until it is
+//promoted with Promote(). Templates are built the same as with Build(). An existing
+//standby set is replaced.
+func (c *Config) BuildStandby() (err error) {
+	set, err := c.buildSet()
+	if err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	c.standby = set
+	c.mu.Unlock()
+	return
+}
+
+//BuildStandby builds a standby set of templates using the default package level config.
+func BuildStandby() (err error) {
+	err = Default().BuildStandby()
+	return
+}
+
+//SmokeTestStandby renders each of SmokeTests against the standby set of templates. The
+//error for the first smoke test that fails is returned.
+func (c *Config) SmokeTestStandby() (err error) {
+	if c.mu == nil {
+		return ErrNoStandby
+	}
+
+	c.mu.RLock()
+	set := c.standby
+	c.mu.RUnlock()
+	if set == nil {
+		return ErrNoStandby
+	}
+
+	return c.smokeTest(set)
+}
+
+//SmokeTestStandby renders the smoke tests against the standby set of templates using
+//the default package level config.
+func SmokeTestStandby() (err error) {
+	err = Default().SmokeTestStandby()
+	return
+}
+
+//Promote runs SmokeTests against the standby set of templates and, if all pass, shows
+//the standby set in place of the templates currently being shown. Templates are
+//replaced all at once so a request never sees a mix of the two sets. The standby set is
+//cleared once promoted.
+func (c *Config) Promote() (err error) {
+	if c.mu == nil {
+		return ErrNoStandby
+	}
+
+	c.mu.RLock()
+	set := c.standby
+	c.mu.RUnlock()
+	if set == nil {
+		return ErrNoStandby
+	}
+
+	err = c.smokeTest(set)
+	if err != nil {
+		return
+	}
+
+	//Make sure the standby set was not replaced while smoke testing.
+	c.mu.Lock()
+	if c.standby != set {
+		c.mu.Unlock()
+		return errors.New("templates: standby templates were replaced while smoke testing, promote again")
+	}
+	c.standby = nil
+	c.mu.Unlock()
+
+	c.activate(set)
+	c.audit(AuditEvent{Action: AuditPromote, Hash: set.hash})
+	return
+}
+
+//Promote promotes the standby set of templates using the default package level config.
+func Promote() (err error) {
+	err = Default().Promote()
+	return
+}
+
+//DiscardStandby removes the standby set of templates without promoting it.
+func (c *Config) DiscardStandby() {
+	if c.mu == nil {
+		return
+	}
+
+	c.mu.Lock()
+	c.standby = nil
+	c.mu.Unlock()
+}
+
+//DiscardStandby removes the standby set of templates using the default package level
+//config.
+func DiscardStandby() {
+	Default().DiscardStandby()
+}
+
+//StandbyHash returns the hash of the source files of the standby set of templates, see
+//Hash(). A blank string is returned if there is no standby set.
+func (c *Config) StandbyHash() string {
+	if c.mu == nil {
+		return ""
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.standby == nil {
+		return ""
+	}
+
+	return c.standby.hash
+}
+
+//StandbyHash returns the hash of the standby set of templates using the default package
+//level config.
+func StandbyHash() string {
+	return Default().StandbyHash()
+}
+
+//smokeTest renders each of SmokeTests against a set of templates.
+func (c *Config) smokeTest(set *templateSet) error {
+	for _, st := range c.SmokeTests {
+		name := st.Template
+		if filepath.Ext(name) == "" {
+			name += "." + c.Extension
+		}
+
+		tmpl := set.named[st.SubDir][name]
+		if tmpl == nil {
+			return fmt.Errorf("templates: smoke test of '%s' in subdirectory '%s' failed: %w", name, st.SubDir, ErrTemplateNotFound)
+		}
+
+		data := c.renderData(renderView{hash: set.hash}, st.SubDir, "", st.Data)
+		var b bytes.Buffer
+		err := tmpl.Execute(c.limitOutput(&b), c.templateData(&data))
+		if err != nil {
+			return fmt.Errorf("templates: smoke test of '%s' in subdirectory '%s' failed: %w", name, st.SubDir, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,135 @@
+package templates
+
+import (
+	"errors"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStandby(t *testing.T) {
+	base := t.TempDir()
+	err := os.Mkdir(filepath.Join(base, "app"), 0755)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	page := filepath.Join(base, "app", "page.html")
+	err = os.WriteFile(page, []byte("v1"), 0644)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	var events []AuditEvent
+	c := NewOnDiskConfig(base, []string{"app"})
+	c.AuditFunc = func(e AuditEvent) {
+		events = append(events, e)
+	}
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	err = c.Promote()
+	if err != ErrNoStandby {
+		t.Fatal("ErrNoStandby should have occured but didn't", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Build the standby set, the live set is unchanged.
+	err = os.WriteFile(page, []byte("v2 {{.InjectedData.Name}}"), 0644)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	err = c.BuildStandby()
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	if c.StandbyHash() == "" || c.StandbyHash() == c.Hash() {
+		t.Fatal("Standby hash not as expected", c.StandbyHash(), c.Hash())
+		return
+	}
+
+	w := httptest.NewRecorder()
+	c.Show(w, "app", "page", nil)
+	if w.Body.String() != "v1" {
+		t.Fatal("Live templates should not have changed", w.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Failing smoke tests prevent promoting.
+	c.SmokeTests = []SmokeTest{
+		{SubDir: "app", Template: "page", Data: map[string]string{"Name": "Bobby"}},
+		{SubDir: "app", Template: "missing.html"},
+	}
+	err = c.Promote()
+	if !errors.Is(err, ErrTemplateNotFound) {
+		t.Fatal("ErrTemplateNotFound should have occured but didn't", err)
+		return
+	}
+
+	c.SmokeTests = []SmokeTest{{SubDir: "app", Template: "page", Data: "not a map"}}
+	err = c.SmokeTestStandby()
+	if err == nil {
+		t.Fatal("Error should have occured rendering with bad data but didn't")
+		return
+	}
+
+	w = httptest.NewRecorder()
+	c.Show(w, "app", "page", nil)
+	if w.Body.String() != "v1" {
+		t.Fatal("Live templates should not have changed", w.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Promote.
+	c.SmokeTests = []SmokeTest{{SubDir: "app", Template: "page", Data: map[string]string{"Name": "Bobby"}}}
+	hash := c.StandbyHash()
+	err = c.Promote()
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	if c.Hash() != hash || c.StandbyHash() != "" {
+		t.Fatal("Standby templates not promoted as expected", c.Hash(), hash, c.StandbyHash())
+		return
+	}
+
+	w = httptest.NewRecorder()
+	c.Show(w, "app", "page", map[string]string{"Name": "Bobby"})
+	if w.Body.String() != "v2 Bobby" {
+		t.Fatal("Promoted templates not shown", w.Body.String())
+		return
+	}
+
+	if len(events) != 1 || events[0].Action != AuditPromote || events[0].Hash != hash {
+		t.Fatal("Promote not recorded as expected", events)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	err = c.BuildStandby()
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	c.DiscardStandby()
+	if c.StandbyHash() != "" {
+		t.Fatal("Standby templates should have been discarded")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
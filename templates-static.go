@@ -0,0 +1,135 @@
+/*
+This file handles reading static files, such as images or stylesheets, for inlining into
+templates. Static files are read from StaticPath, from on-disk or embedded files based
+upon the config, the same as template files. Files are cached after they are first read
+since static files are not expected to change while your app is running; the cache is
+cleared when templates are rebuilt.
+*/
+
+package templates
+
+import (
+	"errors"
+	"html/template"
+	"log"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+//ErrStaticPathNotSet is returned when a static file is read but no StaticPath is set.
+var ErrStaticPathNotSet = errors.New("templates: no value set for StaticPath")
+
+//ErrInvalidStaticFile is returned when the name of a static file is invalid, for example
+//when the name refers to a file outside of StaticPath.
+var ErrInvalidStaticFile = errors.New("templates: invalid static file name")
+
+//readStaticFile returns the contents of a file in StaticPath. The name of the file is
+//relative to StaticPath and must use forward slashes, i.e.: "icons/check.svg". Files are
+//cached after being read.
+func (c *Config) readStaticFile(name string) (b []byte, err error) {
+	if strings.TrimSpace(c.StaticPath) == "" {
+		return nil, ErrStaticPathNotSet
+	}
+
+	//Make sure the file is within StaticPath.
+	name = path.Clean("/" + strings.TrimSpace(name))[1:]
+	if name == "" {
+		return nil, ErrInvalidStaticFile
+	}
+
+	if c.mu != nil {
+		c.mu.RLock()
+		b, ok := c.staticFiles[name]
+		c.mu.RUnlock()
+		if ok {
+			return b, nil
+		}
+	}
+
+	b, err = c.readFile(filepath.Join(c.StaticPath, filepath.FromSlash(name)))
+	if err != nil {
+		return
+	}
+
+	if c.mu != nil {
+		c.mu.Lock()
+		if c.staticFiles == nil {
+			c.staticFiles = make(map[string][]byte)
+		}
+		c.staticFiles[name] = b
+		c.mu.Unlock()
+	}
+
+	return
+}
+
+//svgRootTag matches the opening <svg> tag of an SVG.
+var svgRootTag = regexp.MustCompile(`(?is)<svg\b[^>]*>`)
+
+//svgSizeAttr matches width and height attributes in an <svg> tag.
+var svgSizeAttr = regexp.MustCompile(`(?is)\s(?:width|height)\s*=\s*(?:"[^"]*"|'[^']*')`)
+
+//svgClassAttr matches the class attribute in an <svg> tag.
+var svgClassAttr = regexp.MustCompile(`(?is)\sclass\s*=\s*"([^"]*)"`)
+
+//svgPrologue matches the XML declaration, DOCTYPE, and comments that precede the <svg>
+//tag in an SVG file. These are not needed, and the XML declaration is not allowed, when
+//inlining an SVG into HTML.
+var svgPrologue = regexp.MustCompile(`(?is)^.*?(<svg\b)`)
+
+//inlineSVG returns the contents of an SVG file in StaticPath for inlining into HTML. If
+//a class is provided, the width and height attributes are removed from the <svg> tag,
+//so that the SVG can be sized with CSS, and the class is added to the <svg> tag.
+func (c *Config) inlineSVG(name string, class ...string) template.HTML {
+	b, err := c.readStaticFile(name)
+	if err != nil {
+		//we log out here so that user can identify issue since otherwise tracking down
+		//an error like this is very difficult.
+		log.Println("templates.inlineSVG", "could not read file", name, err)
+		return ""
+	}
+
+	svg := svgPrologue.ReplaceAllString(string(b), "$1")
+	cls := strings.TrimSpace(strings.Join(class, " "))
+	if cls == "" {
+		return template.HTML(svg)
+	}
+
+	loc := svgRootTag.FindStringIndex(svg)
+	if loc == nil {
+		log.Println("templates.inlineSVG", "file is not an svg", name)
+		return ""
+	}
+
+	tag := svg[loc[0]:loc[1]]
+	tag = svgSizeAttr.ReplaceAllString(tag, "")
+	escaped := template.HTMLEscapeString(cls)
+	if m := svgClassAttr.FindStringSubmatchIndex(tag); m != nil {
+		tag = tag[:m[3]] + " " + escaped + tag[m[3]:]
+	} else {
+		tag = "<svg" + ` class="` + escaped + `"` + tag[len("<svg"):]
+	}
+
+	return template.HTML(svg[:loc[0]] + tag + svg[loc[1]:])
+}
+
+//styleEndTag matches a closing </style> tag.
+var styleEndTag = regexp.MustCompile(`(?i)</style`)
+
+//inlineCSS returns the contents of a CSS file in StaticPath wrapped in a <style> block.
+//This is used for inlining critical CSS into the <head> of a page. Any closing </style>
+//tags in the file are escaped so that the file cannot end the <style> block early.
+func (c *Config) inlineCSS(name string) template.HTML {
+	b, err := c.readStaticFile(name)
+	if err != nil {
+		//we log out here so that user can identify issue since otherwise tracking down
+		//an error like this is very difficult.
+		log.Println("templates.inlineCSS", "could not read file", name, err)
+		return ""
+	}
+
+	css := styleEndTag.ReplaceAllString(string(b), `<\/style`)
+	return template.HTML("<style>" + css + "</style>")
+}
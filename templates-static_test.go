@@ -0,0 +1,93 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInlineSVG(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	subdirs := []string{"app", "help"}
+	c := NewOnDiskConfig(base, subdirs)
+	c.StaticPath = filepath.Join(dir, "_testdata", "static")
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//SVG inlined as is, without XML declaration.
+	svg := string(c.inlineSVG("icons/check.svg"))
+	if !strings.HasPrefix(svg, "<svg") {
+		t.Fatal("XML declaration not removed", svg)
+		return
+	}
+	if !strings.Contains(svg, `width="24"`) {
+		t.Fatal("Width should not have been removed", svg)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//SVG with class.
+	svg = string(c.inlineSVG("icons/check.svg", "icon", "icon-check"))
+	if !strings.HasPrefix(svg, `<svg class="icon icon-check" xmlns=`) {
+		t.Fatal("Class not added", svg)
+		return
+	}
+	if strings.Contains(svg, `width="24"`) || strings.Contains(svg, `height="24"`) {
+		t.Fatal("Width and height should have been removed", svg)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Files outside of StaticPath cannot be read.
+	_, err = c.readStaticFile("../templates/header.html")
+	if err == nil {
+		t.Fatal("Error should have occured but didn't")
+		return
+	}
+	if svg := c.inlineSVG("icons/non-existant.svg"); svg != "" {
+		t.Fatal("Nothing should have been returned for missing file", svg)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestInlineCSS(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	subdirs := []string{"app", "help"}
+	c := NewOnDiskConfig(base, subdirs)
+	c.StaticPath = filepath.Join(dir, "_testdata", "static")
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	css := string(c.inlineCSS("css/styles.min.css"))
+	if !strings.HasPrefix(css, "<style>") || !strings.HasSuffix(css, "</style>") {
+		t.Fatal("CSS not wrapped in style block", css)
+		return
+	}
+	if _, ok := c.staticFiles["css/styles.min.css"]; !ok {
+		t.Fatal("CSS file not cached")
+		return
+	}
+}
@@ -0,0 +1,91 @@
+/*
+This file handles showing error pages, such as 404 Not Found or 403 Forbidden, from
+your templates so that they use the same layout as the rest of your pages rather than
+each being written by an ad-hoc handler. Set StatusTemplates in your config to the
+template to show for each status code, then call ShowStatusPage() from your handlers:
+	c.StatusTemplates = map[int]templates.TemplateRef{
+		http.StatusNotFound:            {SubDir: "errors", Template: "404"},
+		http.StatusInternalServerError: {SubDir: "errors", Template: "500"},
+	}
+	...
+	c.ShowStatusPage(w, r, http.StatusNotFound, nil)
+
+If no template is set for a status code, a plain text response with the status code's
+text is written instead, the same as http.Error(). Since an error page may be shown
+because something failed, the template should not rely on data that may be missing.
+*/
+
+package templates
+
+import (
+	"net/http"
+)
+
+//TemplateRef identifies a template to show.
+type TemplateRef struct {
+	//SubDir is the subdirectory the template is in.
+	SubDir string
+
+	//Template is the name of the template, with or without the extension, the same as
+	//provided to Show().
+	Template string
+}
+
+//ShowStatusPage shows the template set in StatusTemplates for the HTTP status code,
+//responding with the status code. The data is provided to the template the same as
+//with ShowRequest(). If no template is set for the status code, a plain text response
+//is written instead. The request, r, may be nil if the request is not known.
+func (c *Config) ShowStatusPage(w http.ResponseWriter, r *http.Request, code int, data interface{}) {
+	ref, ok := c.StatusTemplates[code]
+	if !ok {
+		http.Error(w, http.StatusText(code), code)
+		return
+	}
+
+	//Error pages must always be rendered, not responded to as not modified.
+	if r != nil {
+		r = r.Clone(r.Context())
+		r.Header.Del("If-None-Match")
+		r.Header.Del("If-Modified-Since")
+	}
+
+	c.show(&statusWriter{ResponseWriter: w, code: code}, r, ref.SubDir, ref.Template, data)
+}
+
+//ShowStatusPage shows the template for an HTTP status code using the default package
+//level config.
+func ShowStatusPage(w http.ResponseWriter, r *http.Request, code int, data interface{}) {
+	Default().ShowStatusPage(w, r, code, data)
+}
+
+//statusWriter is an http.ResponseWriter that responds with a status code other than
+//200 OK when a template is shown successfully. If showing the template fails, the
+//error's status code is used instead.
+type statusWriter struct {
+	http.ResponseWriter
+	code        int
+	wroteHeader bool
+}
+
+//WriteHeader implements http.ResponseWriter. Headers that would allow caching the
+//error page are removed.
+func (sw *statusWriter) WriteHeader(code int) {
+	if sw.wroteHeader {
+		return
+	}
+	sw.wroteHeader = true
+
+	sw.Header().Del("Cache-Control")
+	sw.Header().Del("Last-Modified")
+	sw.ResponseWriter.WriteHeader(code)
+}
+
+//Write implements http.ResponseWriter, responding with the status code if no status
+//code was written yet.
+func (sw *statusWriter) Write(b []byte) (int, error) {
+	if !sw.wroteHeader {
+		sw.WriteHeader(sw.code)
+	}
+
+	return sw.ResponseWriter.Write(b)
+}
@@ -0,0 +1,62 @@
+package templates
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestShowStatusPage(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	c := NewOnDiskConfig(base, []string{"status"})
+	c.StatusTemplates = map[int]TemplateRef{
+		http.StatusNotFound:            {SubDir: "status", Template: "404"},
+		http.StatusInternalServerError: {SubDir: "status", Template: "missing"},
+	}
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	r := httptest.NewRequest(http.MethodGet, "/nope", nil)
+	r.Header.Set("If-Modified-Since", "Mon, 01 Jan 2035 00:00:00 GMT")
+	w := httptest.NewRecorder()
+	c.ShowStatusPage(w, r, http.StatusNotFound, "/nope")
+	if w.Code != http.StatusNotFound || w.Body.String() != "<p>Not found: /nope</p>" {
+		t.Fatal("Status page not shown as expected", w.Code, w.Body.String())
+		return
+	}
+	if w.Header().Get("Last-Modified") != "" {
+		t.Fatal("Status page should not be cacheable", w.Header())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//No template for the status code.
+	w = httptest.NewRecorder()
+	c.ShowStatusPage(w, nil, http.StatusForbidden, nil)
+	if w.Code != http.StatusForbidden || w.Body.String() != "Forbidden\n" {
+		t.Fatal("Plain text response not as expected", w.Code, w.Body.String())
+		return
+	}
+
+	//Template does not exist, the error's status code is used.
+	w = httptest.NewRecorder()
+	c.ShowStatusPage(w, nil, http.StatusInternalServerError, nil)
+	if w.Code != http.StatusNotFound {
+		t.Fatal("Error status code not as expected", w.Code, w.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
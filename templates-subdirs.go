@@ -0,0 +1,87 @@
+/*
+This file handles expanding subdirectories listed in SubDirs using glob patterns, for
+example "docs/*". Patterns are expanded each time templates are built so that adding a
+new subdirectory that matches a pattern does not require a code change; the new
+subdirectory is found the next time Build() or Rebuild() is called.
+
+Patterns use the syntax of path.Match and are relative to BasePath. Only directories
+that match a pattern are used; files are ignored. A pattern that matches nothing is not
+an error since a family of subdirectories may be empty at first.
+*/
+
+package templates
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+//isGlob returns if a subdirectory is a glob pattern rather than a single subdirectory.
+func isGlob(subdir string) bool {
+	return strings.ContainsAny(subdir, "*?[")
+}
+
+//subDirs returns the subdirectories to build, with any glob patterns in SubDirs
+//expanded to the matching subdirectories. Each subdirectory is only returned once.
+func (c *Config) subDirs() (subdirs []string, err error) {
+	seen := make(map[string]bool, len(c.SubDirs))
+	for _, s := range c.SubDirs {
+		if !isGlob(s) {
+			if !seen[s] {
+				seen[s] = true
+				subdirs = append(subdirs, s)
+			}
+			continue
+		}
+
+		matches, innerErr := c.globSubDirs(s)
+		if innerErr != nil {
+			return nil, innerErr
+		}
+
+		for _, m := range matches {
+			if !seen[m] {
+				seen[m] = true
+				subdirs = append(subdirs, m)
+			}
+		}
+	}
+
+	return
+}
+
+//globSubDirs returns the subdirectories, relative to BasePath, matching a pattern.
+//Matches are sorted so that subdirectories are always built in the same order.
+func (c *Config) globSubDirs(pattern string) (subdirs []string, err error) {
+	var matches []string
+	if fsys := c.fsys(); fsys != nil {
+		matches, err = fs.Glob(fsys, filepath.ToSlash(filepath.Join(c.BasePath, pattern)))
+	} else {
+		matches, err = filepath.Glob(filepath.Join(c.BasePath, filepath.FromSlash(pattern)))
+	}
+	if err != nil {
+		return
+	}
+
+	for _, m := range matches {
+		var fi fs.FileInfo
+		fi, err = c.stat(m)
+		if err != nil {
+			return nil, err
+		}
+		if !fi.IsDir() {
+			continue
+		}
+
+		rel, innerErr := filepath.Rel(c.BasePath, filepath.FromSlash(m))
+		if innerErr != nil {
+			return nil, innerErr
+		}
+		subdirs = append(subdirs, rel)
+	}
+
+	sort.Strings(subdirs)
+	return
+}
@@ -0,0 +1,69 @@
+package templates
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGlobSubDirs(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Pattern expanded for on-disk files.
+	base := filepath.Join(dir, "_testdata", "templates")
+	subdirs := []string{"help", "reports/*"}
+	c := NewOnDiskConfig(base, subdirs)
+	err = c.Build()
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+
+	for _, s := range []string{"help", filepath.Join("reports", "daily"), filepath.Join("reports", "monthly")} {
+		if _, ok := c.files[s]; !ok {
+			t.Fatal("Subdirectory not built", s, c.files)
+			return
+		}
+	}
+
+	w := httptest.NewRecorder()
+	c.Show(w, filepath.Join("reports", "monthly"), "monthly", nil)
+	if w.Code != http.StatusOK {
+		t.Fatal("Error showing", w.Code, w.Body)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Pattern expanded for embedded files.
+	base = filepath.Join("_testdata", "templates")
+	subdirs = []string{"reports/*"}
+	c = NewEmbeddedConfig(embeddedFiles, base, subdirs)
+	found, err := c.subDirs()
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	if len(found) != 2 || found[0] != filepath.Join("reports", "daily") || found[1] != filepath.Join("reports", "monthly") {
+		t.Fatal("Pattern not expanded as expected", found)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Pattern matching nothing is not an error.
+	c = NewOnDiskConfig(filepath.Join(dir, "_testdata", "templates"), []string{"help", "non-existant-*"})
+	err = c.Build()
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
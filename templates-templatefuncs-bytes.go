@@ -0,0 +1,107 @@
+/*
+This file defines template level functions for displaying sizes in bytes and for parsing
+sizes entered by users. These are used together so that a size shown in a template and
+a size echoed back after a user entered it are displayed the same way.
+
+Sizes use binary units, i.e. 1 KB is 1024 bytes, since this is how most operating
+systems display file sizes.
+*/
+
+package templates
+
+import (
+	"log"
+	"math"
+	"strconv"
+	"strings"
+)
+
+//byteUnits is the list of units sizes are displayed in, in increasing order.
+var byteUnits = []string{"B", "KB", "MB", "GB", "TB", "PB", "EB"}
+
+//FuncFormatBytes returns a size in bytes in a human readable format, i.e. "1.5 MB".
+//Sizes are shown with at most one decimal place. The output can be parsed with
+//FuncParseBytes.
+//
+//In templates, this is available as formatBytes and is used as {{formatBytes .Size}}.
+func FuncFormatBytes(n int64) string {
+	sign := ""
+	size := float64(n)
+	if n < 0 {
+		sign = "-"
+		size = -size
+	}
+
+	unit := 0
+	for size >= 1024 && unit < len(byteUnits)-1 {
+		size /= 1024
+		unit++
+	}
+
+	//Round before formatting so that, i.e. 1023.96 KB, is shown as 1 MB rather than
+	//1024 KB.
+	size = math.Round(size*10) / 10
+	if size >= 1024 && unit < len(byteUnits)-1 {
+		size /= 1024
+		unit++
+	}
+
+	return sign + strconv.FormatFloat(size, 'f', -1, 64) + " " + byteUnits[unit]
+}
+
+//FuncParseBytes returns the number of bytes in a size provided in a human readable
+//format, i.e. "1.5 MB", "1.5MB", "1.5M", or "1.5 MiB". Units are case insensitive and
+//a size without a unit is in bytes. Fractional bytes are rounded. 0 is returned and the
+//error is logged if the size cannot be parsed.
+//
+//In templates, this is available as parseBytes and is used as
+//{{formatBytes (parseBytes .Form.Quota)}}.
+func FuncParseBytes(s string) int64 {
+	n, err := parseBytes(s)
+	if err != nil {
+		log.Println("templates.FuncParseBytes", err)
+		return 0
+	}
+
+	return n
+}
+
+//parseBytes returns the number of bytes in a size provided in a human readable format.
+func parseBytes(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+
+	//Split the number from the unit.
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.' && r != '-' && r != '+'
+	})
+	if i == -1 {
+		i = len(s)
+	}
+	number, unit := s[:i], strings.ToUpper(strings.TrimSpace(s[i:]))
+
+	size, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	//Handle the long, i.e. "KiB", and short, i.e. "K", forms of each unit.
+	unit = strings.TrimSuffix(strings.Replace(unit, "IB", "B", 1), "B")
+
+	multiplier := -1.0
+	for idx, u := range byteUnits {
+		if unit == strings.TrimSuffix(u, "B") {
+			multiplier = math.Pow(1024, float64(idx))
+			break
+		}
+	}
+	if multiplier < 0 {
+		return 0, strconv.ErrSyntax
+	}
+
+	size = math.Round(size * multiplier)
+	if size >= math.MaxInt64 || size < math.MinInt64 {
+		return 0, strconv.ErrRange
+	}
+
+	return int64(size), nil
+}
@@ -0,0 +1,63 @@
+package templates
+
+import (
+	"testing"
+)
+
+func TestFuncFormatBytes(t *testing.T) {
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	tests := map[int64]string{
+		0:                  "0 B",
+		512:                "512 B",
+		1024:               "1 KB",
+		1536:               "1.5 KB",
+		1048575:            "1 MB",
+		5 * 1024 * 1024:    "5 MB",
+		-2048:              "-2 KB",
+		1 << 62:            "4 EB",
+		1024*1024*1024 + 1: "1 GB",
+	}
+	for n, expected := range tests {
+		if s := FuncFormatBytes(n); s != expected {
+			t.Fatal("Size not formatted as expected", n, s, expected)
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestFuncParseBytes(t *testing.T) {
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	tests := map[string]int64{
+		"512":     512,
+		"512 B":   512,
+		"1.5 KB":  1536,
+		"1.5kb":   1536,
+		"1.5K":    1536,
+		"2 MiB":   2 * 1024 * 1024,
+		" 1 gb ":  1024 * 1024 * 1024,
+		"-2 KB":   -2048,
+		"1.5 XB":  0,
+		"abc":     0,
+		"":        0,
+		"9999 EB": 0,
+	}
+	for s, expected := range tests {
+		if n := FuncParseBytes(s); n != expected {
+			t.Fatal("Size not parsed as expected", s, n, expected)
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Formatted sizes parse to the same size when formatted again.
+	for _, n := range []int64{0, 1000, 1536, 123456789, 1 << 40} {
+		s := FuncFormatBytes(n)
+		if again := FuncFormatBytes(FuncParseBytes(s)); again != s {
+			t.Fatal("Size not echoed consistently", n, s, again)
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,506 @@
+/*
+This file defines template level functions for working with slices and maps. These
+handle common needs, such as sorting a list of rows by a column or grouping rows by a
+value, in templates rather than having to prepare the data in each of your http
+handlers.
+
+Fields are looked up by name on structs, or pointers to structs, and by key on maps with
+string keys. A field can be a path to a nested field, i.e. "User.Name".
+*/
+
+package templates
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+//Group is a set of items with the same value for a field, returned by FuncGroupBy.
+type Group struct {
+	//Key is the value of the field the items were grouped by.
+	Key interface{}
+
+	//Items is the items with the Key value for the field, in the order provided.
+	Items []interface{}
+}
+
+//FuncKeys returns the keys of a map sorted in ascending order. This is useful since,
+//while {{range}} sorts map keys, you cannot otherwise get a map's keys in templates.
+//nil is returned if m is not a map.
+//
+//In templates, this is available as keys and is used as {{range keys .Settings}}.
+func FuncKeys(m interface{}) []interface{} {
+	v := reflect.ValueOf(m)
+	if v.Kind() != reflect.Map {
+		log.Println("templates.FuncKeys", "value is not a map", v.Kind())
+		return nil
+	}
+
+	keys := sortedMapKeys(v)
+	out := make([]interface{}, len(keys))
+	for i, k := range keys {
+		out[i] = k.Interface()
+	}
+
+	return out
+}
+
+//FuncValues returns the values of a map in the order of the map's sorted keys. nil is
+//returned if m is not a map.
+//
+//In templates, this is available as values and is used as {{range values .Totals}}.
+func FuncValues(m interface{}) []interface{} {
+	v := reflect.ValueOf(m)
+	if v.Kind() != reflect.Map {
+		log.Println("templates.FuncValues", "value is not a map", v.Kind())
+		return nil
+	}
+
+	keys := sortedMapKeys(v)
+	out := make([]interface{}, len(keys))
+	for i, k := range keys {
+		out[i] = v.MapIndex(k).Interface()
+	}
+
+	return out
+}
+
+//sortedMapKeys returns the keys of the map v sorted in ascending order.
+func sortedMapKeys(v reflect.Value) []reflect.Value {
+	keys := v.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return compareValues(keys[i], keys[j]) < 0
+	})
+
+	return keys
+}
+
+//FuncSortBy returns a copy of list sorted by the value of field in each item. Prefix
+//field with "-" to sort in descending order. Items with equal values are kept in the
+//order provided. nil is returned if list is not a slice or field does not exist.
+//
+//In templates, this is available as sortBy and is used as
+//{{range sortBy .Users "-CreatedAt"}}.
+func FuncSortBy(list interface{}, field string) []interface{} {
+	items, ok := sliceItems(list)
+	if !ok {
+		log.Println("templates.FuncSortBy", "value is not a slice", reflect.ValueOf(list).Kind())
+		return nil
+	}
+
+	descending := strings.HasPrefix(field, "-")
+	field = strings.TrimPrefix(field, "-")
+
+	values := make([]reflect.Value, len(items))
+	for i, item := range items {
+		v, err := fieldValue(item, field)
+		if err != nil {
+			log.Println("templates.FuncSortBy", err)
+			return nil
+		}
+		values[i] = v
+	}
+
+	//Sort indexes so that the items and their values stay paired.
+	idx := make([]int, len(items))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool {
+		c := compareValues(values[idx[i]], values[idx[j]])
+		if descending {
+			return c > 0
+		}
+		return c < 0
+	})
+
+	sorted := make([]interface{}, len(items))
+	for i, n := range idx {
+		sorted[i] = items[n].Interface()
+	}
+
+	return sorted
+}
+
+//FuncGroupBy returns the items in list grouped by the value of field in each item.
+//Groups are returned in the order each value first appears so that a list can be sorted
+//with FuncSortBy and then grouped. nil is returned if list is not a slice or field does
+//not exist.
+//
+//In templates, this is available as groupBy and is used as
+//{{range groupBy .Orders "Status"}}<h2>{{.Key}}</h2>{{range .Items}}...{{end}}{{end}}.
+func FuncGroupBy(list interface{}, field string) []Group {
+	items, ok := sliceItems(list)
+	if !ok {
+		log.Println("templates.FuncGroupBy", "value is not a slice", reflect.ValueOf(list).Kind())
+		return nil
+	}
+
+	var groups []Group
+	positions := make(map[interface{}]int)
+	for _, item := range items {
+		v, err := fieldValue(item, field)
+		if err != nil {
+			log.Println("templates.FuncGroupBy", err)
+			return nil
+		}
+
+		//Values that cannot be used as map keys, i.e. slices, are grouped by their
+		//printed value.
+		key := v.Interface()
+		if !v.Type().Comparable() {
+			key = fmt.Sprint(key)
+		}
+
+		pos, ok := positions[key]
+		if !ok {
+			pos = len(groups)
+			positions[key] = pos
+			groups = append(groups, Group{Key: v.Interface()})
+		}
+		groups[pos].Items = append(groups[pos].Items, item.Interface())
+	}
+
+	return groups
+}
+
+//FuncFirst returns the first item in list. nil is returned if list is empty or is not a
+//slice.
+//
+//In templates, this is available as first and is used as {{with first .Posts}}.
+func FuncFirst(list interface{}) interface{} {
+	items, ok := sliceItems(list)
+	if !ok || len(items) == 0 {
+		return nil
+	}
+
+	return items[0].Interface()
+}
+
+//FuncLast returns the last item in list. nil is returned if list is empty or is not a
+//slice.
+//
+//In templates, this is available as last and is used as {{with last .Posts}}.
+func FuncLast(list interface{}) interface{} {
+	items, ok := sliceItems(list)
+	if !ok || len(items) == 0 {
+		return nil
+	}
+
+	return items[len(items)-1].Interface()
+}
+
+//FuncSlice returns the items in list from start up to, but not including, end. Unlike
+//the slice func built into templates, start and end are limited to the length of list
+//rather than causing an error, so {{subslice .Posts 0 3}} returns at most 3 items even
+//if fewer exist. A negative end means the end of list. nil is returned if list is not a
+//slice.
+//
+//In templates, this is available as subslice and is used as
+//{{range subslice .Posts 0 3}}...{{end}}{{if gt (len .Posts) 3}}<a href="/posts">More</a>{{end}}.
+func FuncSlice(list interface{}, start, end int) []interface{} {
+	items, ok := sliceItems(list)
+	if !ok {
+		log.Println("templates.FuncSlice", "value is not a slice", reflect.ValueOf(list).Kind())
+		return nil
+	}
+
+	if end < 0 || end > len(items) {
+		end = len(items)
+	}
+	if start < 0 {
+		start = 0
+	}
+	if start > end {
+		start = end
+	}
+
+	out := make([]interface{}, 0, end-start)
+	for _, item := range items[start:end] {
+		out = append(out, item.Interface())
+	}
+
+	return out
+}
+
+//FuncChunk returns the items in list split into chunks of n items. The last chunk has
+//fewer than n items if the length of list is not a multiple of n. This is useful for
+//building rows of a grid. nil is returned if n is less than 1 or list is not a slice.
+//
+//In templates, this is available as chunk and is used as
+//{{range chunk .Cards 3}}<div class="row">{{range .}}...{{end}}</div>{{end}}.
+func FuncChunk(list interface{}, n int) [][]interface{} {
+	items, ok := sliceItems(list)
+	if !ok {
+		log.Println("templates.FuncChunk", "value is not a slice", reflect.ValueOf(list).Kind())
+		return nil
+	}
+	if n < 1 {
+		log.Println("templates.FuncChunk", "invalid chunk size", n)
+		return nil
+	}
+
+	chunks := make([][]interface{}, 0, (len(items)+n-1)/n)
+	for i, item := range items {
+		if i%n == 0 {
+			chunks = append(chunks, make([]interface{}, 0, n))
+		}
+
+		last := len(chunks) - 1
+		chunks[last] = append(chunks[last], item.Interface())
+	}
+
+	return chunks
+}
+
+//FuncList returns the provided items as a slice. This is used to build a list in
+//templates, i.e. to provide to FuncIn.
+//
+//In templates, this is available as list and is used as {{list "active" "trial"}}.
+func FuncList(items ...interface{}) []interface{} {
+	return items
+}
+
+//FuncIn returns if needle is an item in haystack. Numbers are compared by value
+//regardless of type, i.e. an int and an int64, and strings are compared to strings of
+//any named string type. False is returned if haystack is not a slice.
+//
+//In templates, this is available as in and is used as
+//{{if in .Status (list "active" "trial")}}.
+func FuncIn(needle, haystack interface{}) bool {
+	items, ok := sliceItems(haystack)
+	if !ok {
+		log.Println("templates.FuncIn", "haystack is not a slice", reflect.ValueOf(haystack).Kind())
+		return false
+	}
+
+	n := reflect.ValueOf(needle)
+	for _, item := range items {
+		if valuesEqual(n, item) {
+			return true
+		}
+	}
+
+	return false
+}
+
+//FuncHas returns if haystack contains needle. This is the same as FuncIn but reads
+//naturally when haystack is piped in, since the piped value is provided last.
+//
+//In templates, this is available as has and is used as {{if .Roles | has "admin"}}.
+func FuncHas(needle, haystack interface{}) bool {
+	return FuncIn(needle, haystack)
+}
+
+//valuesEqual returns if a and b are the same value. Numbers are compared by value and
+//strings are compared regardless of their named type.
+func valuesEqual(a, b reflect.Value) bool {
+	a, b = indirect(a), indirect(b)
+
+	switch {
+	case !isSet(a) || !isSet(b):
+		return !isSet(a) && !isSet(b)
+	case isNumber(a) && isNumber(b):
+		return compareValues(a, b) == 0
+	case a.Kind() == reflect.String && b.Kind() == reflect.String:
+		return a.String() == b.String()
+	case a.Type() == b.Type() && a.Type().Comparable():
+		return a.Interface() == b.Interface()
+	}
+
+	return false
+}
+
+//sliceItems returns each item in a slice or array. False is returned if list is not a
+//slice or array.
+func sliceItems(list interface{}) (items []reflect.Value, ok bool) {
+	v := reflect.ValueOf(list)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, false
+	}
+
+	items = make([]reflect.Value, v.Len())
+	for i := range items {
+		items[i] = v.Index(i)
+	}
+
+	return items, true
+}
+
+//fieldValue returns the value of a field, or path to a nested field separated by
+//periods, in item. item can be a struct, a map with string keys, or a pointer to
+//either. The zero value is returned for keys that do not exist in a map so that maps
+//missing a key can still be sorted or grouped.
+func fieldValue(item reflect.Value, field string) (reflect.Value, error) {
+	return lookupField(item, field, true)
+}
+
+//lookupField returns the value of a field, or path to a nested field, in item. If
+//zeroMissing is false, an error is returned for keys that do not exist in a map.
+func lookupField(item reflect.Value, field string, zeroMissing bool) (reflect.Value, error) {
+	v := item
+	for _, name := range strings.Split(field, ".") {
+		v = indirect(v)
+
+		switch v.Kind() {
+		case reflect.Struct:
+			f := v.FieldByName(name)
+			if !f.IsValid() || !f.CanInterface() {
+				return reflect.Value{}, fmt.Errorf("field %q does not exist", field)
+			}
+			v = f
+		case reflect.Map:
+			if v.Type().Key().Kind() != reflect.String {
+				return reflect.Value{}, fmt.Errorf("map keys are not strings for field %q", field)
+			}
+			f := v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key()))
+			if !f.IsValid() {
+				if !zeroMissing {
+					return reflect.Value{}, fmt.Errorf("key %q does not exist", field)
+				}
+				f = reflect.Zero(v.Type().Elem())
+			}
+			v = f
+		default:
+			return reflect.Value{}, fmt.Errorf("cannot get field %q of %s", field, v.Kind())
+		}
+	}
+
+	return indirect(v), nil
+}
+
+//indirect returns the value v points to, or holds if v is an interface. v is returned
+//as is if it is a nil pointer or interface.
+func indirect(v reflect.Value) reflect.Value {
+	for (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	return v
+}
+
+//compareValues returns -1, 0, or 1 if a is less than, equal to, or greater than b.
+//Numbers, strings, bools, and times are compared by value; other values are compared
+//by their printed value. Invalid values, i.e. nil, are less than all other values.
+func compareValues(a, b reflect.Value) int {
+	a, b = indirect(a), indirect(b)
+
+	switch {
+	case !isSet(a) && !isSet(b):
+		return 0
+	case !isSet(a):
+		return -1
+	case !isSet(b):
+		return 1
+	}
+
+	if ta, ok := a.Interface().(time.Time); ok {
+		if tb, ok := b.Interface().(time.Time); ok {
+			switch {
+			case ta.Before(tb):
+				return -1
+			case ta.After(tb):
+				return 1
+			}
+			return 0
+		}
+	}
+
+	switch {
+	case isInt(a) && isInt(b):
+		switch {
+		case a.Int() < b.Int():
+			return -1
+		case a.Int() > b.Int():
+			return 1
+		}
+		return 0
+	case isUint(a) && isUint(b):
+		switch {
+		case a.Uint() < b.Uint():
+			return -1
+		case a.Uint() > b.Uint():
+			return 1
+		}
+		return 0
+	case isNumber(a) && isNumber(b):
+		return compareFloats(toFloat(a), toFloat(b))
+	case a.Kind() == reflect.String && b.Kind() == reflect.String:
+		return strings.Compare(a.String(), b.String())
+	case a.Kind() == reflect.Bool && b.Kind() == reflect.Bool:
+		return compareFloats(boolToFloat(a.Bool()), boolToFloat(b.Bool()))
+	}
+
+	return strings.Compare(fmt.Sprint(a.Interface()), fmt.Sprint(b.Interface()))
+}
+
+//isSet returns if v is a valid, non-nil, value.
+func isSet(v reflect.Value) bool {
+	if !v.IsValid() {
+		return false
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+		return !v.IsNil()
+	}
+
+	return v.CanInterface()
+}
+
+//isInt returns if v is a signed integer.
+func isInt(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+	return false
+}
+
+//isUint returns if v is an unsigned integer.
+func isUint(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
+//isNumber returns if v is an integer or a float.
+func isNumber(v reflect.Value) bool {
+	return isInt(v) || isUint(v) || v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64
+}
+
+//toFloat returns the number v as a float.
+func toFloat(v reflect.Value) float64 {
+	switch {
+	case isInt(v):
+		return float64(v.Int())
+	case isUint(v):
+		return float64(v.Uint())
+	}
+	return v.Float()
+}
+
+//boolToFloat returns 1 for true and 0 for false so bools can be ordered.
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+//compareFloats returns -1, 0, or 1 if a is less than, equal to, or greater than b.
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,263 @@
+package templates
+
+import (
+	"bytes"
+	"html/template"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type collectionsTestRow struct {
+	Name    string
+	Status  string
+	Amount  float64
+	Created time.Time
+	Owner   *collectionsTestOwner
+}
+
+type collectionsTestOwner struct {
+	Name string
+}
+
+func TestFuncKeysValues(t *testing.T) {
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	m := map[string]int{"c": 3, "a": 1, "b": 2}
+	if k := FuncKeys(m); !reflect.DeepEqual(k, []interface{}{"a", "b", "c"}) {
+		t.Fatal("Keys not returned as expected", k)
+		return
+	}
+	if v := FuncValues(m); !reflect.DeepEqual(v, []interface{}{1, 2, 3}) {
+		t.Fatal("Values not returned as expected", v)
+		return
+	}
+
+	//Numeric keys are sorted as numbers.
+	n := map[int]string{10: "ten", 2: "two", -1: "minus one"}
+	if k := FuncKeys(n); !reflect.DeepEqual(k, []interface{}{-1, 2, 10}) {
+		t.Fatal("Keys not returned as expected", k)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	if k := FuncKeys([]string{"a"}); k != nil {
+		t.Fatal("nil should be returned for a value that is not a map", k)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestFuncSortBy(t *testing.T) {
+	now := time.Now()
+	rows := []collectionsTestRow{
+		{Name: "b", Amount: 2, Created: now, Owner: &collectionsTestOwner{"y"}},
+		{Name: "c", Amount: 1, Created: now.Add(-time.Hour), Owner: &collectionsTestOwner{"z"}},
+		{Name: "a", Amount: 2, Created: now.Add(time.Hour), Owner: &collectionsTestOwner{"x"}},
+	}
+
+	names := func(items []interface{}) (s string) {
+		for _, item := range items {
+			s += item.(collectionsTestRow).Name
+		}
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	tests := map[string]string{
+		"Name":       "abc",
+		"-Name":      "cba",
+		"Amount":     "cba",
+		"-Amount":    "bac",
+		"Created":    "cba",
+		"Owner.Name": "abc",
+	}
+	for field, expected := range tests {
+		if s := names(FuncSortBy(rows, field)); s != expected {
+			t.Fatal("Not sorted as expected", field, s, expected)
+			return
+		}
+	}
+	if rows[0].Name != "b" {
+		t.Fatal("Provided list should not be modified")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Maps can be sorted by key.
+	maps := []map[string]interface{}{{"n": 3}, {"n": 1}, {"n": 2}}
+	sorted := FuncSortBy(maps, "n")
+	if sorted[0].(map[string]interface{})["n"] != 1 || sorted[2].(map[string]interface{})["n"] != 3 {
+		t.Fatal("Maps not sorted as expected", sorted)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	if s := FuncSortBy(rows, "Missing"); s != nil {
+		t.Fatal("nil should be returned for a field that does not exist", s)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestFuncGroupBy(t *testing.T) {
+	rows := []*collectionsTestRow{
+		{Name: "a", Status: "open"},
+		{Name: "b", Status: "closed"},
+		{Name: "c", Status: "open"},
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	groups := FuncGroupBy(rows, "Status")
+	if len(groups) != 2 || groups[0].Key != "open" || groups[1].Key != "closed" {
+		t.Fatal("Groups not returned as expected", groups)
+		return
+	}
+	if len(groups[0].Items) != 2 || groups[0].Items[1].(*collectionsTestRow).Name != "c" {
+		t.Fatal("Group items not returned as expected", groups[0].Items)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Used in a template.
+	tmpl := template.Must(template.New("").Funcs(DefaultFuncMap()).Parse(
+		`{{range groupBy (sortBy . "Name") "Status"}}{{.Key}}:{{range .Items}}{{.Name}}{{end}};{{end}}`,
+	))
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, rows); err != nil {
+		t.Fatal(err)
+		return
+	}
+	if b.String() != "open:ac;closed:b;" {
+		t.Fatal("Template output not as expected", b.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestFuncFirstLast(t *testing.T) {
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	list := []string{"a", "b", "c"}
+	if f := FuncFirst(list); f != "a" {
+		t.Fatal("First item not returned as expected", f)
+		return
+	}
+	if l := FuncLast(list); l != "c" {
+		t.Fatal("Last item not returned as expected", l)
+		return
+	}
+	if f := FuncFirst([]string{}); f != nil {
+		t.Fatal("nil should be returned for an empty list", f)
+		return
+	}
+	if l := FuncLast(nil); l != nil {
+		t.Fatal("nil should be returned for a value that is not a slice", l)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestFuncSlice(t *testing.T) {
+	list := []int{1, 2, 3, 4, 5}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	tests := []struct {
+		start, end int
+		expected   []interface{}
+	}{
+		{0, 3, []interface{}{1, 2, 3}},
+		{3, -1, []interface{}{4, 5}},
+		{0, 10, []interface{}{1, 2, 3, 4, 5}},
+		{-2, 1, []interface{}{1}},
+		{7, 9, []interface{}{}},
+	}
+	for _, tt := range tests {
+		if s := FuncSlice(list, tt.start, tt.end); !reflect.DeepEqual(s, tt.expected) {
+			t.Fatal("Slice not returned as expected", tt.start, tt.end, s)
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestFuncChunk(t *testing.T) {
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	chunks := FuncChunk([]int{1, 2, 3, 4, 5}, 2)
+	expected := [][]interface{}{{1, 2}, {3, 4}, {5}}
+	if !reflect.DeepEqual(chunks, expected) {
+		t.Fatal("Chunks not returned as expected", chunks)
+		return
+	}
+	if c := FuncChunk([]int{1, 2}, 0); c != nil {
+		t.Fatal("nil should be returned for an invalid chunk size", c)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Used in a template.
+	tmpl := template.Must(template.New("").Funcs(DefaultFuncMap()).Parse(
+		`{{range chunk (subslice . 0 5) 2}}[{{range .}}{{.}}{{end}}]{{end}}`,
+	))
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, []int{1, 2, 3, 4, 5, 6, 7}); err != nil {
+		t.Fatal(err)
+		return
+	}
+	if b.String() != "[12][34][5]" {
+		t.Fatal("Template output not as expected", b.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestFuncIn(t *testing.T) {
+	type status string
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	if !FuncIn("trial", FuncList("active", "trial")) {
+		t.Fatal("String should be in list")
+		return
+	}
+	if FuncIn("closed", []string{"active", "trial"}) {
+		t.Fatal("String should not be in list")
+		return
+	}
+	if !FuncIn(status("active"), []string{"active"}) {
+		t.Fatal("Named string type should be in list")
+		return
+	}
+	if !FuncIn(int64(2), []int{1, 2, 3}) {
+		t.Fatal("Number should be in list regardless of type")
+		return
+	}
+	if FuncIn("2", []int{1, 2, 3}) {
+		t.Fatal("String should not equal a number")
+		return
+	}
+	if FuncIn("a", "abc") {
+		t.Fatal("False should be returned for a haystack that is not a slice")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Used in a template.
+	tmpl := template.Must(template.New("").Funcs(DefaultFuncMap()).Parse(
+		`{{if in .Status (list "active" "trial")}}yes{{end}}{{if .Roles | has "admin"}}admin{{end}}`,
+	))
+	var b bytes.Buffer
+	data := map[string]interface{}{"Status": "trial", "Roles": []string{"user", "admin"}}
+	if err := tmpl.Execute(&b, data); err != nil {
+		t.Fatal(err)
+		return
+	}
+	if b.String() != "yesadmin" {
+		t.Fatal("Template output not as expected", b.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
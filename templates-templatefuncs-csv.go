@@ -0,0 +1,31 @@
+/*
+This file defines template level functions for building CSV and TSV files from text
+templates. See RenderText() and RenderCSV(). These funcs are always available to
+templates, you do not need to add them to your FuncMap.
+*/
+
+package templates
+
+import (
+	"fmt"
+	"strings"
+)
+
+//FuncCSVEscape returns a value escaped for use as a field in a CSV file. Fields that
+//contain a comma, double quote, or newline are wrapped in double quotes with any double
+//quotes doubled, per RFC 4180. Values that are not strings are formatted with fmt.
+func FuncCSVEscape(v interface{}) string {
+	s := fmt.Sprint(v)
+	if !strings.ContainsAny(s, ",\"\r\n") {
+		return s
+	}
+
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+//FuncTSVEscape returns a value escaped for use as a field in a TSV file. Tabs and
+//newlines are not allowed in TSV fields so they are replaced by spaces. Values that are
+//not strings are formatted with fmt.
+func FuncTSVEscape(v interface{}) string {
+	return strings.NewReplacer("\t", " ", "\r\n", " ", "\n", " ", "\r", " ").Replace(fmt.Sprint(v))
+}
@@ -0,0 +1,25 @@
+package templates
+
+import "testing"
+
+func TestFuncCSVEscape(t *testing.T) {
+	if s := FuncCSVEscape("plain"); s != "plain" {
+		t.Fatal("Plain value should not have been modified", s)
+		return
+	}
+	if s := FuncCSVEscape(`a "quoted", value`); s != `"a ""quoted"", value"` {
+		t.Fatal("Value not escaped as expected", s)
+		return
+	}
+	if s := FuncCSVEscape(12); s != "12" {
+		t.Fatal("Number not formatted as expected", s)
+		return
+	}
+}
+
+func TestFuncTSVEscape(t *testing.T) {
+	if s := FuncTSVEscape("a\tb\nc"); s != "a b c" {
+		t.Fatal("Value not escaped as expected", s)
+		return
+	}
+}
@@ -0,0 +1,173 @@
+/*
+This file defines template level functions for escaping values and for truncating text.
+
+html/template escapes values based on where they are used in a template. However, funcs
+that build markup, i.e. funcs returning template.HTML, bypass this escaping and must
+escape any values they use themselves. The escaping funcs here are for these cases; in
+templates, values are already escaped for you and these funcs are not needed.
+*/
+
+package templates
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+//FuncEscapeAttr escapes a value for use in a quoted HTML attribute value, i.e.
+//`<a title="` + FuncEscapeAttr(title) + `">`. Use this in funcs that build markup.
+func FuncEscapeAttr(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+
+	for _, r := range s {
+		switch r {
+		case '&':
+			b.WriteString("&amp;")
+		case '<':
+			b.WriteString("&lt;")
+		case '>':
+			b.WriteString("&gt;")
+		case '"':
+			b.WriteString("&#34;")
+		case '\'':
+			b.WriteString("&#39;")
+		case '`':
+			b.WriteString("&#96;")
+		case 0:
+			b.WriteString("\uFFFD")
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
+
+//FuncEscapeJSString escapes a value for use inside of a quoted JavaScript string, i.e.
+//`<script>var name = "` + FuncEscapeJSString(name) + `";</script>`. Quotes of any type
+//are escaped so the string can be quoted with ", ', or `. Characters that could end the
+//<script> element or the string, such as < and line breaks, are escaped as well. Use
+//this in funcs that build markup.
+func FuncEscapeJSString(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+
+	for _, r := range s {
+		switch r {
+		case '\\':
+			b.WriteString(`\\`)
+		case '"':
+			b.WriteString(`\"`)
+		case '\'':
+			b.WriteString(`\'`)
+		case '`':
+			b.WriteString("\\`")
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '<', '>', '&', '$', '\u2028', '\u2029':
+			//< and > prevent ending the <script> element or starting an HTML comment, &
+			//prevents entities being decoded when used in an event handler attribute, $
+			//prevents template literal substitution, and line and paragraph separators
+			//end strings in older JavaScript engines.
+			writeJSUnicodeEscape(&b, r)
+		default:
+			if r < 0x20 || r == 0x7F {
+				writeJSUnicodeEscape(&b, r)
+				continue
+			}
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
+
+//writeJSUnicodeEscape writes a rune as a JavaScript \uXXXX escape. Only used for runes
+//in the basic multilingual plane.
+func writeJSUnicodeEscape(b *strings.Builder, r rune) {
+	hex := strconv.FormatInt(int64(r), 16)
+	b.WriteString(`\u`)
+	b.WriteString(strings.Repeat("0", 4-len(hex)))
+	b.WriteString(strings.ToUpper(hex))
+}
+
+//FuncTruncate shortens text to at most n characters, adding an ellipsis if the text
+//was shortened. Characters are counted as a user sees them so that emoji made up of
+//many code points, such as flags, skin tones, or families, and letters with combining
+//accents are never split. Since text is truncated before it is escaped, HTML entities
+//are never split either. The ellipsis counts towards n.
+//
+//In templates, this is available as truncate and is used as {{truncate .Summary 140}}.
+func FuncTruncate(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+
+	const ellipsis = "\u2026"
+
+	//Find where each character starts.
+	var starts []int
+	for i := 0; i < len(s); {
+		starts = append(starts, i)
+		i += characterLen(s[i:])
+		if len(starts) > n {
+			break
+		}
+	}
+	if len(starts) <= n {
+		return s
+	}
+
+	return strings.TrimRightFunc(s[:starts[n-1]], unicode.IsSpace) + ellipsis
+}
+
+//characterLen returns the number of bytes used by the first character, as a user sees
+//it, in s. This is an approximation of a grapheme cluster that handles emoji sequences,
+//regional indicator pairs (flags), and combining marks.
+func characterLen(s string) int {
+	r, size := utf8.DecodeRuneInString(s)
+	i := size
+
+	//Flags are a pair of regional indicators.
+	if isRegionalIndicator(r) {
+		if next, nextSize := utf8.DecodeRuneInString(s[i:]); isRegionalIndicator(next) {
+			i += nextSize
+		}
+	}
+
+	for i < len(s) {
+		next, nextSize := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case next == '\u200d':
+			//Zero width joiner, the following rune is part of this character.
+			i += nextSize
+			if i < len(s) {
+				_, joinedSize := utf8.DecodeRuneInString(s[i:])
+				i += joinedSize
+			}
+		case unicode.In(next, unicode.Mn, unicode.Me),
+			next >= 0xFE00 && next <= 0xFE0F,
+			next >= 0x1F3FB && next <= 0x1F3FF,
+			next >= 0xE0020 && next <= 0xE007F:
+			//Combining marks, variation selectors, skin tone modifiers, and tags.
+			i += nextSize
+		default:
+			return i
+		}
+	}
+
+	return i
+}
+
+//isRegionalIndicator returns if r is a regional indicator symbol, used in pairs to
+//build flag emoji.
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1F1E6 && r <= 0x1F1FF
+}
@@ -0,0 +1,67 @@
+package templates
+
+import (
+	"testing"
+)
+
+func TestFuncEscapeAttr(t *testing.T) {
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	in := `"><script>alert('x')</script>` + "`&\x00"
+	expected := `&#34;&gt;&lt;script&gt;alert(&#39;x&#39;)&lt;/script&gt;&#96;&amp;` + "�"
+	if out := FuncEscapeAttr(in); out != expected {
+		t.Fatal("Not escaped as expected", out)
+		return
+	}
+	if out := FuncEscapeAttr("Café 👍"); out != "Café 👍" {
+		t.Fatal("Safe characters should not be escaped", out)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestFuncEscapeJSString(t *testing.T) {
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	tests := map[string]string{
+		`it's "quoted"`:      `it\'s \"quoted\"`,
+		"`${x}`":             "\\`\\u0024{x}\\`",
+		"</script><!--":      `\u003C/script\u003E\u003C!--`,
+		"a\\b\nc\r\td\u2028": `a\\b\nc\r\td\u2028`,
+		"\x01 & \U0001F44D":  "\\u0001 \\u0026 \U0001F44D",
+	}
+	for in, expected := range tests {
+		if out := FuncEscapeJSString(in); out != expected {
+			t.Fatal("Not escaped as expected", in, out, expected)
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestFuncTruncate(t *testing.T) {
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	type test struct {
+		in       string
+		n        int
+		expected string
+	}
+	tests := []test{
+		{"Hello world", 20, "Hello world"},
+		{"Hello world", 11, "Hello world"},
+		{"Hello world", 7, "Hello…"},
+		{"Hello world", 0, ""},
+		{"Tom & Jerry", 6, "Tom &…"},
+		{"Café au lait", 5, "Café…"},
+		{"Café au lait", 5, "Café…"},
+		{"👍🏽👍🏽👍🏽", 2, "👍🏽…"},
+		{"👨‍👩‍👧 family", 2, "👨‍👩‍👧…"},
+		{"🇺🇸🇨🇦🇲🇽", 2, "🇺🇸…"},
+		{"❤️❤️❤️", 2, "❤️…"},
+	}
+	for _, tt := range tests {
+		if out := FuncTruncate(tt.in, tt.n); out != tt.expected {
+			t.Fatal("Not truncated as expected", tt.in, tt.n, out, tt.expected)
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,135 @@
+/*
+This file defines a template level function for building a plain text excerpt of
+content written in markdown or HTML. This is used for listing pages, i.e. a list of blog
+posts, and for meta descriptions where markup would be shown as is or is not allowed.
+
+Markdown is not fully parsed. Common syntax, such as headings, emphasis, links, images,
+lists, quotes, and code, is removed so the text reads naturally. HTML is tokenized and
+only text is kept; the contents of <script> and <style> elements are removed.
+*/
+
+package templates
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+var (
+	//markdownImage matches a markdown image, i.e. ![alt](src), which is removed.
+	markdownImage = regexp.MustCompile(`!\[[^\]]*\]\([^)]*\)`)
+
+	//markdownLink matches a markdown link, i.e. [text](href), which is replaced by its
+	//text.
+	markdownLink = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+
+	//markdownEmphasis matches bold, italic, strikethrough, or code text, which is
+	//replaced by the text without the markers. Markers must be next to the text, the
+	//same as markdown, so that underscores in words, i.e. snake_case, are kept. Longer
+	//markers are matched first so that ** is not matched as *.
+	markdownEmphasis = []*regexp.Regexp{
+		regexp.MustCompile("`([^`]+)`"),
+		regexp.MustCompile(`\*\*(\S(?:.*?\S)?)\*\*`),
+		regexp.MustCompile(`\b__(\S(?:.*?\S)?)__\b`),
+		regexp.MustCompile(`~~(\S(?:.*?\S)?)~~`),
+		regexp.MustCompile(`\*(\S(?:.*?\S)?)\*`),
+		regexp.MustCompile(`\b_(\S(?:.*?\S)?)_\b`),
+	}
+
+	//markdownLinePrefix matches the syntax at the start of a line for headings, quotes,
+	//and list items, which is removed.
+	markdownLinePrefix = regexp.MustCompile(`^\s*(?:#{1,6}\s+|>\s?|[-*+]\s+|\d+[.)]\s+)+`)
+
+	//markdownRule matches a horizontal rule, which is removed.
+	markdownRule = regexp.MustCompile(`^\s*(?:[-*_]\s*){3,}$`)
+)
+
+//FuncExcerpt returns the first n words of markdown or HTML content as plain text. An
+//ellipsis is added if the content was shortened. Whitespace, including line breaks, is
+//collapsed to a single space.
+//
+//In templates, this is available as excerpt and is used as
+//<meta name="description" content="{{excerpt .Post.Body 30}}">.
+func FuncExcerpt(content string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+
+	words := strings.Fields(htmlText(stripMarkdown(content)))
+	if len(words) <= n {
+		return strings.Join(words, " ")
+	}
+
+	return strings.Join(words[:n], " ") + "…"
+}
+
+//stripMarkdown removes common markdown syntax from s.
+func stripMarkdown(s string) string {
+	lines := strings.Split(s, "\n")
+	kept := make([]string, 0, len(lines))
+
+	inFence := false
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		//Code blocks are removed since code does not read well in an excerpt.
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence || markdownRule.MatchString(line) {
+			continue
+		}
+
+		line = markdownLinePrefix.ReplaceAllString(line, "")
+		line = markdownImage.ReplaceAllString(line, "")
+		line = markdownLink.ReplaceAllString(line, "$1")
+		for _, re := range markdownEmphasis {
+			line = re.ReplaceAllString(line, "$1")
+		}
+		kept = append(kept, line)
+	}
+
+	return strings.Join(kept, "\n")
+}
+
+//htmlText returns the text in s with HTML tags removed and entities decoded.
+func htmlText(s string) string {
+	var b strings.Builder
+
+	z := html.NewTokenizer(strings.NewReader(s))
+	skip := ""
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return b.String()
+
+		case html.TextToken:
+			if skip == "" {
+				b.Write(z.Text())
+			}
+
+		case html.StartTagToken:
+			name, _ := z.TagName()
+			if tag := string(name); tag == "script" || tag == "style" {
+				skip = tag
+			}
+
+			//Tags are replaced with a space so words in separate elements, i.e.
+			//<p>one</p><p>two</p>, are not joined.
+			b.WriteByte(' ')
+
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			if string(name) == skip {
+				skip = ""
+			}
+			b.WriteByte(' ')
+
+		case html.SelfClosingTagToken:
+			b.WriteByte(' ')
+		}
+	}
+}
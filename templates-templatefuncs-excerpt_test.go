@@ -0,0 +1,34 @@
+package templates
+
+import (
+	"testing"
+)
+
+func TestFuncExcerpt(t *testing.T) {
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	md := "# Release notes\n\n" +
+		"This **release** adds [dark mode](/docs/dark) and _faster_ builds.\n\n" +
+		"![screenshot](/img/dark.png)\n\n" +
+		"```go\nfunc main() {}\n```\n\n" +
+		"- Fixes `snake_case` names\n" +
+		"> Thanks to all contributors\n"
+
+	tests := []struct {
+		content  string
+		words    int
+		expected string
+	}{
+		{md, 100, "Release notes This release adds dark mode and faster builds. Fixes snake_case names Thanks to all contributors"},
+		{md, 4, "Release notes This release…"},
+		{"<p>Hello <b>world</b> &amp; friends</p><p>Again</p><script>alert(1)</script>", 10, "Hello world & friends Again"},
+		{"<h1>Title</h1><p>one two three</p>", 2, "Title one…"},
+		{"plain text", 0, ""},
+	}
+	for _, tt := range tests {
+		if out := FuncExcerpt(tt.content, tt.words); out != tt.expected {
+			t.Fatal("Excerpt not returned as expected", out, tt.expected)
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,40 @@
+/*
+This file defines template level functions for building RSS and Atom feeds, and other
+XML output, from text templates. See RenderText() and ShowText(). These funcs are
+always available to templates, you do not need to add them to your FuncMap.
+*/
+
+package templates
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"time"
+)
+
+//FuncRFC3339 formats a time in the RFC 3339 format used by Atom feeds.
+func FuncRFC3339(t time.Time) string {
+	return t.Format(time.RFC3339)
+}
+
+//FuncRFC1123Z formats a time in the RFC 1123 format, with a numeric zone, used by RSS
+//feeds.
+func FuncRFC1123Z(t time.Time) string {
+	return t.Format(time.RFC1123Z)
+}
+
+//FuncCDATA wraps a string in a CDATA section so that it can be used in XML without
+//escaping, for example HTML content in a feed item's description. Any "]]>" in the
+//string is split across multiple CDATA sections since it would otherwise end the CDATA
+//section early.
+func FuncCDATA(s string) string {
+	return "<![CDATA[" + strings.ReplaceAll(s, "]]>", "]]]]><![CDATA[>") + "]]>"
+}
+
+//FuncXMLEscape escapes a string for use in XML text or attribute values.
+func FuncXMLEscape(s string) string {
+	var b bytes.Buffer
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,17 @@
+package templates
+
+import "testing"
+
+func TestFuncCDATA(t *testing.T) {
+	if s := FuncCDATA("a]]>b"); s != "<![CDATA[a]]]]><![CDATA[>b]]>" {
+		t.Fatal("CDATA not returned as expected", s)
+		return
+	}
+}
+
+func TestFuncXMLEscape(t *testing.T) {
+	if s := FuncXMLEscape(`<a href="x">&</a>`); s != "&lt;a href=&#34;x&#34;&gt;&amp;&lt;/a&gt;" {
+		t.Fatal("Escaped string not returned as expected", s)
+		return
+	}
+}
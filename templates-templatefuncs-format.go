@@ -0,0 +1,89 @@
+/*
+This file defines template level functions for building strings with named
+placeholders. This is easier to read than nested print or printf calls and allows the
+same string, i.e. a translated string, to be reused with the placeholders in any order.
+*/
+
+package templates
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+)
+
+//FuncDict returns a map built from pairs of keys and values. Keys must be strings. nil
+//is returned if an odd number of arguments is provided or a key is not a string. This
+//is used to provide more than one value to FuncFormat or to a {{template}}.
+//
+//In templates, this is available as dict and is used as
+//{{template "card" dict "Title" .Name "User" .User}}.
+func FuncDict(pairs ...interface{}) map[string]interface{} {
+	if len(pairs)%2 != 0 {
+		log.Println("templates.FuncDict", "odd number of arguments, each key must have a value")
+		return nil
+	}
+
+	m := make(map[string]interface{}, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			log.Println("templates.FuncDict", "key is not a string", pairs[i])
+			return nil
+		}
+
+		m[key] = pairs[i+1]
+	}
+
+	return m
+}
+
+//FuncFormat returns s with each {name} placeholder replaced by the value of name in
+//args. args can be a map with string keys, such as one built with FuncDict, or a struct
+//in which case placeholders are field names. A placeholder can be a path to a nested
+//field, i.e. {User.Name}. Placeholders that do not exist in args, or are nil, are left
+//as is. Use {{ and }} for literal braces.
+//
+//In templates, this is available as format and is used as
+//{{format "Hello {name}, you have {count} messages" (dict "name" .User.Name "count" .Unread)}}.
+func FuncFormat(s string, args interface{}) string {
+	v := reflect.ValueOf(args)
+
+	var b strings.Builder
+	b.Grow(len(s))
+
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+
+		//Literal braces.
+		if (ch == '{' || ch == '}') && i+1 < len(s) && s[i+1] == ch {
+			b.WriteByte(ch)
+			i++
+			continue
+		}
+		if ch != '{' {
+			b.WriteByte(ch)
+			continue
+		}
+
+		end := strings.IndexByte(s[i:], '}')
+		if end == -1 {
+			b.WriteString(s[i:])
+			break
+		}
+		placeholder := s[i : i+end+1]
+		name := strings.TrimSpace(placeholder[1 : len(placeholder)-1])
+
+		value, err := lookupField(v, name, false)
+		if err != nil || !isSet(value) {
+			b.WriteString(placeholder)
+		} else {
+			b.WriteString(fmt.Sprint(value.Interface()))
+		}
+
+		i += end
+	}
+
+	return b.String()
+}
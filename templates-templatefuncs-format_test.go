@@ -0,0 +1,75 @@
+package templates
+
+import (
+	"bytes"
+	"html/template"
+	"testing"
+)
+
+func TestFuncDict(t *testing.T) {
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	m := FuncDict("a", 1, "b", "two")
+	if len(m) != 2 || m["a"] != 1 || m["b"] != "two" {
+		t.Fatal("Map not built as expected", m)
+		return
+	}
+	if m := FuncDict("a"); m != nil {
+		t.Fatal("nil should be returned for an odd number of arguments", m)
+		return
+	}
+	if m := FuncDict(1, "a"); m != nil {
+		t.Fatal("nil should be returned for a key that is not a string", m)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestFuncFormat(t *testing.T) {
+	type user struct {
+		Name  string
+		Email *string
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	args := FuncDict("name", "Ann", "count", 3, "user", user{Name: "Bob"})
+	tests := map[string]string{
+		"Hello {name}":                  "Hello Ann",
+		"{name} has {count} messages":   "Ann has 3 messages",
+		"{count} messages for { name }": "3 messages for Ann",
+		"From {user.Name}":              "From Bob",
+		"{missing} and {user.Email}":    "{missing} and {user.Email}",
+		"Literal {{name}} braces }}":    "Literal {name} braces }",
+		"Unclosed {name":                "Unclosed {name",
+	}
+	for in, expected := range tests {
+		if out := FuncFormat(in, args); out != expected {
+			t.Fatal("Not formatted as expected", in, out, expected)
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Structs can be provided directly.
+	if out := FuncFormat("Hi {Name}", &user{Name: "Cy"}); out != "Hi Cy" {
+		t.Fatal("Not formatted as expected", out)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Used in a template, output is still escaped.
+	tmpl := template.Must(template.New("").Funcs(DefaultFuncMap()).Parse(
+		`<p>{{format "Hello {name}" (dict "name" .)}}</p>`,
+	))
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, "<b>Ann</b>"); err != nil {
+		t.Fatal(err)
+		return
+	}
+	if b.String() != "<p>Hello &lt;b&gt;Ann&lt;/b&gt;</p>" {
+		t.Fatal("Template output not as expected", b.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,45 @@
+/*
+This file defines template level functions that return HTML. These funcs handle escaping
+of any values used so that the returned HTML can be trusted.
+*/
+
+package templates
+
+import (
+	"html/template"
+	"strconv"
+	"strings"
+)
+
+//FuncObfuscateEmail returns an email address encoded as HTML entities. The email address
+//is displayed normally in a browser but is not readable by simple scrapers looking for
+//email addresses in a page's source.
+func FuncObfuscateEmail(email string) template.HTML {
+	return template.HTML(entityEncode(strings.TrimSpace(email)))
+}
+
+//FuncObfuscateMailto returns a mailto link to an email address with the link and the
+//displayed text encoded as HTML entities. If text is provided, it is displayed instead
+//of the email address.
+func FuncObfuscateMailto(email string, text ...string) template.HTML {
+	email = strings.TrimSpace(email)
+
+	display := entityEncode(email)
+	if t := strings.TrimSpace(strings.Join(text, " ")); t != "" {
+		display = template.HTMLEscapeString(t)
+	}
+
+	return template.HTML(`<a href="` + entityEncode("mailto:"+email) + `">` + display + `</a>`)
+}
+
+//entityEncode encodes each character in s as a decimal HTML entity.
+func entityEncode(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		b.WriteString("&#")
+		b.WriteString(strconv.Itoa(int(r)))
+		b.WriteString(";")
+	}
+
+	return b.String()
+}
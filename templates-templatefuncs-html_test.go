@@ -0,0 +1,39 @@
+package templates
+
+import (
+	"html"
+	"strings"
+	"testing"
+)
+
+func TestFuncObfuscateEmail(t *testing.T) {
+	email := "user@example.com"
+	obfuscated := string(FuncObfuscateEmail(email))
+	if strings.Contains(obfuscated, email) || strings.Contains(obfuscated, "@") {
+		t.Fatal("Email not obfuscated", obfuscated)
+		return
+	}
+	if html.UnescapeString(obfuscated) != email {
+		t.Fatal("Obfuscated email does not decode to email", obfuscated)
+		return
+	}
+}
+
+func TestFuncObfuscateMailto(t *testing.T) {
+	email := "user@example.com"
+	link := string(FuncObfuscateMailto(email))
+	if strings.Contains(link, email) || strings.Contains(link, "mailto") {
+		t.Fatal("Mailto not obfuscated", link)
+		return
+	}
+	if html.UnescapeString(link) != `<a href="mailto:user@example.com">user@example.com</a>` {
+		t.Fatal("Obfuscated link does not decode as expected", html.UnescapeString(link))
+		return
+	}
+
+	link = string(FuncObfuscateMailto(email, "<Contact Us>"))
+	if !strings.HasSuffix(link, ">&lt;Contact Us&gt;</a>") {
+		t.Fatal("Text not used as expected", link)
+		return
+	}
+}
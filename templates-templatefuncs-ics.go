@@ -0,0 +1,78 @@
+/*
+This file defines template level functions for building iCalendar (.ics) files from text
+templates. See RenderText() and ShowICS(). These funcs are always available to
+templates, you do not need to add them to your FuncMap.
+*/
+
+package templates
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+//icsMaxLineLength is the maximum length, in octets, of a line in an iCalendar file not
+//including the line break.
+const icsMaxLineLength = 75
+
+//FuncICSEscape escapes a string for use as a TEXT value in an iCalendar file, such as
+//an event's SUMMARY or DESCRIPTION, per RFC 5545.
+func FuncICSEscape(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	).Replace(s)
+}
+
+//FuncICSDateTime formats a time as a UTC DATE-TIME value for an iCalendar file, i.e.:
+//20211121T150405Z.
+func FuncICSDateTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+//FuncICSDate formats a time as a DATE value for an iCalendar file, i.e.: 20211121. This
+//is used for all-day events.
+func FuncICSDate(t time.Time) string {
+	return t.Format("20060102")
+}
+
+//FoldICS converts the line endings in an iCalendar file to CRLF and folds lines longer
+//than 75 octets, as required by RFC 5545. Lines are folded by inserting a CRLF followed
+//by a space. Multi-byte characters are not split across lines. Blank lines are removed
+//since they are not allowed; this allows templates to use {{range}} and {{if}} without
+//having to trim whitespace.
+func FoldICS(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+
+	var b strings.Builder
+	for _, line := range strings.Split(s, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		//The first line can use the maximum length, continuation lines begin with a
+		//space so they can use one less.
+		max := icsMaxLineLength
+		for len(line) > max {
+			cut := max
+			for cut > 0 && !utf8.RuneStart(line[cut]) {
+				cut--
+			}
+
+			b.WriteString(line[:cut])
+			b.WriteString("\r\n ")
+			line = line[cut:]
+			max = icsMaxLineLength - 1
+		}
+
+		b.WriteString(line)
+		b.WriteString("\r\n")
+	}
+
+	return b.String()
+}
@@ -0,0 +1,47 @@
+package templates
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFuncICSEscape(t *testing.T) {
+	if s := FuncICSEscape("a,b;c\\d\ne"); s != `a\,b\;c\\d\ne` {
+		t.Fatal("Value not escaped as expected", s)
+		return
+	}
+}
+
+func TestFuncICSDateTime(t *testing.T) {
+	loc := time.FixedZone("EST", -5*60*60)
+	if s := FuncICSDateTime(time.Date(2021, 11, 21, 10, 4, 5, 0, loc)); s != "20211121T150405Z" {
+		t.Fatal("Time not formatted as expected", s)
+		return
+	}
+}
+
+func TestFoldICS(t *testing.T) {
+	long := "DESCRIPTION:" + strings.Repeat("é", 50)
+	folded := FoldICS("BEGIN:VEVENT\n\n" + long + "\nEND:VEVENT\n")
+
+	lines := strings.Split(strings.TrimSuffix(folded, "\r\n"), "\r\n")
+	if len(lines) < 4 {
+		t.Fatal("Long line not folded", lines)
+		return
+	}
+	for i, l := range lines {
+		if len(l) > 75 {
+			t.Fatal("Line too long", len(l), l)
+			return
+		}
+		if i > 1 && i < len(lines)-1 && !strings.HasPrefix(l, " ") {
+			t.Fatal("Continuation line does not start with space", l)
+			return
+		}
+	}
+	if strings.Replace(strings.Join(lines[1:len(lines)-1], ""), " ", "", -1) != long {
+		t.Fatal("Unfolded line does not match original")
+		return
+	}
+}
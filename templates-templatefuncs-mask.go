@@ -0,0 +1,105 @@
+/*
+This file defines template level functions for partially hiding sensitive values, such
+as email addresses and phone numbers, shown on account or settings pages. Enough of the
+value is shown for a user to recognize it without exposing the full value to anyone
+looking at the screen.
+
+Masking is done when the template is rendered so the full value is still provided to
+the template. Do not provide values to templates that must never be sent to the user.
+*/
+
+package templates
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+//maskChar is the character used in place of hidden characters.
+const maskChar = '*'
+
+//FuncMaskExcept returns s with every character except the last lastN characters
+//replaced with an asterisk, i.e. "************4242" for a card number. s is masked fully
+//if lastN is less than 1.
+//
+//In templates, this is available as maskExcept and is used as {{maskExcept .Account 4}}.
+func FuncMaskExcept(s string, lastN int) string {
+	if lastN < 0 {
+		lastN = 0
+	}
+
+	n := utf8.RuneCountInString(s)
+	if lastN >= n {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+
+	i := 0
+	for _, r := range s {
+		if i < n-lastN {
+			b.WriteRune(maskChar)
+		} else {
+			b.WriteRune(r)
+		}
+		i++
+	}
+
+	return b.String()
+}
+
+//FuncMaskEmail returns an email address with all but the first character of the part
+//before the @ replaced with asterisks, i.e. "j*******@example.com". The domain is
+//shown so a user can tell which of their addresses is used. Values that are not an
+//email address are masked fully.
+//
+//In templates, this is available as maskEmail and is used as {{maskEmail .User.Email}}.
+func FuncMaskEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 1 {
+		return FuncMaskExcept(email, 0)
+	}
+
+	local, domain := email[:at], email[at:]
+	first, size := utf8.DecodeRuneInString(local)
+
+	return string(first) + FuncMaskExcept(local[size:], 0) + domain
+}
+
+//FuncMaskPhone returns a phone number with all but the last 4 digits replaced with
+//asterisks, i.e. "+* (***) ***-4567". Formatting characters, such as spaces, dashes,
+//and parenthesis, are kept so the masked value looks like the original.
+//
+//In templates, this is available as maskPhone and is used as {{maskPhone .User.Phone}}.
+func FuncMaskPhone(phone string) string {
+	const shown = 4
+
+	digits := 0
+	for _, r := range phone {
+		if unicode.IsDigit(r) {
+			digits++
+		}
+	}
+
+	var b strings.Builder
+	b.Grow(len(phone))
+
+	seen := 0
+	for _, r := range phone {
+		if !unicode.IsDigit(r) {
+			b.WriteRune(r)
+			continue
+		}
+
+		seen++
+		if seen <= digits-shown {
+			b.WriteRune(maskChar)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
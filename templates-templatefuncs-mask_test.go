@@ -0,0 +1,60 @@
+package templates
+
+import (
+	"testing"
+)
+
+func TestFuncMaskExcept(t *testing.T) {
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	tests := []struct {
+		s        string
+		lastN    int
+		expected string
+	}{
+		{"4242424242424242", 4, "************4242"},
+		{"secret", 0, "******"},
+		{"secret", -1, "******"},
+		{"abc", 5, "abc"},
+		{"héllo", 2, "***lo"},
+	}
+	for _, tt := range tests {
+		if out := FuncMaskExcept(tt.s, tt.lastN); out != tt.expected {
+			t.Fatal("Not masked as expected", tt.s, out, tt.expected)
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestFuncMaskEmail(t *testing.T) {
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	tests := map[string]string{
+		"john.doe@example.com": "j*******@example.com",
+		"a@example.com":        "a@example.com",
+		"not an email":         "************",
+		"@example.com":         "************",
+	}
+	for in, expected := range tests {
+		if out := FuncMaskEmail(in); out != expected {
+			t.Fatal("Not masked as expected", in, out, expected)
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestFuncMaskPhone(t *testing.T) {
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	tests := map[string]string{
+		"+1 (555) 123-4567": "+* (***) ***-4567",
+		"+15551234567":      "+*******4567",
+		"123":               "123",
+	}
+	for in, expected := range tests {
+		if out := FuncMaskPhone(in); out != expected {
+			t.Fatal("Not masked as expected", in, out, expected)
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,121 @@
+/*
+This file defines template level functions for emitting <meta>, <link>, and structured
+data tags used for search engines and social media sites. These funcs return correctly
+escaped HTML so that the same markup is used across all of your templates.
+
+To use these funcs, pass a Meta in the data injected into your template and call the
+funcs in the <head> of your template, for example:
+	<head>
+		{{metaTag "description" .InjectedData.Meta.Description}}
+		{{ogTags .InjectedData.Meta}}
+		{{canonicalURL .InjectedData.Meta.URL}}
+	</head>
+*/
+
+package templates
+
+import (
+	"encoding/json"
+	"html/template"
+	"log"
+	"net/url"
+	"strings"
+)
+
+//Meta is the information about a page used to build Open Graph <meta> tags.
+type Meta struct {
+	Title       string //og:title
+	Description string //og:description
+	URL         string //og:url, should be the canonical URL of the page
+	Image       string //og:image, should be an absolute URL
+	Type        string //og:type, defaults to "website"
+	SiteName    string //og:site_name
+}
+
+//FuncMetaTag returns a <meta> tag with the provided name and content. Nothing is
+//returned if content is blank.
+func FuncMetaTag(name, content string) template.HTML {
+	if strings.TrimSpace(content) == "" {
+		return ""
+	}
+
+	return template.HTML(`<meta name="` + template.HTMLEscapeString(name) + `" content="` + template.HTMLEscapeString(content) + `">`)
+}
+
+//FuncOGTags returns the Open Graph <meta> tags for a page. Tags are only returned for
+//fields that are not blank. URLs that are not http or https are ignored.
+func FuncOGTags(m Meta) template.HTML {
+	if m.Type == "" {
+		m.Type = "website"
+	}
+
+	properties := []struct {
+		property string
+		content  string
+	}{
+		{"og:title", m.Title},
+		{"og:description", m.Description},
+		{"og:type", m.Type},
+		{"og:url", safeURL(m.URL)},
+		{"og:image", safeURL(m.Image)},
+		{"og:site_name", m.SiteName},
+	}
+
+	var b strings.Builder
+	for _, p := range properties {
+		if strings.TrimSpace(p.content) == "" {
+			continue
+		}
+
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(`<meta property="` + p.property + `" content="` + template.HTMLEscapeString(p.content) + `">`)
+	}
+
+	return template.HTML(b.String())
+}
+
+//FuncCanonicalURL returns a <link rel="canonical"> tag for the provided URL. Nothing is
+//returned if the URL is blank or is not an http or https URL.
+func FuncCanonicalURL(u string) template.HTML {
+	u = safeURL(u)
+	if u == "" {
+		return ""
+	}
+
+	return template.HTML(`<link rel="canonical" href="` + template.HTMLEscapeString(u) + `">`)
+}
+
+//FuncJSONLD returns a <script type="application/ld+json"> block containing v marshalled
+//to JSON. This is used for emitting structured data, such as schema.org Product or
+//Article data, for search engines. The characters <, >, and & are escaped in the JSON
+//so that the data cannot close the <script> block early. Nothing is returned if v cannot
+//be marshalled.
+func FuncJSONLD(v interface{}) template.HTML {
+	b, err := json.Marshal(v)
+	if err != nil {
+		//we log out here so that user can identify issue since otherwise tracking down
+		//an error like this is very difficult.
+		log.Println("templates.FuncJSONLD", "could not marshal value", err)
+		return ""
+	}
+
+	return template.HTML(`<script type="application/ld+json">` + string(b) + `</script>`)
+}
+
+//safeURL returns the provided URL if it is an http or https URL, otherwise a blank
+//string is returned. This prevents "javascript:" or other unsafe URLs from being used.
+func safeURL(u string) string {
+	u = strings.TrimSpace(u)
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return ""
+	}
+
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return ""
+	}
+
+	return u
+}
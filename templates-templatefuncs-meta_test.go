@@ -0,0 +1,77 @@
+package templates
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFuncMetaTag(t *testing.T) {
+	tag := FuncMetaTag("description", `A "quoted" <description>`)
+	if string(tag) != `<meta name="description" content="A &#34;quoted&#34; &lt;description&gt;">` {
+		t.Fatal("Meta tag not returned as expected", tag)
+		return
+	}
+
+	if tag := FuncMetaTag("description", " "); tag != "" {
+		t.Fatal("Nothing should have been returned for blank content", tag)
+		return
+	}
+}
+
+func TestFuncOGTags(t *testing.T) {
+	m := Meta{
+		Title: "My Page",
+		URL:   "https://example.com/page",
+		Image: "javascript:alert(1)",
+	}
+	tags := string(FuncOGTags(m))
+	if !strings.Contains(tags, `<meta property="og:title" content="My Page">`) {
+		t.Fatal("Title tag missing", tags)
+		return
+	}
+	if !strings.Contains(tags, `<meta property="og:type" content="website">`) {
+		t.Fatal("Default type tag missing", tags)
+		return
+	}
+	if strings.Contains(tags, "og:image") {
+		t.Fatal("Unsafe image URL should have been ignored", tags)
+		return
+	}
+	if strings.Contains(tags, "og:description") {
+		t.Fatal("Blank description should have been ignored", tags)
+		return
+	}
+}
+
+func TestFuncCanonicalURL(t *testing.T) {
+	if tag := FuncCanonicalURL("https://example.com/?a=1&b=2"); string(tag) != `<link rel="canonical" href="https://example.com/?a=1&amp;b=2">` {
+		t.Fatal("Canonical tag not returned as expected", tag)
+		return
+	}
+
+	if tag := FuncCanonicalURL("javascript:alert(1)"); tag != "" {
+		t.Fatal("Unsafe URL should have been ignored", tag)
+		return
+	}
+}
+
+func TestFuncJSONLD(t *testing.T) {
+	v := map[string]string{
+		"@context": "https://schema.org",
+		"name":     "</script><script>alert(1)</script>",
+	}
+	script := string(FuncJSONLD(v))
+	if !strings.HasPrefix(script, `<script type="application/ld+json">`) || !strings.HasSuffix(script, "</script>") {
+		t.Fatal("Script block not returned as expected", script)
+		return
+	}
+	if strings.Count(script, "</script>") != 1 {
+		t.Fatal("Data was not escaped", script)
+		return
+	}
+
+	if script := FuncJSONLD(make(chan int)); script != "" {
+		t.Fatal("Nothing should have been returned for unmarshallable value", script)
+		return
+	}
+}
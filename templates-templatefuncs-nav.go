@@ -0,0 +1,232 @@
+/*
+This file defines template level functions and types for building navigation elements,
+such as breadcrumbs and menus, so that navigation is rendered the same across all
+templates.
+*/
+
+package templates
+
+import (
+	"html/template"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+//Breadcrumb is a single link in a breadcrumb trail.
+type Breadcrumb struct {
+	Label string
+	URL   string
+}
+
+//FuncBreadcrumbs returns a breadcrumb trail as an ordered list with schema.org
+//BreadcrumbList markup. The last breadcrumb is the current page and is not linked.
+//Provide the breadcrumbs in the data injected into your template, or build them with
+//BreadcrumbsFromPath(), and use {{breadcrumbs .InjectedData.Breadcrumbs}}.
+func FuncBreadcrumbs(crumbs []Breadcrumb) template.HTML {
+	if len(crumbs) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(`<nav aria-label="breadcrumb"><ol class="breadcrumb" itemscope itemtype="https://schema.org/BreadcrumbList">`)
+	for i, c := range crumbs {
+		label := template.HTMLEscapeString(c.Label)
+		position := strconv.Itoa(i + 1)
+		last := i == len(crumbs)-1
+
+		if last {
+			b.WriteString(`<li class="breadcrumb-item active" aria-current="page" itemprop="itemListElement" itemscope itemtype="https://schema.org/ListItem">`)
+		} else {
+			b.WriteString(`<li class="breadcrumb-item" itemprop="itemListElement" itemscope itemtype="https://schema.org/ListItem">`)
+		}
+
+		href := safeHref(c.URL)
+		if !last && href != "" {
+			b.WriteString(`<a itemprop="item" href="` + template.HTMLEscapeString(href) + `"><span itemprop="name">` + label + `</span></a>`)
+		} else {
+			b.WriteString(`<span itemprop="name">` + label + `</span>`)
+		}
+
+		b.WriteString(`<meta itemprop="position" content="` + position + `"></li>`)
+	}
+	b.WriteString(`</ol></nav>`)
+
+	return template.HTML(b.String())
+}
+
+//BreadcrumbsFromPath builds breadcrumbs from a URL path, with one breadcrumb per path
+//segment plus a breadcrumb for the root labeled by home. Labels are built from each
+//segment by replacing dashes and underscores with spaces and capitalizing each word,
+//i.e.: "/docs/getting-started" results in "Home", "Docs", "Getting Started".
+func BreadcrumbsFromPath(p, home string) (crumbs []Breadcrumb) {
+	crumbs = append(crumbs, Breadcrumb{Label: home, URL: "/"})
+
+	current := ""
+	for _, segment := range strings.Split(path.Clean("/"+p), "/") {
+		if segment == "" {
+			continue
+		}
+		current += "/" + segment
+
+		label := segment
+		if unescaped, err := url.PathUnescape(segment); err == nil {
+			label = unescaped
+		}
+		label = strings.NewReplacer("-", " ", "_", " ").Replace(label)
+
+		words := strings.Fields(label)
+		for i, w := range words {
+			r, size := utf8.DecodeRuneInString(w)
+			words[i] = string(unicode.ToUpper(r)) + w[size:]
+		}
+
+		crumbs = append(crumbs, Breadcrumb{Label: strings.Join(words, " "), URL: current})
+	}
+
+	return
+}
+
+//safeHref returns the provided URL if it is a relative URL or an http, https, or mailto
+//URL, otherwise a blank string is returned. This prevents "javascript:" or other unsafe
+//URLs from being used in links.
+func safeHref(u string) string {
+	u = strings.TrimSpace(u)
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return ""
+	}
+
+	switch parsed.Scheme {
+	case "", "http", "https", "mailto":
+		return u
+	default:
+		return ""
+	}
+}
+
+//FuncIsActive returns true if href is the current page, i.e.: currentPath. This is used
+//for highlighting the current page in navigation menus. Trailing slashes, query strings,
+//and fragments are ignored.
+//
+//In templates, this is available as the request-scoped func isActive, using the path of
+//the request, and is used as {{if isActive "/docs/faq"}}active{{end}}.
+func FuncIsActive(currentPath, href string) bool {
+	current, target := cleanNavPath(currentPath), cleanNavPath(href)
+	if current == "" || target == "" {
+		return false
+	}
+
+	return current == target
+}
+
+//FuncIsActivePrefix returns true if href is the current page or a parent of the current
+//page, i.e.: currentPath. This is used for highlighting the current section in navigation
+//menus. Matching is done by path segment so "/doc" does not match "/docs".
+//
+//In templates, this is available as the request-scoped func isActivePrefix, using the
+//path of the request, and is used as {{if isActivePrefix "/docs"}}active{{end}}.
+func FuncIsActivePrefix(currentPath, href string) bool {
+	current, target := cleanNavPath(currentPath), cleanNavPath(href)
+	if current == "" || target == "" {
+		return false
+	}
+
+	if target == "/" || current == target {
+		return true
+	}
+
+	return strings.HasPrefix(current, target+"/")
+}
+
+//cleanNavPath returns the cleaned path portion of a URL for comparing paths.
+func cleanNavPath(u string) string {
+	u = strings.TrimSpace(u)
+	if u == "" {
+		return ""
+	}
+
+	if parsed, err := url.Parse(u); err == nil {
+		u = parsed.Path
+	}
+
+	return path.Clean("/" + u)
+}
+
+//Nav is a link in a navigation menu. Links can have child links to build nested menus,
+//i.e. dropdowns or a sidebar with sections. Menus are declared as data, typically once
+//for your app, and rendered with FuncNav.
+type Nav struct {
+	Label    string
+	Href     string
+	Children []Nav
+}
+
+//FuncNav returns a navigation menu as nested unordered lists. The link for currentPath
+//is marked with aria-current="page" and its list item, plus the list items of each of
+//its parents, are given the "active" class so that the current section can be styled.
+//Links without an Href, i.e. section headings, are shown as text.
+//
+//In templates, this is available as the request-scoped func nav, using the path of the
+//request, and is used as {{nav .InjectedData.Menu}}.
+func FuncNav(items []Nav, currentPath string) template.HTML {
+	if len(items) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(`<nav>`)
+	writeNavList(&b, items, currentPath)
+	b.WriteString(`</nav>`)
+
+	return template.HTML(b.String())
+}
+
+//writeNavList writes a list of links, and each link's children, as an unordered list.
+func writeNavList(b *strings.Builder, items []Nav, currentPath string) {
+	b.WriteString(`<ul>`)
+	for _, item := range items {
+		current := item.Href != "" && FuncIsActive(currentPath, item.Href)
+
+		if current || navContains(item.Children, currentPath) {
+			b.WriteString(`<li class="active">`)
+		} else {
+			b.WriteString(`<li>`)
+		}
+
+		label := template.HTMLEscapeString(item.Label)
+		href := safeHref(item.Href)
+		switch {
+		case href == "":
+			b.WriteString(`<span>` + label + `</span>`)
+		case current:
+			b.WriteString(`<a href="` + template.HTMLEscapeString(href) + `" aria-current="page">` + label + `</a>`)
+		default:
+			b.WriteString(`<a href="` + template.HTMLEscapeString(href) + `">` + label + `</a>`)
+		}
+
+		if len(item.Children) > 0 {
+			writeNavList(b, item.Children, currentPath)
+		}
+		b.WriteString(`</li>`)
+	}
+	b.WriteString(`</ul>`)
+}
+
+//navContains returns true if a link in items, or any of their children, is for
+//currentPath.
+func navContains(items []Nav, currentPath string) bool {
+	for _, item := range items {
+		if item.Href != "" && FuncIsActive(currentPath, item.Href) {
+			return true
+		}
+		if navContains(item.Children, currentPath) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,104 @@
+package templates
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFuncBreadcrumbs(t *testing.T) {
+	crumbs := []Breadcrumb{
+		{Label: "Home", URL: "/"},
+		{Label: "<Docs>", URL: "javascript:alert(1)"},
+		{Label: "FAQ", URL: "/docs/faq"},
+	}
+	html := string(FuncBreadcrumbs(crumbs))
+	if !strings.Contains(html, `<a itemprop="item" href="/"><span itemprop="name">Home</span></a>`) {
+		t.Fatal("Linked breadcrumb missing", html)
+		return
+	}
+	if strings.Contains(html, "javascript") || !strings.Contains(html, "&lt;Docs&gt;") {
+		t.Fatal("Breadcrumb not escaped", html)
+		return
+	}
+	if strings.Contains(html, `href="/docs/faq"`) || !strings.Contains(html, `aria-current="page"`) {
+		t.Fatal("Last breadcrumb should not be linked", html)
+		return
+	}
+
+	if html := FuncBreadcrumbs(nil); html != "" {
+		t.Fatal("Nothing should have been returned", html)
+		return
+	}
+}
+
+func TestBreadcrumbsFromPath(t *testing.T) {
+	crumbs := BreadcrumbsFromPath("/docs/getting-started/", "Home")
+	if len(crumbs) != 3 {
+		t.Fatal("Wrong number of breadcrumbs", crumbs)
+		return
+	}
+	if crumbs[2].Label != "Getting Started" || crumbs[2].URL != "/docs/getting-started" {
+		t.Fatal("Breadcrumb not built as expected", crumbs[2])
+		return
+	}
+
+	crumbs = BreadcrumbsFromPath("/%C3%A9lan", "Home")
+	if crumbs[1].Label != "Élan" {
+		t.Fatal("Non-ASCII breadcrumb not capitalized as expected", crumbs[1])
+		return
+	}
+}
+
+func TestFuncIsActive(t *testing.T) {
+	if !FuncIsActive("/docs/faq/", "/docs/faq?x=1") {
+		t.Fatal("Path should have been active")
+		return
+	}
+	if FuncIsActive("/docs/faq", "/docs") {
+		t.Fatal("Path should not have been active")
+		return
+	}
+	if FuncIsActive("", "/docs") {
+		t.Fatal("Path should not have been active without current path")
+		return
+	}
+}
+
+func TestFuncIsActivePrefix(t *testing.T) {
+	if !FuncIsActivePrefix("/docs/faq", "/docs") {
+		t.Fatal("Path should have been active")
+		return
+	}
+	if FuncIsActivePrefix("/docs/faq", "/doc") {
+		t.Fatal("Partial segment should not have been active")
+		return
+	}
+}
+
+func TestFuncNav(t *testing.T) {
+	menu := []Nav{
+		{Label: "Docs", Children: []Nav{
+			{Label: "<Intro>", Href: "/docs/intro"},
+			{Label: "Bad", Href: "javascript:alert(1)"},
+		}},
+		{Label: "Blog", Href: "/blog"},
+	}
+
+	html := string(FuncNav(menu, "/docs/intro/"))
+	expected := `<nav><ul>` +
+		`<li class="active"><span>Docs</span><ul>` +
+		`<li class="active"><a href="/docs/intro" aria-current="page">&lt;Intro&gt;</a></li>` +
+		`<li><span>Bad</span></li>` +
+		`</ul></li>` +
+		`<li><a href="/blog">Blog</a></li>` +
+		`</ul></nav>`
+	if html != expected {
+		t.Fatal("Nav not rendered as expected", html)
+		return
+	}
+
+	if html := FuncNav(nil, "/"); html != "" {
+		t.Fatal("Nothing should have been returned", html)
+		return
+	}
+}
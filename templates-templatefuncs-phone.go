@@ -0,0 +1,183 @@
+/*
+This file defines a template level function for displaying phone numbers stored in the
+canonical E.164 format, i.e. +15551234567, in a human readable format.
+
+Numbers are shown in the national format when the number is from the region the page is
+shown for, i.e. (555) 123-4567 for a US number shown to a US user, and in the
+international format otherwise, i.e. +1 555-123-4567. Only the common formats for a
+limited number of countries are known; this is not a replacement for a full phone
+number library such as libphonenumber. Numbers from other countries, or with an
+unexpected length, are shown as provided.
+*/
+
+package templates
+
+import (
+	"strings"
+)
+
+//phoneFormat is how phone numbers for a country calling code are formatted.
+type phoneFormat struct {
+	//regions is the list of ISO 3166-1 alpha-2 region codes that use the country calling
+	//code.
+	regions []string
+
+	//trunk is the prefix added to numbers dialed within the country, i.e. "0".
+	trunk string
+
+	//groups is the size of each group of digits, keyed by the length of the number
+	//without the country calling code.
+	groups map[int][]int
+
+	//groupsFor returns the size of each group of digits for a number without the
+	//country calling code. This is used instead of groups for countries where the
+	//grouping depends on the number's prefix. ok is false if the number's length is
+	//not known.
+	groupsFor func(subscriber string) (sizes []int, ok bool)
+
+	//national formats the groups of digits in the national format. If nil, the trunk
+	//prefix and groups separated by spaces are used.
+	national func(groups []string) string
+
+	//international formats the groups of digits in the international format, without
+	//the country calling code. If nil, groups separated by spaces are used.
+	international func(groups []string) string
+}
+
+//phoneFormats is the formats for each known country calling code.
+var phoneFormats = map[string]phoneFormat{
+	"1": {
+		regions: []string{"US", "CA", "PR", "GU", "VI", "AS", "MP"},
+		groups:  map[int][]int{10: {3, 3, 4}},
+		national: func(g []string) string {
+			return "(" + g[0] + ") " + g[1] + "-" + g[2]
+		},
+		international: func(g []string) string {
+			return strings.Join(g, "-")
+		},
+	},
+	"44": {
+		regions:   []string{"GB", "GG", "IM", "JE"},
+		trunk:     "0",
+		groupsFor: ukPhoneGroups,
+	},
+	"33": {
+		regions: []string{"FR"},
+		trunk:   "0",
+		groups:  map[int][]int{9: {1, 2, 2, 2, 2}},
+	},
+	"61": {
+		regions: []string{"AU"},
+		trunk:   "0",
+		groups:  map[int][]int{9: {1, 4, 4}},
+	},
+	"64": {
+		regions: []string{"NZ"},
+		trunk:   "0",
+		groups:  map[int][]int{8: {1, 3, 4}, 9: {2, 3, 4}},
+	},
+	"91": {
+		regions: []string{"IN"},
+		trunk:   "0",
+		groups:  map[int][]int{10: {5, 5}},
+	},
+}
+
+//FuncFormatPhone returns a phone number provided in E.164 format, i.e. +15551234567, in
+//the national format if the number is from region, i.e. "(555) 123-4567" for "US", or
+//the international format otherwise, i.e. "+1 555-123-4567". region is an ISO 3166-1
+//alpha-2 code and is case insensitive; provide a blank region to always use the
+//international format. The number is returned as provided if it is not in E.164 format
+//or its format is not known.
+//
+//In templates, this is available as formatPhone and is used as
+//{{formatPhone .User.Phone "US"}}.
+func FuncFormatPhone(number, region string) string {
+	e164 := strings.TrimSpace(number)
+	if len(e164) < 2 || e164[0] != '+' || !isDigits(e164[1:]) {
+		return number
+	}
+	digits := e164[1:]
+
+	//Country calling codes are 1 to 3 digits and no code is the prefix of another.
+	for i := 1; i <= 3 && i < len(digits); i++ {
+		code, subscriber := digits[:i], digits[i:]
+
+		f, ok := phoneFormats[code]
+		if !ok {
+			continue
+		}
+
+		sizes, ok := f.groups[len(subscriber)]
+		if f.groupsFor != nil {
+			sizes, ok = f.groupsFor(subscriber)
+		}
+		if !ok {
+			return number
+		}
+
+		groups := make([]string, 0, len(sizes))
+		for _, size := range sizes {
+			groups = append(groups, subscriber[:size])
+			subscriber = subscriber[size:]
+		}
+
+		if f.inRegion(region) {
+			if f.national != nil {
+				return f.national(groups)
+			}
+			return f.trunk + strings.Join(groups, " ")
+		}
+
+		if f.international != nil {
+			return "+" + code + " " + f.international(groups)
+		}
+		return "+" + code + " " + strings.Join(groups, " ")
+	}
+
+	return number
+}
+
+//ukPhoneGroups returns the grouping of a UK number without the country calling code.
+//Only 10 digit numbers are known. UK area codes vary in length, so only prefixes with
+//a single grouping are grouped, i.e. 020 7946 0958, 07700 900123, and 0300 123 4567.
+//Other numbers, such as those with 01 area codes, are not grouped.
+func ukPhoneGroups(subscriber string) (sizes []int, ok bool) {
+	if len(subscriber) != 10 {
+		return nil, false
+	}
+
+	switch subscriber[0] {
+	case '2':
+		return []int{2, 4, 4}, true
+	case '7':
+		return []int{4, 6}, true
+	case '3', '8', '9':
+		return []int{3, 3, 4}, true
+	default:
+		return []int{10}, true
+	}
+}
+
+//inRegion returns if region uses the country calling code of f.
+func (f phoneFormat) inRegion(region string) bool {
+	region = strings.TrimSpace(region)
+	for _, r := range f.regions {
+		if strings.EqualFold(r, region) {
+			return true
+		}
+	}
+
+	return false
+}
+
+//isDigits returns if s is only made up of the digits 0 through 9.
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return s != ""
+}
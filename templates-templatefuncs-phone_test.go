@@ -0,0 +1,43 @@
+package templates
+
+import (
+	"testing"
+)
+
+func TestFuncFormatPhone(t *testing.T) {
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	tests := []struct {
+		number, region, expected string
+	}{
+		{"+15551234567", "US", "(555) 123-4567"},
+		{"+15551234567", "ca", "(555) 123-4567"},
+		{"+15551234567", "GB", "+1 555-123-4567"},
+		{"+15551234567", "", "+1 555-123-4567"},
+		{"+442079460958", "GB", "020 7946 0958"},
+		{"+442079460958", "US", "+44 20 7946 0958"},
+		{"+447700900123", "GB", "07700 900123"},
+		{"+443001234567", "GB", "0300 123 4567"},
+		{"+441632960123", "GB", "01632960123"},
+		{"+33123456789", "FR", "01 23 45 67 89"},
+		{"+33123456789", "DE", "+33 1 23 45 67 89"},
+		{"+61212345678", "AU", "02 1234 5678"},
+		{"+919876543210", "IN", "098765 43210"},
+	}
+	for _, tt := range tests {
+		if out := FuncFormatPhone(tt.number, tt.region); out != tt.expected {
+			t.Fatal("Phone number not formatted as expected", tt.number, tt.region, out, tt.expected)
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Numbers that cannot be formatted are returned as is.
+	for _, number := range []string{"555-123-4567", "+1555", "+4930123456", "+", "+1abc"} {
+		if out := FuncFormatPhone(number, "US"); out != number {
+			t.Fatal("Phone number should be returned as is", number, out)
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
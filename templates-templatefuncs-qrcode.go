@@ -0,0 +1,650 @@
+/*
+This file defines template level functions for building QR codes. QR codes are rendered
+as PNG images embedded in data URIs so that pages such as 2FA enrollment, payment, and
+ticket pages can be rendered entirely server side without a separate handler to serve
+the image.
+
+QR codes are encoded in byte mode using the medium (M) error correction level which
+can recover from roughly 15% of the code being damaged or obscured. The smallest
+version, or size, of QR code that fits the content is used.
+
+The encoding below is based upon the QR Code generator library by Project Nayuki,
+https://www.nayuki.io/page/qr-code-generator-library, which is provided under the
+following license:
+
+	Copyright (c) Project Nayuki. (MIT License)
+
+	Permission is hereby granted, free of charge, to any person obtaining a copy of
+	this software and associated documentation files (the "Software"), to deal in
+	the Software without restriction, including without limitation the rights to
+	use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+	the Software, and to permit persons to whom the Software is furnished to do so,
+	subject to the following conditions:
+	- The above copyright notice and this permission notice shall be included in
+	  all copies or substantial portions of the Software.
+	- The Software is provided "as is", without warranty of any kind, express or
+	  implied, including but not limited to the warranties of merchantability,
+	  fitness for a particular purpose and noninfringement. In no event shall the
+	  authors or copyright holders be liable for any claim, damages or other
+	  liability, whether in an action of contract, tort or otherwise, arising from,
+	  out of or in connection with the Software or the use or other dealings in the
+	  Software.
+*/
+
+package templates
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"html/template"
+	"image"
+	"image/color"
+	"image/png"
+)
+
+//ErrQRCodeContentTooLong is returned when content is too long to fit in a QR code.
+var ErrQRCodeContentTooLong = errors.New("templates: content too long for qr code")
+
+const (
+	//qrQuietZone is the number of light modules around a QR code. This is required by
+	//the QR code spec so that readers can find the code.
+	qrQuietZone = 4
+
+	//qrMinVersion and qrMaxVersion are the smallest and largest versions of QR code.
+	qrMinVersion = 1
+	qrMaxVersion = 40
+)
+
+//qrECCCodewordsPerBlock is the number of error correction codewords per block for each
+//version of QR code at the M error correction level. Index 0 is unused.
+var qrECCCodewordsPerBlock = [qrMaxVersion + 1]int{-1,
+	10, 16, 26, 18, 24, 16, 18, 22, 22, 26,
+	30, 22, 22, 24, 24, 28, 28, 26, 26, 26,
+	26, 28, 28, 28, 28, 28, 28, 28, 28, 28,
+	28, 28, 28, 28, 28, 28, 28, 28, 28, 28,
+}
+
+//qrNumBlocks is the number of error correction blocks for each version of QR code at
+//the M error correction level. Index 0 is unused.
+var qrNumBlocks = [qrMaxVersion + 1]int{-1,
+	1, 1, 1, 2, 2, 4, 4, 4, 5, 5,
+	5, 8, 9, 9, 10, 10, 11, 13, 14, 16,
+	17, 17, 18, 20, 21, 23, 25, 26, 28, 29,
+	31, 33, 35, 37, 38, 40, 43, 45, 47, 49,
+}
+
+//FuncQRCode returns a QR code for content as a data URI of a PNG image. size is the
+//width and height of the image in pixels. The QR code is scaled to the largest whole
+//number of pixels per module that fits within size and centered. If size is too small
+//to fit the QR code at one pixel per module, the image will be larger than size.
+//
+//In templates, this is available as qrCode and is used as
+//<img src="{{qrCode "otpauth://totp/..." 200}}" width="200" height="200">.
+func FuncQRCode(content string, size int) (template.URL, error) {
+	modules, err := qrEncode([]byte(content), -1)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	err = png.Encode(&buf, qrImage(modules, size))
+	if err != nil {
+		return "", err
+	}
+
+	return template.URL("data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())), nil
+}
+
+//qrImage draws a QR code's modules as an image with a quiet zone around the code.
+func qrImage(modules [][]bool, size int) image.Image {
+	n := len(modules) + 2*qrQuietZone
+	scale := size / n
+	if scale < 1 {
+		scale = 1
+	}
+	if size < n*scale {
+		size = n * scale
+	}
+	offset := (size - len(modules)*scale) / 2
+
+	img := image.NewPaletted(image.Rect(0, 0, size, size), color.Palette{color.White, color.Black})
+	for y, row := range modules {
+		for x, dark := range row {
+			if !dark {
+				continue
+			}
+
+			for dy := 0; dy < scale; dy++ {
+				for dx := 0; dx < scale; dx++ {
+					img.SetColorIndex(offset+x*scale+dx, offset+y*scale+dy, 1)
+				}
+			}
+		}
+	}
+
+	return img
+}
+
+//qrCode is a QR code being built. modules are true for dark modules. isFunction marks
+//modules used by function patterns, such as the finder patterns, that data is not
+//drawn on and masks are not applied to.
+type qrCode struct {
+	version    int
+	size       int
+	modules    [][]bool
+	isFunction [][]bool
+}
+
+//qrEncode encodes data as a QR code and returns the modules, true for dark modules.
+//mask is the mask pattern to use, 0 through 7, or -1 to pick the mask that results in
+//the QR code that is easiest to read.
+func qrEncode(data []byte, mask int) ([][]bool, error) {
+	//Find the smallest version that fits the data.
+	version := 0
+	for v := qrMinVersion; v <= qrMaxVersion; v++ {
+		if 4+qrCharCountBits(v)+8*len(data) <= qrNumDataCodewords(v)*8 {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return nil, ErrQRCodeContentTooLong
+	}
+
+	//Build the bit stream: byte mode indicator, character count, data, terminator, and
+	//padding.
+	capacity := qrNumDataCodewords(version) * 8
+	var bits qrBits
+	bits.append(0x4, 4)
+	bits.append(len(data), qrCharCountBits(version))
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+	terminator := capacity - len(bits)
+	if terminator > 4 {
+		terminator = 4
+	}
+	bits.append(0, terminator)
+	bits.append(0, (8-len(bits)%8)%8)
+	for pad := 0xEC; len(bits) < capacity; pad ^= 0xEC ^ 0x11 {
+		bits.append(pad, 8)
+	}
+
+	codewords := make([]byte, len(bits)/8)
+	for i, bit := range bits {
+		if bit {
+			codewords[i>>3] |= 1 << (7 - uint(i&7))
+		}
+	}
+
+	q := newQRCode(version)
+	q.drawFunctionPatterns()
+	q.drawCodewords(qrAddECCAndInterleave(codewords, version))
+
+	//Pick the mask resulting in the lowest penalty, the QR code that is easiest to
+	//read, if no mask was provided.
+	if mask == -1 {
+		minPenalty := -1
+		for m := 0; m < 8; m++ {
+			q.applyMask(m)
+			q.drawFormatBits(m)
+			penalty := q.penalty()
+			if minPenalty == -1 || penalty < minPenalty {
+				mask = m
+				minPenalty = penalty
+			}
+
+			//Masks are applied using xor so applying the mask again removes it.
+			q.applyMask(m)
+		}
+	}
+
+	q.applyMask(mask)
+	q.drawFormatBits(mask)
+
+	return q.modules, nil
+}
+
+//qrBits is a sequence of bits.
+type qrBits []bool
+
+//append appends the lowest n bits of v, most significant bit first.
+func (b *qrBits) append(v, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*b = append(*b, (v>>uint(i))&1 != 0)
+	}
+}
+
+//qrCharCountBits returns the number of bits used for the character count in byte mode
+//for a version.
+func qrCharCountBits(version int) int {
+	if version <= 9 {
+		return 8
+	}
+
+	return 16
+}
+
+//qrNumRawDataModules returns the number of modules that can store data, including
+//error correction, for a version. This is the number of modules not used by function
+//patterns, format bits, or version bits.
+func qrNumRawDataModules(version int) int {
+	result := (16*version+128)*version + 64
+	if version >= 2 {
+		numAlign := version/7 + 2
+		result -= (25*numAlign-10)*numAlign - 55
+		if version >= 7 {
+			result -= 36
+		}
+	}
+
+	return result
+}
+
+//qrNumDataCodewords returns the number of 8-bit codewords that can store data, not
+//including error correction, for a version.
+func qrNumDataCodewords(version int) int {
+	return qrNumRawDataModules(version)/8 - qrECCCodewordsPerBlock[version]*qrNumBlocks[version]
+}
+
+//qrAddECCAndInterleave splits data into blocks, adds error correction codewords to each
+//block, and interleaves the blocks as required by the QR code spec.
+func qrAddECCAndInterleave(data []byte, version int) []byte {
+	numBlocks := qrNumBlocks[version]
+	blockECCLen := qrECCCodewordsPerBlock[version]
+	rawCodewords := qrNumRawDataModules(version) / 8
+	numShortBlocks := numBlocks - rawCodewords%numBlocks
+	shortBlockLen := rawCodewords / numBlocks
+
+	divisor := qrReedSolomonDivisor(blockECCLen)
+	blocks := make([][]byte, numBlocks)
+	k := 0
+	for i := range blocks {
+		n := shortBlockLen - blockECCLen
+		if i >= numShortBlocks {
+			n++
+		}
+
+		block := make([]byte, 0, shortBlockLen+1)
+		block = append(block, data[k:k+n]...)
+		k += n
+		ecc := qrReedSolomonRemainder(block, divisor)
+
+		//Short blocks are padded so that all blocks are the same length. The padding is
+		//skipped when interleaving.
+		if i < numShortBlocks {
+			block = append(block, 0)
+		}
+		blocks[i] = append(block, ecc...)
+	}
+
+	result := make([]byte, 0, rawCodewords)
+	for i := range blocks[0] {
+		for j, block := range blocks {
+			if i != shortBlockLen-blockECCLen || j >= numShortBlocks {
+				result = append(result, block[i])
+			}
+		}
+	}
+
+	return result
+}
+
+//qrReedSolomonDivisor returns the Reed-Solomon generator polynomial of a degree. The
+//coefficients are stored highest to lowest power, excluding the leading term which is
+//always 1.
+func qrReedSolomonDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := range result {
+			result[j] = qrMultiply(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = qrMultiply(root, 0x02)
+	}
+
+	return result
+}
+
+//qrReedSolomonRemainder returns the Reed-Solomon error correction codewords for data.
+func qrReedSolomonRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i, d := range divisor {
+			result[i] ^= qrMultiply(d, factor)
+		}
+	}
+
+	return result
+}
+
+//qrMultiply multiplies two values in the Galois field GF(2^8) used by QR codes.
+func qrMultiply(x, y byte) byte {
+	z := 0
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * 0x11D)
+		z ^= int((y>>uint(i))&1) * int(x)
+	}
+
+	return byte(z)
+}
+
+//newQRCode returns a blank QR code for a version.
+func newQRCode(version int) *qrCode {
+	size := version*4 + 17
+	q := &qrCode{
+		version:    version,
+		size:       size,
+		modules:    make([][]bool, size),
+		isFunction: make([][]bool, size),
+	}
+	for i := 0; i < size; i++ {
+		q.modules[i] = make([]bool, size)
+		q.isFunction[i] = make([]bool, size)
+	}
+
+	return q
+}
+
+//setFunction sets a module that is part of a function pattern.
+func (q *qrCode) setFunction(x, y int, dark bool) {
+	q.modules[y][x] = dark
+	q.isFunction[y][x] = true
+}
+
+//drawFunctionPatterns draws the timing, finder, and alignment patterns and the version
+//bits. The format bits are reserved, since the mask is not known yet.
+func (q *qrCode) drawFunctionPatterns() {
+	for i := 0; i < q.size; i++ {
+		q.setFunction(6, i, i%2 == 0)
+		q.setFunction(i, 6, i%2 == 0)
+	}
+
+	q.drawFinderPattern(3, 3)
+	q.drawFinderPattern(q.size-4, 3)
+	q.drawFinderPattern(3, q.size-4)
+
+	positions := q.alignmentPatternPositions()
+	last := len(positions) - 1
+	for i, x := range positions {
+		for j, y := range positions {
+			//Skip the positions that overlap the finder patterns.
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+
+			q.drawAlignmentPattern(x, y)
+		}
+	}
+
+	q.drawFormatBits(0)
+	q.drawVersionBits()
+}
+
+//drawFinderPattern draws a finder pattern, and the separator around it, centered at x, y.
+func (q *qrCode) drawFinderPattern(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || xx >= q.size || yy < 0 || yy >= q.size {
+				continue
+			}
+
+			dist := qrMax(qrAbs(dx), qrAbs(dy))
+			q.setFunction(xx, yy, dist != 2 && dist != 4)
+		}
+	}
+}
+
+//drawAlignmentPattern draws an alignment pattern centered at x, y.
+func (q *qrCode) drawAlignmentPattern(x, y int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			q.setFunction(x+dx, y+dy, qrMax(qrAbs(dx), qrAbs(dy)) != 1)
+		}
+	}
+}
+
+//alignmentPatternPositions returns the positions of the centers of the alignment
+//patterns, used for both rows and columns.
+func (q *qrCode) alignmentPatternPositions() []int {
+	if q.version == 1 {
+		return nil
+	}
+
+	numAlign := q.version/7 + 2
+	step := (q.version*8 + numAlign*3 + 5) / (numAlign*4 - 4) * 2
+	result := make([]int, numAlign)
+	result[0] = 6
+	for i, pos := numAlign-1, q.size-7; i >= 1; i, pos = i-1, pos-step {
+		result[i] = pos
+	}
+
+	return result
+}
+
+//drawFormatBits draws both copies of the format bits, the error correction level and
+//mask, and the dark module.
+func (q *qrCode) drawFormatBits(mask int) {
+	//The M error correction level is encoded as 0.
+	data := mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+	bits := (data<<10 | rem) ^ 0x5412
+
+	//First copy, around the top left finder pattern.
+	for i := 0; i <= 5; i++ {
+		q.setFunction(8, i, qrBit(bits, i))
+	}
+	q.setFunction(8, 7, qrBit(bits, 6))
+	q.setFunction(8, 8, qrBit(bits, 7))
+	q.setFunction(7, 8, qrBit(bits, 8))
+	for i := 9; i < 15; i++ {
+		q.setFunction(14-i, 8, qrBit(bits, i))
+	}
+
+	//Second copy, split between the top right and bottom left finder patterns.
+	for i := 0; i < 8; i++ {
+		q.setFunction(q.size-1-i, 8, qrBit(bits, i))
+	}
+	for i := 8; i < 15; i++ {
+		q.setFunction(8, q.size-15+i, qrBit(bits, i))
+	}
+	q.setFunction(8, q.size-8, true)
+}
+
+//drawVersionBits draws both copies of the version bits. Version bits are only used for
+//version 7 and larger.
+func (q *qrCode) drawVersionBits() {
+	if q.version < 7 {
+		return
+	}
+
+	rem := q.version
+	for i := 0; i < 12; i++ {
+		rem = (rem << 1) ^ ((rem >> 11) * 0x1F25)
+	}
+	bits := q.version<<12 | rem
+
+	for i := 0; i < 18; i++ {
+		bit := qrBit(bits, i)
+		a := q.size - 11 + i%3
+		b := i / 3
+		q.setFunction(a, b, bit)
+		q.setFunction(b, a, bit)
+	}
+}
+
+//drawCodewords draws the data and error correction codewords in the zigzag pattern
+//required by the QR code spec, skipping function modules.
+func (q *qrCode) drawCodewords(data []byte) {
+	i := 0
+	for right := q.size - 1; right >= 1; right -= 2 {
+		//Skip the vertical timing pattern.
+		if right == 6 {
+			right = 5
+		}
+
+		for vert := 0; vert < q.size; vert++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+				y := vert
+				if (right+1)&2 == 0 {
+					y = q.size - 1 - vert
+				}
+
+				if !q.isFunction[y][x] && i < len(data)*8 {
+					q.modules[y][x] = qrBit(int(data[i>>3]), 7-(i&7))
+					i++
+				}
+			}
+		}
+	}
+}
+
+//applyMask inverts the data modules that match a mask pattern.
+func (q *qrCode) applyMask(mask int) {
+	for y := 0; y < q.size; y++ {
+		for x := 0; x < q.size; x++ {
+			if q.isFunction[y][x] {
+				continue
+			}
+
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+
+			if invert {
+				q.modules[y][x] = !q.modules[y][x]
+			}
+		}
+	}
+}
+
+//penalty returns a score for how difficult the QR code is to read per the QR code
+//spec. A lower score is better.
+func (q *qrCode) penalty() (result int) {
+	get := func(x, y int, vertical bool) bool {
+		if vertical {
+			return q.modules[x][y]
+		}
+		return q.modules[y][x]
+	}
+
+	//Finder-like patterns, dark-light-dark-dark-dark-light-dark, with four light
+	//modules before or after.
+	finderLike := [2][11]bool{
+		{true, false, true, true, true, false, true, false, false, false, false},
+		{false, false, false, false, true, false, true, true, true, false, true},
+	}
+
+	for _, vertical := range []bool{false, true} {
+		for y := 0; y < q.size; y++ {
+			//Runs of five or more modules of the same color.
+			run := 1
+			for x := 1; x < q.size; x++ {
+				if get(x, y, vertical) == get(x-1, y, vertical) {
+					run++
+					continue
+				}
+
+				if run >= 5 {
+					result += 3 + run - 5
+				}
+				run = 1
+			}
+			if run >= 5 {
+				result += 3 + run - 5
+			}
+
+			for x := 0; x+11 <= q.size; x++ {
+				for _, pattern := range finderLike {
+					match := true
+					for i, dark := range pattern {
+						if get(x+i, y, vertical) != dark {
+							match = false
+							break
+						}
+					}
+					if match {
+						result += 40
+					}
+				}
+			}
+		}
+	}
+
+	//Blocks of 2x2 modules of the same color.
+	dark := 0
+	for y := 0; y < q.size; y++ {
+		for x := 0; x < q.size; x++ {
+			if q.modules[y][x] {
+				dark++
+			}
+
+			if x+1 < q.size && y+1 < q.size {
+				c := q.modules[y][x]
+				if c == q.modules[y][x+1] && c == q.modules[y+1][x] && c == q.modules[y+1][x+1] {
+					result += 3
+				}
+			}
+		}
+	}
+
+	//Balance of dark and light modules, penalized for each 5% away from 50%.
+	total := q.size * q.size
+	k := (qrAbs(dark*20-total*10)+total-1)/total - 1
+	result += k * 10
+
+	return
+}
+
+//qrBit returns if bit i of v is set.
+func qrBit(v, i int) bool {
+	return (v>>uint(i))&1 != 0
+}
+
+//qrAbs returns the absolute value of v.
+func qrAbs(v int) int {
+	if v < 0 {
+		return -v
+	}
+
+	return v
+}
+
+//qrMax returns the larger of a and b.
+func qrMax(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
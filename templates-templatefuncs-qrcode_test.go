@@ -0,0 +1,225 @@
+package templates
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestFuncQRCode(t *testing.T) {
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//PNG image of the requested size.
+	u, err := FuncQRCode("https://example.com", 200)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	const prefix = "data:image/png;base64,"
+	if !strings.HasPrefix(string(u), prefix) {
+		t.Fatal("Data URI not returned", u)
+		return
+	}
+	b, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(string(u), prefix))
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	img, err := png.Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if img.Bounds().Dx() != 200 || img.Bounds().Dy() != 200 {
+		t.Fatal("Image not sized as expected", img.Bounds())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Quiet zone is light and the top left finder pattern is dark. A 19 byte string
+	//results in a version 2, 25x25 module, code. With the quiet zone this is 33
+	//modules, at 6 pixels per module, centered in 200 pixels.
+	isDark := func(x, y int) bool {
+		return color.GrayModel.Convert(img.At(x, y)).(color.Gray).Y < 128
+	}
+	if isDark(0, 0) || isDark(100, 0) || isDark(0, 100) {
+		t.Fatal("Quiet zone should be light")
+		return
+	}
+	offset := (200 - 25*6) / 2
+	if !isDark(offset, offset) || !isDark(offset+6*3, offset+6*3) {
+		t.Fatal("Finder pattern should be dark")
+		return
+	}
+	if isDark(offset+6, offset+6) {
+		t.Fatal("Finder pattern ring should be light")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Image larger than requested if size is too small.
+	u, err = FuncQRCode("https://example.com", 10)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	b, _ = base64.StdEncoding.DecodeString(strings.TrimPrefix(string(u), prefix))
+	img, err = png.Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if img.Bounds().Dx() != 33 {
+		t.Fatal("Image not sized as expected", img.Bounds())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Content too long.
+	_, err = FuncQRCode(strings.Repeat("a", 2332), 200)
+	if err != ErrQRCodeContentTooLong {
+		t.Fatal("Error about content length should have occured", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestQREncode(t *testing.T) {
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Smallest version that fits is used.
+	tests := map[int]int{
+		0:    21,
+		14:   21,
+		15:   25,
+		2331: 177,
+	}
+	for length, size := range tests {
+		modules, err := qrEncode(bytes.Repeat([]byte("a"), length), -1)
+		if err != nil {
+			t.Fatal(err)
+			return
+		}
+		if len(modules) != size {
+			t.Fatal("Version not picked as expected", length, len(modules))
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestQREncodeKnownAnswer(t *testing.T) {
+	//Expected modules, # for dark, were encoded with rsc.io/qr, a separate
+	//implementation of the QR code spec, using the same version, M error correction
+	//level, and mask. The version 7 code includes the version bits and is made up of
+	//many error correction blocks.
+	tests := []struct {
+		content  string
+		mask     int
+		expected []string
+	}{
+		{"templates", 0, []string{
+			"#######..##...#######",
+			"#.....#.#..##.#.....#",
+			"#.###.#...###.#.###.#",
+			"#.###.#...##..#.###.#",
+			"#.###.#.##..#.#.###.#",
+			"#.....#..#..#.#.....#",
+			"#######.#.#.#.#######",
+			".........#.##........",
+			"#.#.#.#..#.#....#..#.",
+			"#.##....#......##..##",
+			"##.######.#.#########",
+			"#.#.#..........#...#.",
+			".####.##..#.##.##..##",
+			"........#..#.#..#.#.#",
+			"#######....#.#.######",
+			"#.....#..#.###.......",
+			"#.###.#.#.##..#.##...",
+			"#.###.#..##...####.#.",
+			"#.###.#.#.#.#..##.#.#",
+			"#.....#...#...##...#.",
+			"#######.###.#.##...##",
+		}},
+		{strings.Repeat("0123456789", 11), 5, []string{
+			"#######...#.#.###..##.#..######..#..#.#######",
+			"#.....#.###.###.#.......###..#.....#..#.....#",
+			"#.###.#.###.#.#..##.##.#....######.#..#.###.#",
+			"#.###.#.####.#..#.##.#..###.#.#.#..##.#.###.#",
+			"#.###.#....#.#.#.#..#####.....##..###.#.###.#",
+			"#.....#.....##..##..#...##..##.#.#....#.....#",
+			"#######.#.#.#.#.#.#.#.#.#.#.#.#.#.#.#.#######",
+			"........#.##.###...##...#.#######.#.#........",
+			"#.....#.#...#.#.##.#######.#.##..#.#.##..###.",
+			"#..##.....###..##.#######.#.#.#.#.#.#.#.#.##.",
+			".#.##.#...#....##..#....######.#..#####..##..",
+			"..####..###...#.#.#....##....####.#..#.##.#..",
+			"#.#######.#.#.#####.#..####..#.##.#...###..##",
+			"#....#.#...##.#..#...###....#.###...#...#.###",
+			"###...#...#######..####.###.##....#.###......",
+			"...#...#.#...#..###.##..#.##.###..#..###..#..",
+			"#..#######..#.#...#..#.###.#..#..#.#..##.#...",
+			"##.###..#...##...#..##.#...#..#.#...#..#..###",
+			"..##..#....########..####..#...##.......#..##",
+			".##.##.#.##.##.##.#....#.########..###.##.##.",
+			"#.#######....####.#######.##.##....#######...",
+			"##.##...##..####.#.##...##..#.#.#.###...#.##.",
+			"###.#.#.####.###..###.#.#..###.#..#.#.#.###..",
+			"##.##...###.#######.#...#..######.#.#...#.#..",
+			"....########.######.##########.##.#.#####..##",
+			"#..###.#.####.######.##.#..##.###...###...###",
+			"#..##.#..#..#.#..#.....#.##..#....##...#.....",
+			"#...#...#.#.#..#.###.##.#.#.####..####.##.#..",
+			"#.#.####.#.#......#..#...#.#.#...#..##..##.##",
+			"##.###..####.##...#.###.#..#..#.#...##....###",
+			"..##..##.####.#.##..#.###..#...##....##....##",
+			".#.###.###.##....#.##.##...######..####...##.",
+			"##.#.##.#....#.##..###....##.##......#..##...",
+			".#.#.#.#.#.##.#.##.###.#..#.#.###.##....####.",
+			"....#.#.####.###....##.#.#####....#.......#..",
+			".####.....#####..#####..#..######..#####..#..",
+			"#..##.####.#.##....######.####.###.######..##",
+			"........##..#.#..####...#..##.###...#...#.###",
+			"#######...##......###.#.##...#....###.#.#....",
+			"#.....#...#.##..#...#...#.##.###..###...#.###",
+			"#.###.#..#....#.#...######.#.#...#..######..#",
+			"#.###.#.....####..#.#..#....#.#.#..#.#.##.#.#",
+			"#.###.#..#..##.##.#.#.###...#..##..##..##...#",
+			"#.....#.........#..###.#...##..##...#.....#..",
+			"#######.#.##.#.#........#.##..#......##..#.#.",
+		}},
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	for _, tt := range tests {
+		modules, err := qrEncode([]byte(tt.content), tt.mask)
+		if err != nil {
+			t.Fatal(err)
+			return
+		}
+		if len(modules) != len(tt.expected) {
+			t.Fatal("Version not picked as expected", len(modules), len(tt.expected))
+			return
+		}
+
+		for y, row := range modules {
+			var b strings.Builder
+			for _, dark := range row {
+				if dark {
+					b.WriteByte('#')
+				} else {
+					b.WriteByte('.')
+				}
+			}
+			if b.String() != tt.expected[y] {
+				t.Fatal("Row not encoded as expected", len(modules), y, b.String(), tt.expected[y])
+				return
+			}
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,81 @@
+/*
+This file defines template level functions for generating random values. These are
+typically used for unique DOM IDs, such as when a partial that uses an id attribute,
+i.e. for a <label for="">, is included more than once on a page.
+
+Random values are generated using crypto/rand. Since these funcs return a different
+value each time they are called, the output of templates using them is not cached, the
+same as for any other func in your config's FuncMap.
+*/
+
+package templates
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+)
+
+//randomStringChars is the list of characters used in random strings. Only letters and
+//numbers are used so that random strings can be used in IDs, class names, and URLs
+//without escaping.
+const randomStringChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+//FuncUUID returns a random, version 4, UUID, i.e. "f47ac10b-58cc-4372-a567-0e02b2c3d479".
+//A blank string is returned if random data cannot be read.
+//
+//In templates, this is available as uuid and is used as
+//{{$id := uuid}}<label for="{{$id}}">Name</label><input id="{{$id}}">.
+func FuncUUID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		log.Println("templates.FuncUUID", err)
+		return ""
+	}
+
+	//Set the version (4) and variant (RFC 4122) bits.
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	s := hex.EncodeToString(b)
+	return s[0:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:32]
+}
+
+//FuncRandomString returns a random string of n letters and numbers. A blank string is
+//returned if n is less than 1 or random data cannot be read. Since a random string may
+//start with a number, use a prefix when the string is used as an ID that is referenced
+//in CSS selectors.
+//
+//In templates, this is available as randomString and is used as
+//<div id="tab-{{randomString 8}}">.
+func FuncRandomString(n int) string {
+	if n < 1 {
+		return ""
+	}
+
+	//Bytes that would make some characters more likely than others are discarded, so
+	//more bytes than needed are read.
+	const maxByte = 256 - (256 % len(randomStringChars))
+
+	s := make([]byte, 0, n)
+	b := make([]byte, n+n/4+1)
+	for len(s) < n {
+		if _, err := rand.Read(b); err != nil {
+			log.Println("templates.FuncRandomString", err)
+			return ""
+		}
+
+		for _, v := range b {
+			if int(v) >= maxByte {
+				continue
+			}
+
+			s = append(s, randomStringChars[int(v)%len(randomStringChars)])
+			if len(s) == n {
+				break
+			}
+		}
+	}
+
+	return string(s)
+}
@@ -0,0 +1,44 @@
+package templates
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestFuncUUID(t *testing.T) {
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	pattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+	a, b := FuncUUID(), FuncUUID()
+	if !pattern.MatchString(a) {
+		t.Fatal("UUID not in expected format", a)
+		return
+	}
+	if a == b {
+		t.Fatal("UUIDs should be unique", a, b)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestFuncRandomString(t *testing.T) {
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	pattern := regexp.MustCompile(`^[a-zA-Z0-9]+$`)
+
+	for _, n := range []int{1, 8, 100} {
+		s := FuncRandomString(n)
+		if len(s) != n || !pattern.MatchString(s) {
+			t.Fatal("Random string not as expected", n, s)
+			return
+		}
+	}
+	if FuncRandomString(16) == FuncRandomString(16) {
+		t.Fatal("Random strings should be unique")
+		return
+	}
+	if s := FuncRandomString(0); s != "" {
+		t.Fatal("Blank string should be returned for invalid length", s)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,142 @@
+/*
+This file defines template level functions for building signed URLs. Signed URLs are
+used for links that should only work for a limited time, such as download or preview
+links, without your http handlers having to build each link and provide it to the
+template via InjectedData.
+
+A signed URL has an "expires" and a "signature" query parameter added. The signature is
+an HMAC-SHA256 of the URL's path and query, including the expiration, using the
+URLSigningKey set in your config. Use VerifySignedURL() in the http handler the link
+points to to check that the URL was signed by your app and has not expired.
+*/
+
+package templates
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	//signedURLExpiresParam is the query parameter the expiration of a signed URL, as a
+	//unix timestamp, is stored in.
+	signedURLExpiresParam = "expires"
+
+	//signedURLSignatureParam is the query parameter the signature of a signed URL is
+	//stored in.
+	signedURLSignatureParam = "signature"
+)
+
+var (
+	//ErrNoURLSigningKey is returned when signing or verifying a URL and no
+	//URLSigningKey is set in the config.
+	ErrNoURLSigningKey = errors.New("templates: no value set for URLSigningKey")
+
+	//ErrInvalidURLSignature is returned when verifying a URL that has a missing or
+	//incorrect signature.
+	ErrInvalidURLSignature = errors.New("templates: invalid url signature")
+
+	//ErrURLExpired is returned when verifying a signed URL whose expiration has passed.
+	ErrURLExpired = errors.New("templates: signed url expired")
+)
+
+//SignURL returns path with an expiration, ttl from now, and a signature added as query
+//parameters. path can include a query already. If no URLSigningKey is set, path is
+//returned as is and will not pass VerifySignedURL().
+func (c *Config) SignURL(path string, ttl time.Duration) string {
+	s, err := c.signURL(path, ttl)
+	if err != nil {
+		return path
+	}
+
+	return s
+}
+
+//SignURL returns a signed URL using the default package level config.
+func SignURL(path string, ttl time.Duration) string {
+	return Default().SignURL(path, ttl)
+}
+
+//signURL signs path returning an error if path cannot be signed.
+func (c *Config) signURL(path string, ttl time.Duration) (string, error) {
+	if len(c.URLSigningKey) == 0 {
+		return "", ErrNoURLSigningKey
+	}
+
+	u, err := url.Parse(path)
+	if err != nil {
+		return "", err
+	}
+
+	q := u.Query()
+	q.Del(signedURLSignatureParam)
+	q.Set(signedURLExpiresParam, strconv.FormatInt(time.Now().Add(ttl).Unix(), 10))
+	u.RawQuery = q.Encode()
+
+	q.Set(signedURLSignatureParam, c.urlSignature(u.Path, u.RawQuery))
+	u.RawQuery = q.Encode()
+
+	return u.String(), nil
+}
+
+//signURLFunc is the signURL template func. The ttl is provided as a duration string
+//since durations cannot be easily created in templates, i.e.
+//<a href="{{signURL "/files/report.pdf" "15m"}}">. Unlike SignURL(), an error is
+//returned if no URLSigningKey is set so that the template fails to render.
+func (c *Config) signURLFunc(path, ttl string) (string, error) {
+	d, err := time.ParseDuration(ttl)
+	if err != nil {
+		return "", err
+	}
+
+	return c.signURL(path, d)
+}
+
+//VerifySignedURL checks that a URL, typically a request's URL, was signed using the
+//URLSigningKey set in the config and has not expired.
+func (c *Config) VerifySignedURL(u *url.URL) error {
+	if len(c.URLSigningKey) == 0 {
+		return ErrNoURLSigningKey
+	}
+
+	q := u.Query()
+	signature := q.Get(signedURLSignatureParam)
+	if signature == "" {
+		return ErrInvalidURLSignature
+	}
+	q.Del(signedURLSignatureParam)
+
+	//The query is re-encoded the same as when the URL was signed, which sorts the
+	//query parameters, so that the order of parameters does not matter.
+	expected := c.urlSignature(u.Path, q.Encode())
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return ErrInvalidURLSignature
+	}
+
+	expires, err := strconv.ParseInt(q.Get(signedURLExpiresParam), 10, 64)
+	if err != nil {
+		return ErrInvalidURLSignature
+	}
+	if time.Now().Unix() > expires {
+		return ErrURLExpired
+	}
+
+	return nil
+}
+
+//VerifySignedURL checks a signed URL using the default package level config.
+func VerifySignedURL(u *url.URL) error {
+	return Default().VerifySignedURL(u)
+}
+
+//urlSignature returns the signature for a URL's path and encoded query.
+func (c *Config) urlSignature(path, rawQuery string) string {
+	mac := hmac.New(sha256.New, c.URLSigningKey)
+	mac.Write([]byte(path + "?" + rawQuery))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
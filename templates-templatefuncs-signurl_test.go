@@ -0,0 +1,133 @@
+package templates
+
+import (
+	"errors"
+	"html"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignURL(t *testing.T) {
+	c := NewConfig()
+	c.URLSigningKey = []byte("0123456789abcdef0123456789abcdef")
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Signed URL verifies.
+	s := c.SignURL("/files/report.pdf?inline=1", time.Hour)
+	u, err := url.Parse(s)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if u.Path != "/files/report.pdf" || u.Query().Get("inline") != "1" {
+		t.Fatal("URL not signed as expected", s)
+		return
+	}
+	err = c.VerifySignedURL(u)
+	if err != nil {
+		t.Fatal("Signed URL should verify", s, err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Altered URL does not verify.
+	altered := *u
+	altered.Path = "/files/other.pdf"
+	err = c.VerifySignedURL(&altered)
+	if err != ErrInvalidURLSignature {
+		t.Fatal("Altered URL should not verify", err)
+		return
+	}
+	u.RawQuery = strings.Replace(u.RawQuery, "inline=1", "inline=0", 1)
+	err = c.VerifySignedURL(u)
+	if err != ErrInvalidURLSignature {
+		t.Fatal("Altered URL should not verify", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Expired URL does not verify.
+	u, _ = url.Parse(c.SignURL("/files/report.pdf", -time.Minute))
+	err = c.VerifySignedURL(u)
+	if err != ErrURLExpired {
+		t.Fatal("Expired URL should not verify", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Different key does not verify.
+	u, _ = url.Parse(c.SignURL("/files/report.pdf", time.Hour))
+	other := NewConfig()
+	other.URLSigningKey = []byte("fedcba9876543210fedcba9876543210")
+	err = other.VerifySignedURL(u)
+	if err != ErrInvalidURLSignature {
+		t.Fatal("URL signed with different key should not verify", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//No key.
+	other.URLSigningKey = nil
+	if s := other.SignURL("/files/report.pdf", time.Hour); s != "/files/report.pdf" {
+		t.Fatal("URL should not be signed without a key", s)
+		return
+	}
+	if err := other.VerifySignedURL(u); err != ErrNoURLSigningKey {
+		t.Fatal("Error about missing key should have occured", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestSignURLTemplateFunc(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	subdirs := []string{"data"}
+	c := NewOnDiskConfig(base, subdirs)
+	c.URLSigningKey = []byte("0123456789abcdef0123456789abcdef")
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Signed URL in template verifies.
+	w := httptest.NewRecorder()
+	c.Show(w, "data", "signurl", nil)
+	u, err := url.Parse(html.UnescapeString(strings.TrimSpace(w.Body.String())))
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	err = c.VerifySignedURL(u)
+	if err != nil {
+		t.Fatal("Signed URL should verify", w.Body.String(), err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Template fails to render without a key.
+	c.URLSigningKey = nil
+	err = c.Render(httptest.NewRecorder(), "data", "signurl", nil)
+	if !errors.Is(err, ErrNoURLSigningKey) {
+		t.Fatal("Error about missing key should have occured", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,162 @@
+/*
+This file defines a template level function for rendering a slice of structs as an HTML
+table. This is useful for admin or debug pages where writing a {{range}} loop by hand
+for each type of data is busywork.
+
+Columns are determined from the struct's exported fields. The column's header and order
+can be set using a "table" struct tag, for example:
+	type User struct {
+		ID       int    `table:"ID,order=1"`
+		Name     string `table:"Full Name,order=2"`
+		Password string `table:"-"`
+	}
+Fields tagged with "-" are not shown. Fields without an order are shown after fields with
+an order in the order they are defined in the struct.
+*/
+
+package templates
+
+import (
+	"fmt"
+	"html/template"
+	"log"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//tableColumn is a column in a table built by FuncTable.
+type tableColumn struct {
+	index  int
+	header string
+	order  int
+}
+
+//FuncTable returns a slice of structs, or pointers to structs, as an HTML table. Each
+//value is escaped. If columns are provided, only the fields with those names are shown,
+//in the order provided, for example {{table .InjectedData.Users "Name" "Email"}}.
+//Nothing is returned if rows is not a slice of structs.
+func FuncTable(rows interface{}, columns ...string) template.HTML {
+	v := reflect.ValueOf(rows)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		log.Println("templates.FuncTable", "rows is not a slice", v.Kind())
+		return ""
+	}
+
+	t := v.Type().Elem()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		log.Println("templates.FuncTable", "rows is not a slice of structs", t.Kind())
+		return ""
+	}
+
+	cols := tableColumns(t, columns)
+
+	var b strings.Builder
+	b.WriteString("<table><thead><tr>")
+	for _, c := range cols {
+		b.WriteString("<th>" + template.HTMLEscapeString(c.header) + "</th>")
+	}
+	b.WriteString("</tr></thead><tbody>")
+
+	for i := 0; i < v.Len(); i++ {
+		row := v.Index(i)
+		if row.Kind() == reflect.Ptr {
+			if row.IsNil() {
+				continue
+			}
+			row = row.Elem()
+		}
+
+		b.WriteString("<tr>")
+		for _, c := range cols {
+			b.WriteString("<td>" + template.HTMLEscapeString(tableValue(row.Field(c.index))) + "</td>")
+		}
+		b.WriteString("</tr>")
+	}
+	b.WriteString("</tbody></table>")
+
+	return template.HTML(b.String())
+}
+
+//tableColumns returns the columns for a struct type. If names are provided, only the
+//fields with those names are returned, in the order provided.
+func tableColumns(t reflect.Type, names []string) (cols []tableColumn) {
+	byName := make(map[string]tableColumn)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			//unexported
+			continue
+		}
+
+		col := tableColumn{
+			index:  i,
+			header: f.Name,
+			order:  -1,
+		}
+
+		tag := f.Tag.Get("table")
+		if tag == "-" {
+			continue
+		}
+		parts := strings.Split(tag, ",")
+		if h := strings.TrimSpace(parts[0]); h != "" {
+			col.header = h
+		}
+		for _, p := range parts[1:] {
+			p = strings.TrimSpace(p)
+			if strings.HasPrefix(p, "order=") {
+				if o, err := strconv.Atoi(strings.TrimPrefix(p, "order=")); err == nil {
+					col.order = o
+				}
+			}
+		}
+
+		byName[f.Name] = col
+		cols = append(cols, col)
+	}
+
+	if len(names) > 0 {
+		cols = cols[:0]
+		for _, n := range names {
+			if col, ok := byName[n]; ok {
+				cols = append(cols, col)
+			}
+		}
+		return
+	}
+
+	sort.SliceStable(cols, func(i, j int) bool {
+		oi, oj := cols[i].order, cols[j].order
+		switch {
+		case oi >= 0 && oj >= 0:
+			return oi < oj
+		case oi >= 0:
+			return true
+		default:
+			return false
+		}
+	})
+
+	return
+}
+
+//tableValue returns a field's value as a string for displaying in a table.
+func tableValue(v reflect.Value) string {
+	if v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+
+	if s, ok := v.Interface().(fmt.Stringer); ok {
+		return s.String()
+	}
+
+	return fmt.Sprint(v.Interface())
+}
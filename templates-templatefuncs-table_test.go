@@ -0,0 +1,58 @@
+package templates
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFuncTable(t *testing.T) {
+	type user struct {
+		Email    string
+		Name     string `table:"Full Name,order=1"`
+		Password string `table:"-"`
+		private  string
+	}
+	users := []*user{
+		{Email: "a@example.com", Name: "<Alice>", Password: "secret"},
+		nil,
+		{Email: "b@example.com", Name: "Bob"},
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Columns from struct tags.
+	html := string(FuncTable(users))
+	if !strings.Contains(html, "<thead><tr><th>Full Name</th><th>Email</th></tr></thead>") {
+		t.Fatal("Headers not built as expected", html)
+		return
+	}
+	if strings.Contains(html, "secret") {
+		t.Fatal("Ignored field should not be shown", html)
+		return
+	}
+	if !strings.Contains(html, "<td>&lt;Alice&gt;</td>") {
+		t.Fatal("Values not escaped", html)
+		return
+	}
+	if strings.Count(html, "<tr>") != 3 {
+		t.Fatal("Wrong number of rows", html)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Selected columns.
+	html = string(FuncTable(users, "Email"))
+	if !strings.Contains(html, "<thead><tr><th>Email</th></tr></thead>") {
+		t.Fatal("Selected columns not used", html)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Not a slice of structs.
+	if html := FuncTable([]string{"a"}); html != "" {
+		t.Fatal("Nothing should have been returned", html)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,122 @@
+/*
+This file defines template level functions for displaying times relative to now and for
+working with windows of time, such as the current day or week. These are commonly
+needed in activity feeds and dashboards.
+
+Since these funcs use the current time, the output of templates using them is not
+cached, the same as for any other func in your config's FuncMap.
+*/
+
+package templates
+
+import (
+	"strconv"
+	"time"
+)
+
+//FuncTimeAgo returns how long ago t was in words, i.e. "5 minutes ago" or "3 days ago".
+//Times less than a minute ago return "just now" and times in the future are returned as,
+//i.e., "in 2 hours". Months are treated as 30 days and years as 365 days.
+func FuncTimeAgo(t time.Time) string {
+	return timeAgo(t, time.Now())
+}
+
+//timeAgo returns how long before now t was in words.
+func timeAgo(t, now time.Time) string {
+	d := now.Sub(t)
+	future := d < 0
+	if future {
+		d = -d
+	}
+
+	if d < time.Minute {
+		return "just now"
+	}
+
+	units := []struct {
+		name string
+		size time.Duration
+	}{
+		{"year", 365 * 24 * time.Hour},
+		{"month", 30 * 24 * time.Hour},
+		{"week", 7 * 24 * time.Hour},
+		{"day", 24 * time.Hour},
+		{"hour", time.Hour},
+		{"minute", time.Minute},
+	}
+
+	var s string
+	for _, u := range units {
+		if d < u.size {
+			continue
+		}
+
+		n := int(d / u.size)
+		s = strconv.Itoa(n) + " " + u.name
+		if n != 1 {
+			s += "s"
+		}
+		break
+	}
+
+	if future {
+		return "in " + s
+	}
+	return s + " ago"
+}
+
+//FuncIsToday returns if t is on the current day, in t's location.
+func FuncIsToday(t time.Time) bool {
+	return sameDay(t, time.Now().In(t.Location()))
+}
+
+//FuncIsYesterday returns if t is on the day before the current day, in t's location.
+func FuncIsYesterday(t time.Time) bool {
+	return sameDay(t, time.Now().In(t.Location()).AddDate(0, 0, -1))
+}
+
+//sameDay returns if a and b are on the same day.
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
+
+//FuncStartOfDay returns midnight at the start of t's day, in t's location.
+func FuncStartOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+//FuncStartOfWeek returns midnight at the start of t's week, in t's location. Weeks start
+//on Monday per ISO 8601.
+func FuncStartOfWeek(t time.Time) time.Time {
+	daysSinceMonday := (int(t.Weekday()) + 6) % 7
+	return FuncStartOfDay(t).AddDate(0, 0, -daysSinceMonday)
+}
+
+//FuncStartOfMonth returns midnight at the start of t's month, in t's location.
+func FuncStartOfMonth(t time.Time) time.Time {
+	y, m, _ := t.Date()
+	return time.Date(y, m, 1, 0, 0, 0, 0, t.Location())
+}
+
+//FuncWithinLast returns if t is within the duration before now. The duration is provided
+//as a string, parsed by time.ParseDuration(), since durations cannot be easily created in
+//templates. False is returned if the duration cannot be parsed or t is in the future.
+//
+//In templates, this is available as withinLast and is used as
+//{{if withinLast .CreatedAt "24h"}}New{{end}}.
+func FuncWithinLast(t time.Time, duration string) bool {
+	return withinLast(t, duration, time.Now())
+}
+
+//withinLast returns if t is within the duration before now.
+func withinLast(t time.Time, duration string, now time.Time) bool {
+	d, err := time.ParseDuration(duration)
+	if err != nil {
+		return false
+	}
+
+	return !t.After(now) && now.Sub(t) <= d
+}
@@ -0,0 +1,95 @@
+package templates
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeAgo(t *testing.T) {
+	now := time.Date(2021, 11, 21, 15, 4, 5, 0, time.UTC)
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	tests := map[time.Duration]string{
+		30 * time.Second:        "just now",
+		-30 * time.Second:       "just now",
+		time.Minute:             "1 minute ago",
+		5 * time.Minute:         "5 minutes ago",
+		2 * time.Hour:           "2 hours ago",
+		-2 * time.Hour:          "in 2 hours",
+		25 * time.Hour:          "1 day ago",
+		10 * 24 * time.Hour:     "1 week ago",
+		45 * 24 * time.Hour:     "1 month ago",
+		800 * 24 * time.Hour:    "2 years ago",
+		-3 * 7 * 24 * time.Hour: "in 3 weeks",
+	}
+	for ago, expected := range tests {
+		if s := timeAgo(now.Add(-ago), now); s != expected {
+			t.Fatal("Time ago not returned as expected", ago, s, expected)
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestFuncIsToday(t *testing.T) {
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	now := time.Now()
+	if !FuncIsToday(now) || FuncIsToday(now.AddDate(0, 0, -1)) {
+		t.Fatal("Today not determined as expected")
+		return
+	}
+	if !FuncIsYesterday(now.AddDate(0, 0, -1)) || FuncIsYesterday(now) {
+		t.Fatal("Yesterday not determined as expected")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestFuncStartOf(t *testing.T) {
+	loc := time.FixedZone("EST", -5*60*60)
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Sunday, November 21, 2021.
+	sunday := time.Date(2021, 11, 21, 15, 4, 5, 0, loc)
+	if d := FuncStartOfDay(sunday); !d.Equal(time.Date(2021, 11, 21, 0, 0, 0, 0, loc)) {
+		t.Fatal("Start of day not returned as expected", d)
+		return
+	}
+	if d := FuncStartOfWeek(sunday); !d.Equal(time.Date(2021, 11, 15, 0, 0, 0, 0, loc)) {
+		t.Fatal("Start of week not returned as expected", d)
+		return
+	}
+	monday := time.Date(2021, 11, 15, 9, 0, 0, 0, loc)
+	if d := FuncStartOfWeek(monday); !d.Equal(time.Date(2021, 11, 15, 0, 0, 0, 0, loc)) {
+		t.Fatal("Start of week not returned as expected", d)
+		return
+	}
+	if d := FuncStartOfMonth(sunday); !d.Equal(time.Date(2021, 11, 1, 0, 0, 0, 0, loc)) {
+		t.Fatal("Start of month not returned as expected", d)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestWithinLast(t *testing.T) {
+	now := time.Date(2021, 11, 21, 15, 4, 5, 0, time.UTC)
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	if !withinLast(now.Add(-time.Hour), "24h", now) {
+		t.Fatal("Time should be within duration")
+		return
+	}
+	if withinLast(now.Add(-25*time.Hour), "24h", now) {
+		t.Fatal("Time should not be within duration")
+		return
+	}
+	if withinLast(now.Add(time.Hour), "24h", now) {
+		t.Fatal("Future time should not be within duration")
+		return
+	}
+	if withinLast(now, "a day", now) {
+		t.Fatal("Invalid duration should return false")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
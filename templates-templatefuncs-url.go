@@ -0,0 +1,102 @@
+/*
+This file defines template level functions for building URLs.
+*/
+
+package templates
+
+import (
+	"html/template"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+)
+
+//FuncAbsoluteURL builds an absolute URL to path using the scheme and host of the request.
+//This is needed for URLs used outside of your site, such as in emails, canonical links,
+//or Open Graph tags. If trustProxy is true, the X-Forwarded-Proto and X-Forwarded-Host
+//headers are used, if provided, since the request's scheme and host are those of the
+//proxy; only set trustProxy if your app is behind a proxy that sets these headers. If
+//path is already an absolute URL, or no request is provided, path is returned as is.
+//
+//In templates, this is available as the request-scoped func absoluteURL and is used as
+//{{absoluteURL "/path/to/page"}}.
+func FuncAbsoluteURL(r *http.Request, path string, trustProxy bool) string {
+	if r == nil {
+		return path
+	}
+	if u, err := url.Parse(path); err == nil && u.IsAbs() {
+		return path
+	}
+
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	host := r.Host
+
+	if trustProxy {
+		if v := firstHeaderValue(r, "X-Forwarded-Proto"); v == "http" || v == "https" {
+			scheme = v
+		}
+		if v := firstHeaderValue(r, "X-Forwarded-Host"); v != "" {
+			host = v
+		}
+	}
+
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+
+	return scheme + "://" + host + path
+}
+
+//firstHeaderValue returns the first value of a header that may contain a comma
+//separated list of values, as is common with headers set by proxies.
+func firstHeaderValue(r *http.Request, header string) string {
+	v := r.Header.Get(header)
+	if i := strings.Index(v, ","); i >= 0 {
+		v = v[:i]
+	}
+
+	return strings.ToLower(strings.TrimSpace(v))
+}
+
+//FuncSrcSet returns a srcset attribute value for a responsive image. For each width, the
+//URL to the image is built by inserting the width before the extension of base, for
+//example "/img/hero.jpg" with a width of 480 results in "/img/hero-480w.jpg 480w". You
+//must create an image for each width yourself.
+//
+//In templates, this is available as srcSet and is used as
+//<img src="/img/hero.jpg" srcset="{{srcSet "/img/hero.jpg" 480 800 1200}}">. When used
+//in templates, the filename for each width is replaced by its cache busting filename if
+//one exists in the config's CacheBustingFilePairs.
+func FuncSrcSet(base string, widths ...int) template.Srcset {
+	return srcSet(base, widths, nil)
+}
+
+//srcSet builds a srcset attribute value replacing each filename with its cache busting
+//filename, if one exists in pairs.
+func srcSet(base string, widths []int, pairs map[string]string) template.Srcset {
+	dir, file := path.Split(base)
+	ext := path.Ext(file)
+	name := strings.TrimSuffix(file, ext)
+
+	candidates := make([]string, 0, len(widths))
+	for _, w := range widths {
+		if w <= 0 {
+			continue
+		}
+
+		width := strconv.Itoa(w)
+		f := name + "-" + width + "w" + ext
+		if cb, ok := pairs[f]; ok {
+			f = cb
+		}
+
+		candidates = append(candidates, dir+f+" "+width+"w")
+	}
+
+	return template.Srcset(strings.Join(candidates, ", "))
+}
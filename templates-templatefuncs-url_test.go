@@ -0,0 +1,152 @@
+package templates
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestFuncAbsoluteURL(t *testing.T) {
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Scheme and host from request.
+	r := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+	if u := FuncAbsoluteURL(r, "/page", false); u != "http://example.com/page" {
+		t.Fatal("URL not built as expected", u)
+		return
+	}
+	r.TLS = &tls.ConnectionState{}
+	if u := FuncAbsoluteURL(r, "page", false); u != "https://example.com/page" {
+		t.Fatal("URL not built as expected", u)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Proxy headers only used when trusted.
+	r = httptest.NewRequest(http.MethodGet, "http://internal:8080/", nil)
+	r.Header.Set("X-Forwarded-Proto", "https")
+	r.Header.Set("X-Forwarded-Host", "example.com, internal")
+	if u := FuncAbsoluteURL(r, "/page", false); u != "http://internal:8080/page" {
+		t.Fatal("Proxy headers should not have been used", u)
+		return
+	}
+	if u := FuncAbsoluteURL(r, "/page", true); u != "https://example.com/page" {
+		t.Fatal("Proxy headers not used as expected", u)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Absolute URLs and missing requests return path as is.
+	if u := FuncAbsoluteURL(r, "https://other.com/page", true); u != "https://other.com/page" {
+		t.Fatal("Absolute URL should not have been modified", u)
+		return
+	}
+	if u := FuncAbsoluteURL(nil, "/page", true); u != "/page" {
+		t.Fatal("Path should not have been modified", u)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestAbsoluteURLRequestFunc(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	subdirs := []string{"app", "help"}
+	c := NewOnDiskConfig(base, subdirs)
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Func is bound to each request.
+	for _, host := range []string{"one.example.com", "two.example.com"} {
+		r := httptest.NewRequest(http.MethodGet, "http://"+host+"/", nil)
+		w := httptest.NewRecorder()
+		c.ShowRequest(w, r, "help", "absolute", nil)
+		if w.Code != http.StatusOK {
+			t.Fatal("Error showing", w.Code, w.Body)
+			return
+		}
+		if strings.TrimSpace(w.Body.String()) != "http://"+host+"/help/absolute" {
+			t.Fatal("URL not built as expected", w.Body.String())
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Copies of templates reused between requests, and used by concurrent requests, are
+	//bound to the request being rendered only.
+	var wg sync.WaitGroup
+	errs := make(chan string, 50)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			host := "host" + strconv.Itoa(i) + ".example.com"
+			r := httptest.NewRequest(http.MethodGet, "http://"+host+"/", nil)
+			w := httptest.NewRecorder()
+			c.ShowRequest(w, r, "help", "absolute", nil)
+			if strings.TrimSpace(w.Body.String()) != "http://"+host+"/help/absolute" {
+				errs <- w.Body.String()
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for out := range errs {
+		t.Fatal("URL not built for the request being rendered", out)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Func works without a request.
+	w := httptest.NewRecorder()
+	c.Show(w, "help", "absolute", nil)
+	if strings.TrimSpace(w.Body.String()) != "/help/absolute" {
+		t.Fatal("Path not returned as expected", w.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Subdirectories not using request-scoped funcs are not copied.
+	if _, ok := c.requestTemplates["app"]; ok {
+		t.Fatal("Templates not using request-scoped funcs should not be pooled")
+		return
+	}
+	if _, ok := c.requestTemplates["help"]; !ok {
+		t.Fatal("Templates using request-scoped funcs should be pooled")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestFuncSrcSet(t *testing.T) {
+	if s := FuncSrcSet("/img/hero.jpg", 480, 0, 800); s != "/img/hero-480w.jpg 480w, /img/hero-800w.jpg 800w" {
+		t.Fatal("Srcset not returned as expected", s)
+		return
+	}
+
+	pairs := map[string]string{"hero-800w.jpg": "A1B2C3.hero-800w.jpg"}
+	if s := srcSet("/img/hero.jpg", []int{480, 800}, pairs); s != "/img/hero-480w.jpg 480w, /img/A1B2C3.hero-800w.jpg 800w" {
+		t.Fatal("Cache busting filename not used as expected", s)
+		return
+	}
+}
@@ -21,6 +21,7 @@ For more info, see https://pkg.go.dev/text/template#hdr-Functions
 package templates
 
 import (
+	"errors"
 	"log"
 	"strings"
 	"time"
@@ -65,3 +66,75 @@ func FuncAddInt(x interface{}, y int) (z int) {
 		return 0
 	}
 }
+
+//dateFormat is the format of dates provided as strings to the date funcs.
+const dateFormat = "2006-01-02"
+
+//FuncDateAdd adds a number of days, which may be negative, to a date in templates. The
+//date can be a time.Time or a string in yyyy-mm-dd format, or "today" for the current
+//date. The result is the same type as provided, a yyyy-mm-dd string for strings, so it
+//can be used with FuncDateReformat, i.e. {{dateReformat (dateAdd .Start 30) "Jan 2"}}.
+func FuncDateAdd(date interface{}, days int) interface{} {
+	if t, ok := date.(time.Time); ok {
+		return t.AddDate(0, 0, days)
+	}
+
+	t, err := parseDate(date)
+	if err != nil {
+		//just return original value if error occurs
+		log.Println("templates.FuncDateAdd", err)
+		return date
+	}
+
+	return t.AddDate(0, 0, days).Format(dateFormat)
+}
+
+//FuncDateDiff returns the number of days from one date to another in templates, i.e.
+//"expires in {{dateDiff "today" .Expires}} days". The result is negative if to is before
+//from. Dates can be a time.Time or a string in yyyy-mm-dd format, or "today" for the
+//current date. Only the calendar date is used, the time of day is ignored.
+func FuncDateDiff(from, to interface{}) int {
+	f, err := parseDate(from)
+	if err != nil {
+		log.Println("templates.FuncDateDiff", err)
+		return 0
+	}
+	t, err := parseDate(to)
+	if err != nil {
+		log.Println("templates.FuncDateDiff", err)
+		return 0
+	}
+
+	//Compare calendar dates in UTC so that time zones and daylight saving time do not
+	//affect the number of days.
+	fy, fm, fd := f.Date()
+	ty, tm, td := t.Date()
+	fromDate := time.Date(fy, fm, fd, 0, 0, 0, 0, time.UTC)
+	toDate := time.Date(ty, tm, td, 0, 0, 0, 0, time.UTC)
+
+	return int(toDate.Sub(fromDate).Hours() / 24)
+}
+
+//errInvalidDate is returned when a date provided to a date func is not a time.Time or a
+//string in yyyy-mm-dd format.
+var errInvalidDate = errors.New("templates: invalid date, must be a time.Time or yyyy-mm-dd")
+
+//parseDate returns a date provided to a date func as a time.Time.
+func parseDate(date interface{}) (time.Time, error) {
+	switch d := date.(type) {
+	case time.Time:
+		return d, nil
+	case string:
+		if strings.EqualFold(strings.TrimSpace(d), "today") {
+			return time.Now(), nil
+		}
+
+		t, err := time.Parse(dateFormat, strings.TrimSpace(d))
+		if err != nil {
+			return time.Time{}, errInvalidDate
+		}
+		return t, nil
+	default:
+		return time.Time{}, errInvalidDate
+	}
+}
@@ -1,6 +1,9 @@
 package templates
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func TestFuncIndexOf(t *testing.T) {
 	haystack := "asdfghjkl"
@@ -51,3 +54,60 @@ func TestFuncAddInt(t *testing.T) {
 		return
 	}
 }
+
+func TestFuncDateAdd(t *testing.T) {
+	//string dates return string dates
+	if d := FuncDateAdd("2020-02-27", 3); d != "2020-03-01" {
+		t.Fatal("date not added correctly", d)
+		return
+	}
+	if d := FuncDateAdd("2020-01-01", -1); d != "2019-12-31" {
+		t.Fatal("date not added correctly", d)
+		return
+	}
+
+	//time.Time dates return time.Time dates
+	start := time.Date(2020, 1, 31, 12, 0, 0, 0, time.UTC)
+	if d, ok := FuncDateAdd(start, 1).(time.Time); !ok || !d.Equal(time.Date(2020, 2, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Fatal("date not added correctly", d)
+		return
+	}
+
+	//input date was bad
+	if d := FuncDateAdd("2020-01-32", 1); d != "2020-01-32" {
+		t.Fatal("date should have been returned as is due to input date issue", d)
+		return
+	}
+}
+
+func TestFuncDateDiff(t *testing.T) {
+	if n := FuncDateDiff("2020-02-27", "2020-03-01"); n != 3 {
+		t.Fatal("days between dates not correct", n)
+		return
+	}
+	if n := FuncDateDiff("2020-03-01", "2020-02-27"); n != -3 {
+		t.Fatal("days between dates not correct", n)
+		return
+	}
+
+	//time of day is ignored
+	from := time.Date(2020, 1, 1, 23, 0, 0, 0, time.UTC)
+	to := time.Date(2020, 1, 2, 1, 0, 0, 0, time.UTC)
+	if n := FuncDateDiff(from, to); n != 1 {
+		t.Fatal("days between dates not correct", n)
+		return
+	}
+
+	//today
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+	if n := FuncDateDiff("today", tomorrow); n != 1 {
+		t.Fatal("days between dates not correct", n)
+		return
+	}
+
+	//input date was bad
+	if n := FuncDateDiff(5, "2020-01-01"); n != 0 {
+		t.Fatal("0 should have been returned due to input date issue", n)
+		return
+	}
+}
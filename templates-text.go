@@ -0,0 +1,240 @@
+/*
+This file handles rendering templates as text, rather than HTML, using the golang
+'text/template' package. This is needed for output that isn't HTML, such as RSS or Atom
+feeds, CSV exports, or calendar invites, where the contextual escaping performed by
+'html/template' would mangle the output.
+
+Text templates are parsed from the same files as HTML templates, using the same
+subdirectories and inheritance, so no extra configuration is needed. Text templates
+are parsed the first time a template from a subdirectory is rendered as text and are
+cached until templates are rebuilt.
+
+Note that no escaping is performed when rendering text templates. You must escape
+values yourself, using funcs such as xmlEscape or cdata, as needed for your output.
+*/
+
+package templates
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	texttemplate "text/template"
+	"text/template/parse"
+)
+
+//Content types for text output.
+const (
+	ContentTypeRSS  = "application/rss+xml; charset=utf-8"
+	ContentTypeAtom = "application/atom+xml; charset=utf-8"
+	ContentTypeXML  = "application/xml; charset=utf-8"
+	ContentTypeText = "text/plain; charset=utf-8"
+	ContentTypeCSV  = "text/csv; charset=utf-8"
+	ContentTypeTSV  = "text/tab-separated-values; charset=utf-8"
+	ContentTypeICS  = "text/calendar; charset=utf-8"
+)
+
+//textFuncMap returns the funcs that are always available to templates. These funcs are
+//needed for rendering text templates but, since text templates are parsed from the same
+//files as HTML templates, they must be available when parsing HTML templates as well.
+func textFuncMap() texttemplate.FuncMap {
+	return texttemplate.FuncMap{
+		"rfc3339":   FuncRFC3339,
+		"rfc1123z":  FuncRFC1123Z,
+		"cdata":     FuncCDATA,
+		"xmlEscape": FuncXMLEscape,
+		"csvEscape": FuncCSVEscape,
+		"tsvEscape": FuncTSVEscape,
+
+		"icsEscape":   FuncICSEscape,
+		"icsDateTime": FuncICSDateTime,
+		"icsDate":     FuncICSDate,
+	}
+}
+
+//parseTextFiles reads and parses each file at the provided paths into a single text
+//template. This works the same as parseFiles() but for text templates.
+func (c *Config) parseTextFiles(paths []string) (t *texttemplate.Template, err error) {
+	t = c.bindTextTryTemplate(texttemplate.New("").Funcs(c.funcMap()))
+
+	for _, p := range paths {
+		b, innerErr := c.readFile(p)
+		if innerErr != nil {
+			return nil, innerErr
+		}
+
+		b, innerErr = c.transform(p, b)
+		if innerErr != nil {
+			return nil, innerErr
+		}
+
+		//Skip files excluded by their build directive.
+		b, include := c.applyBuildDirectives(b)
+		if include {
+			_, innerErr = t.New(filepath.Base(p)).Parse(c.source(b))
+			if innerErr != nil {
+				return nil, innerErr
+			}
+		}
+	}
+
+	trees := make(map[string]*parse.Tree)
+	for _, tmpl := range t.Templates() {
+		trees[tmpl.Name()] = tmpl.Tree
+	}
+	err = c.checkIncludeDepth(trees)
+	if err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+//lookupText returns the text templates for a subdirectory, parsing them if they have
+//not already been parsed.
+func (c *Config) lookupText(subdir string) (t *texttemplate.Template, err error) {
+	if c.mu == nil {
+		return nil, ErrUnknownSubDir
+	}
+
+	c.mu.RLock()
+	t, ok := c.textTemplates[subdir]
+	paths, built := c.files[subdir]
+	generation := c.generation
+	c.mu.RUnlock()
+	if ok {
+		return
+	}
+	if !built {
+		return nil, ErrUnknownSubDir
+	}
+
+	t, err = c.parseTextFiles(paths)
+	if err != nil {
+		log.Println("templates.lookupText", "error parsing text files at subdir '"+subdir+"'", err)
+		return
+	}
+
+	//Cache the parsed templates. The templates may have been modified while parsing so
+	//only cache the parsed templates if they match the current files.
+	c.mu.Lock()
+	if c.generation == generation {
+		if c.textTemplates == nil {
+			c.textTemplates = make(map[string]*texttemplate.Template)
+		}
+		c.textTemplates[subdir] = t
+	}
+	c.mu.Unlock()
+
+	return
+}
+
+//RenderText renders a template as text to w. This works the same as Show() but the
+//template is rendered using the 'text/template' package so that no HTML escaping is
+//performed. This is used for rendering output that is not HTML, such as feeds. An error
+//is returned if the subdirectory or template cannot be found or if an error occurs
+//while executing the template.
+func (c *Config) RenderText(w io.Writer, subdir, templateName string, injectedData interface{}) (err error) {
+	//Add the extension to the template name if needed, the same as Show().
+	if filepath.Ext(templateName) == "" {
+		templateName += "." + c.Extension
+	}
+
+	t, err := c.lookupText(subdir)
+	if err != nil {
+		return
+	}
+	if t.Lookup(templateName) == nil {
+		return ErrTemplateNotFound
+	}
+
+	err = c.validateData(subdir, templateName, injectedData)
+	if err != nil {
+		return
+	}
+
+	release, err := c.acquireRender(context.Background())
+	if err != nil {
+		return
+	}
+	defer release()
+
+	v, _, releaseView := c.acquireView(subdir)
+	defer releaseView()
+
+	data := c.renderData(v, subdir, "", injectedData)
+
+	return t.ExecuteTemplate(c.limitOutput(w), templateName, c.templateData(&data))
+}
+
+//RenderText renders a template as text using the default package level config.
+func RenderText(w io.Writer, subdir, templateName string, injectedData interface{}) (err error) {
+	return Default().RenderText(w, subdir, templateName, injectedData)
+}
+
+//ShowText renders a template as text and returns it to the user's browser with the
+//provided content type, for example ContentTypeRSS. The template is rendered fully
+//before anything is written so that an error response can be returned if an error
+//occurs.
+func (c *Config) ShowText(w http.ResponseWriter, contentType, subdir, templateName string, injectedData interface{}) {
+	c.showText(w, contentType, subdir, templateName, injectedData, nil)
+}
+
+//showText handles rendering a template as text for ShowText() and similar funcs. If
+//transform is provided, the rendered output is passed through it before being written.
+func (c *Config) showText(w http.ResponseWriter, contentType, subdir, templateName string, injectedData interface{}, transform func([]byte) []byte) {
+	var b bytes.Buffer
+	err := c.RenderText(&b, subdir, templateName, injectedData)
+	if err != nil {
+		c.writeError(w, "", c.errorStatus(err), "templates.ShowText: error during execute", err)
+		return
+	}
+
+	out := b.Bytes()
+	if transform != nil {
+		out = transform(out)
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	c.setHeaders(w, subdir, templateName)
+	w.Write(out)
+}
+
+//ShowText renders a template as text and returns it to the user's browser using the
+//default package level config.
+func ShowText(w http.ResponseWriter, contentType, subdir, templateName string, injectedData interface{}) {
+	Default().ShowText(w, contentType, subdir, templateName, injectedData)
+}
+
+//RenderCSV renders a template as text and returns it to the user's browser as a CSV
+//file. This is the same as ShowText() with the content type for CSV. Use the csvEscape
+//func in your template to escape each value. To have the browser download the file,
+//set the Content-Disposition header prior to calling this func.
+func (c *Config) RenderCSV(w http.ResponseWriter, subdir, templateName string, injectedData interface{}) {
+	c.ShowText(w, ContentTypeCSV, subdir, templateName, injectedData)
+}
+
+//RenderCSV renders a template as a CSV file using the default package level config.
+func RenderCSV(w http.ResponseWriter, subdir, templateName string, injectedData interface{}) {
+	Default().RenderCSV(w, subdir, templateName, injectedData)
+}
+
+//ShowICS renders a template as text and returns it to the user's browser as an
+//iCalendar (.ics) file. The rendered output has its line endings converted to CRLF and
+//long lines folded, as required by RFC 5545, so your template does not need to handle
+//either. Use the icsEscape, icsDateTime, and icsDate funcs in your template to format
+//each value.
+func (c *Config) ShowICS(w http.ResponseWriter, subdir, templateName string, injectedData interface{}) {
+	c.showText(w, ContentTypeICS, subdir, templateName, injectedData, func(b []byte) []byte {
+		return []byte(FoldICS(string(b)))
+	})
+}
+
+//ShowICS renders a template as an iCalendar file using the default package level
+//config.
+func ShowICS(w http.ResponseWriter, subdir, templateName string, injectedData interface{}) {
+	Default().ShowICS(w, subdir, templateName, injectedData)
+}
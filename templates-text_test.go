@@ -0,0 +1,176 @@
+package templates
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderText(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	subdirs := []string{"app", "help", "feeds"}
+	c := NewOnDiskConfig(base, subdirs)
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	data := struct {
+		Title       string
+		Description string
+		Date        time.Time
+	}{
+		Title:       "News & Updates",
+		Description: "<p>Latest</p>",
+		Date:        time.Date(2021, 11, 21, 0, 0, 0, 0, time.UTC),
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Render text without HTML escaping.
+	b := bytes.Buffer{}
+	err = c.RenderText(&b, "feeds", "rss", data)
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	out := b.String()
+	if !strings.HasPrefix(out, `<?xml version="1.0" encoding="UTF-8"?>`) {
+		t.Fatal("XML declaration was mangled", out)
+		return
+	}
+	if !strings.Contains(out, "<title>News &amp; Updates</title>") {
+		t.Fatal("Title not escaped as expected", out)
+		return
+	}
+	if !strings.Contains(out, "<![CDATA[<p>Latest</p>]]>") {
+		t.Fatal("CDATA not output as expected", out)
+		return
+	}
+	if !strings.Contains(out, "<pubDate>Sun, 21 Nov 2021 00:00:00 +0000</pubDate>") {
+		t.Fatal("Date not formatted as expected", out)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Missing template and subdirectory.
+	err = c.RenderText(&b, "feeds", "non-existant-template", data)
+	if err != ErrTemplateNotFound {
+		t.Fatal("ErrTemplateNotFound should have occured but didn't", err)
+		return
+	}
+	err = c.RenderText(&b, "non-existant-subdir", "rss", data)
+	if err != ErrUnknownSubDir {
+		t.Fatal("ErrUnknownSubDir should have occured but didn't", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Show text with content type.
+	w := httptest.NewRecorder()
+	c.ShowText(w, ContentTypeRSS, "feeds", "rss", data)
+	if w.Code != http.StatusOK {
+		t.Fatal("Error showing", w.Code, w.Body)
+		return
+	}
+	if w.Header().Get("Content-Type") != ContentTypeRSS {
+		t.Fatal("Content type not set as expected", w.Header().Get("Content-Type"))
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestRenderCSV(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	subdirs := []string{"exports"}
+	c := NewOnDiskConfig(base, subdirs)
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	data := []struct {
+		Name string
+		Note string
+	}{
+		{"Alice & Bob", `says "hi", then leaves`},
+	}
+
+	w := httptest.NewRecorder()
+	c.RenderCSV(w, "exports", "users", data)
+	if w.Code != http.StatusOK {
+		t.Fatal("Error showing", w.Code, w.Body)
+		return
+	}
+	if w.Header().Get("Content-Type") != ContentTypeCSV {
+		t.Fatal("Content type not set as expected", w.Header().Get("Content-Type"))
+		return
+	}
+	if !strings.Contains(w.Body.String(), `Alice & Bob,"says ""hi"", then leaves"`) {
+		t.Fatal("CSV not rendered as expected", w.Body.String())
+		return
+	}
+}
+
+func TestShowICS(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	subdirs := []string{"feeds"}
+	c := NewOnDiskConfig(base, subdirs)
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	data := struct {
+		UID     string
+		Start   time.Time
+		Summary string
+	}{
+		UID:     "1@example.com",
+		Start:   time.Date(2021, 11, 21, 15, 0, 0, 0, time.UTC),
+		Summary: "Meeting, with team",
+	}
+
+	w := httptest.NewRecorder()
+	c.ShowICS(w, "feeds", "event", data)
+	if w.Code != http.StatusOK {
+		t.Fatal("Error showing", w.Code, w.Body)
+		return
+	}
+	if w.Header().Get("Content-Type") != ContentTypeICS {
+		t.Fatal("Content type not set as expected", w.Header().Get("Content-Type"))
+		return
+	}
+	out := w.Body.String()
+	if !strings.Contains(out, "DTSTART:20211121T150000Z\r\nSUMMARY:Meeting\\, with team\r\n") {
+		t.Fatal("ICS not rendered as expected", out)
+		return
+	}
+}
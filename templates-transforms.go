@@ -0,0 +1,34 @@
+/*
+This file handles transforming the source of template files before they are parsed,
+using the SourceTransforms set in your config. This allows for custom preprocessing of
+your templates, such as inserting compile-time constants, stripping blocks only used in
+development, or expanding your own shorthand syntax into template actions.
+
+Transforms are applied to each file, in the order provided, each time the file is
+parsed, as HTML or as text. Transforms are applied before TrimBlocks. Transforms are
+not applied to the contrib partials provided by this package.
+*/
+
+package templates
+
+import (
+	"fmt"
+)
+
+//transform returns the source of the file at path with each of the config's
+//SourceTransforms applied.
+func (c *Config) transform(path string, b []byte) ([]byte, error) {
+	for _, fn := range c.SourceTransforms {
+		if fn == nil {
+			continue
+		}
+
+		transformed, err := fn(path, b)
+		if err != nil {
+			return nil, fmt.Errorf("templates: error transforming '%s': %w", path, err)
+		}
+		b = transformed
+	}
+
+	return b, nil
+}
@@ -0,0 +1,91 @@
+package templates
+
+import (
+	"bytes"
+	"errors"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSourceTransforms(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	subdirs := []string{"transforms"}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Transforms are applied in order.
+	var paths []string
+	c := NewOnDiskConfig(base, subdirs)
+	c.SourceTransforms = []func(string, []byte) ([]byte, error){
+		func(path string, src []byte) ([]byte, error) {
+			paths = append(paths, filepath.Base(path))
+			return bytes.ReplaceAll(src, []byte("@@VERSION@@"), []byte("{{.AppVersion}}")), nil
+		},
+		func(path string, src []byte) ([]byte, error) {
+			return bytes.ReplaceAll(src, []byte("version"), []byte("v")), nil
+		},
+	}
+	c.AppVersion = "1.2.3"
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	w := httptest.NewRecorder()
+	c.Show(w, "transforms", "const", nil)
+	if strings.TrimSpace(w.Body.String()) != "v 1.2.3" {
+		t.Fatal("Source not transformed as expected", w.Body.String())
+		return
+	}
+
+	found := false
+	for _, p := range paths {
+		if p == "const.html" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatal("Transform not provided the file's path", paths)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Text templates are transformed too.
+	var b bytes.Buffer
+	err = c.RenderText(&b, "transforms", "const", nil)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if strings.TrimSpace(b.String()) != "v 1.2.3" {
+		t.Fatal("Text source not transformed as expected", b.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//An error stops building.
+	errTransform := errors.New("bad source")
+	c = NewOnDiskConfig(base, subdirs)
+	c.SourceTransforms = []func(string, []byte) ([]byte, error){
+		func(path string, src []byte) ([]byte, error) {
+			return nil, errTransform
+		},
+	}
+	err = c.Build()
+	if !errors.Is(err, errTransform) {
+		t.Fatal("Error should have been returned", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,149 @@
+/*
+This file handles removing the blank lines and indentation left in the output of
+templates by control structures such as {{if}} and {{range}}. Since an action on its own
+line is surrounded by the line's indentation and newline, which are written to the
+output, templates written to be readable result in output with many blank or oddly
+indented lines. Removing these otherwise requires littering templates with {{- -}}.
+
+When TrimBlocks is set, each line that only contains control structures, comments, or
+variable declarations, which do not write anything to the output, has its indentation
+and trailing newline removed before the template is parsed. This is similar to the
+trim_blocks and lstrip_blocks options in Jinja.
+*/
+
+package templates
+
+import (
+	"regexp"
+	"strings"
+)
+
+//trimBlocksKeywords are the keywords that start actions which do not write anything to
+//the output.
+var trimBlocksKeywords = map[string]bool{
+	"if":       true,
+	"else":     true,
+	"end":      true,
+	"range":    true,
+	"with":     true,
+	"define":   true,
+	"block":    true,
+	"break":    true,
+	"continue": true,
+}
+
+//variableDeclaration matches an action that declares or assigns a variable.
+var variableDeclaration = regexp.MustCompile(`^\$\w*\s*:?=`)
+
+//source returns the source of a template file to parse, with blocks trimmed if needed.
+func (c *Config) source(b []byte) string {
+	if !c.TrimBlocks {
+		return string(b)
+	}
+
+	return trimBlocks(string(b))
+}
+
+//trimBlocks removes the indentation and trailing newline of each line that only
+//contains actions that do not write anything to the output.
+func trimBlocks(src string) string {
+	var b strings.Builder
+	b.Grow(len(src))
+
+	for _, line := range strings.SplitAfter(src, "\n") {
+		trimmed := strings.Trim(line, " \t\r\n")
+		if isControlLine(trimmed) {
+			b.WriteString(trimmed)
+			continue
+		}
+
+		b.WriteString(line)
+	}
+
+	return b.String()
+}
+
+//isControlLine returns if s only contains actions that do not write anything to the
+//output.
+func isControlLine(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for s != "" {
+		if !strings.HasPrefix(s, "{{") {
+			return false
+		}
+
+		end := actionEnd(s[2:])
+		if end == -1 {
+			return false
+		}
+		action := s[2 : 2+end]
+		s = strings.TrimLeft(s[2+end+2:], " \t")
+
+		//Remove whitespace trimming markers.
+		if len(action) >= 2 && action[0] == '-' && isTemplateSpace(action[1]) {
+			action = action[1:]
+		}
+		if len(action) >= 2 && action[len(action)-1] == '-' && isTemplateSpace(action[len(action)-2]) {
+			action = action[:len(action)-1]
+		}
+		action = strings.TrimSpace(action)
+
+		if strings.HasPrefix(action, "/*") || variableDeclaration.MatchString(action) {
+			continue
+		}
+
+		keyword := action
+		if i := strings.IndexAny(action, " \t\r\n("); i != -1 {
+			keyword = action[:i]
+		}
+		if !trimBlocksKeywords[keyword] {
+			return false
+		}
+	}
+
+	return true
+}
+
+//actionEnd returns the index of the }} that ends an action, skipping over strings and
+//comments in the action. -1 is returned if the action does not end.
+func actionEnd(s string) int {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"', '\'':
+			quote := s[i]
+			for i++; i < len(s) && s[i] != quote; i++ {
+				if s[i] == '\\' {
+					i++
+				}
+			}
+		case '`':
+			end := strings.IndexByte(s[i+1:], '`')
+			if end == -1 {
+				return -1
+			}
+			i += end + 1
+		case '/':
+			if strings.HasPrefix(s[i:], "/*") {
+				end := strings.Index(s[i+2:], "*/")
+				if end == -1 {
+					return -1
+				}
+				i += end + 3
+			}
+		case '}':
+			if strings.HasPrefix(s[i:], "}}") {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+//isTemplateSpace returns if c is whitespace as defined by the template package.
+func isTemplateSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
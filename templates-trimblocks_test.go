@@ -0,0 +1,71 @@
+package templates
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTrimBlocks(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	subdirs := []string{"trim"}
+	c := NewOnDiskConfig(base, subdirs)
+	c.TrimBlocks = true
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Lines with only control structures removed, other lines kept.
+	w := httptest.NewRecorder()
+	c.Show(w, "trim", "list", []string{"a", "b"})
+	expected := "<ul>\n    <li>a</li>\n    <li>b</li>\n</ul>\n<p>inline</p>\n"
+	if w.Body.String() != expected {
+		t.Fatalf("Blocks not trimmed as expected %q", w.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestIsControlLine(t *testing.T) {
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	tests := map[string]bool{
+		"{{if .A}}":                      true,
+		"{{- else if .B -}}":             true,
+		"{{end}}{{end}}":                 true,
+		"{{range $i, $v := .List}}":      true,
+		"{{/* comment }} */}}":           true,
+		"{{$x := 1}}":                    true,
+		`{{define "x"}}`:                 true,
+		"":                               false,
+		"{{.Name}}":                      false,
+		`{{template "header" .}}`:        false,
+		"{{if .A}}<p>":                   false,
+		"{{$x}}":                         false,
+		`{{if eq .A "}}"}}`:              true,
+		"{{ifFunc .A}}":                  false,
+		"{{if .A}} {{else}}":             true,
+		"{{end}}{{.Name}}":               false,
+		"{{if .A}":                       false,
+		"{{end}} text":                   false,
+		"{{/* unterminated comment }}":   false,
+		"{{$x = 2}}{{- /* a */ -}}":      true,
+		"{{with .A}}{{$y := .B}}{{end}}": true,
+	}
+	for line, expected := range tests {
+		if isControlLine(line) != expected {
+			t.Fatal("Control line not determined as expected", line, expected)
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
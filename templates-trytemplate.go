@@ -0,0 +1,148 @@
+/*
+This file handles the tryTemplate func, used to render a partial that may fail without
+failing the whole page. This is useful for sections of a page that can degrade, such as
+a widget whose data could not be retrieved, where showing the rest of the page is
+better than showing an error.
+	{{tryTemplate "weather-widget" .InjectedData.Weather}}
+	{{tryTemplate "weather-widget" .InjectedData.Weather "weather-unavailable"}}
+
+If rendering the partial returns an error, or panics, the error is logged and the
+optional fallback template is rendered instead, with the same data. If no fallback is
+provided, or the fallback also fails, nothing is rendered. The partial is rendered
+fully before its output is used so that a failed partial never results in partial
+output.
+
+Since each partial is rendered separately, the limit on nesting templates that Go
+enforces for a single render does not apply to partials. A partial that renders itself
+with tryTemplate, directly or via other templates, would recurse until the program
+crashes. To prevent this, tryTemplate returns ErrTryTemplateDepth, failing the render,
+once partials are nested too deeply. The fallback template is not rendered in this case
+since it would likely recurse as well.
+
+Since tryTemplate renders a template from the same set of templates being rendered,
+the func is bound to each set of templates, including copies made for request-scoped
+funcs and templates rendered as text, rather than being in DefaultFuncMap(). A func
+named tryTemplate in your FuncMap replaces this func. Untrusted templates, see
+ParseUntrusted(), cannot use tryTemplate since the templates it renders cannot be
+checked.
+*/
+
+package templates
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"log"
+	"runtime"
+	texttemplate "text/template"
+)
+
+//tryTemplateFunc is the name of the tryTemplate func in templates.
+const tryTemplateFunc = "tryTemplate"
+
+//maxTryTemplateFrames is the depth of the call stack past which tryTemplate no longer
+//renders partials. The depth of the call stack is used, rather than counting nested
+//calls to tryTemplate, since the same set of templates is rendered by many requests at
+//once and the depth of each render must be tracked separately. This allows for
+//partials to be nested far more deeply than any real page needs.
+const maxTryTemplateFrames = 10000
+
+//ErrTryTemplateDepth is returned when partials rendered with tryTemplate are nested too
+//deeply, typically because a partial renders itself.
+var ErrTryTemplateDepth = errors.New("templates: tryTemplate nested too deeply, templates may render each other recursively")
+
+//templateExecutor is a set of HTML or text templates.
+type templateExecutor interface {
+	ExecuteTemplate(w io.Writer, name string, data interface{}) error
+}
+
+//bindTryTemplate sets the tryTemplate func on t so that it renders templates from t. t
+//is returned for chaining. This must be called for each copy of a set of templates since
+//a copy would otherwise render templates from the set it was copied from.
+func (c *Config) bindTryTemplate(t *template.Template) *template.Template {
+	if _, ok := c.FuncMap[tryTemplateFunc]; ok {
+		return t
+	}
+
+	return t.Funcs(template.FuncMap{
+		tryTemplateFunc: func(name string, data interface{}, fallback ...string) (template.HTML, error) {
+			out, err := tryTemplate(t, name, data, fallback...)
+			return template.HTML(out), err
+		},
+	})
+}
+
+//bindTextTryTemplate sets the tryTemplate func on a set of text templates, the same as
+//bindTryTemplate().
+func (c *Config) bindTextTryTemplate(t *texttemplate.Template) *texttemplate.Template {
+	if _, ok := c.FuncMap[tryTemplateFunc]; ok {
+		return t
+	}
+
+	return t.Funcs(texttemplate.FuncMap{
+		tryTemplateFunc: func(name string, data interface{}, fallback ...string) (string, error) {
+			return tryTemplate(t, name, data, fallback...)
+		},
+	})
+}
+
+//tryTemplate renders the template name from t, or the first fallback template if
+//rendering fails. Errors are logged and a blank string is returned if no template could
+//be rendered. An error is only returned if partials are nested too deeply.
+func tryTemplate(t templateExecutor, name string, data interface{}, fallback ...string) (string, error) {
+	if nestedTooDeeply() {
+		log.Println("templates.tryTemplate", "not rendering template '"+name+"'", ErrTryTemplateDepth)
+		return "", ErrTryTemplateDepth
+	}
+
+	b, err := executeRecover(t, name, data)
+	if err == nil {
+		return string(b), nil
+	}
+	if errors.Is(err, ErrTryTemplateDepth) {
+		return "", err
+	}
+	log.Println("templates.tryTemplate", "error rendering template '"+name+"'", err)
+
+	if len(fallback) == 0 || fallback[0] == "" {
+		return "", nil
+	}
+
+	b, err = executeRecover(t, fallback[0], data)
+	if errors.Is(err, ErrTryTemplateDepth) {
+		return "", err
+	}
+	if err != nil {
+		log.Println("templates.tryTemplate", "error rendering fallback template '"+fallback[0]+"'", err)
+		return "", nil
+	}
+
+	return string(b), nil
+}
+
+//nestedTooDeeply returns if the call stack is deeper than maxTryTemplateFrames. Only
+//the frames past the limit are requested so that checking is cheap.
+func nestedTooDeeply() bool {
+	var pc [1]uintptr
+	return runtime.Callers(maxTryTemplateFrames, pc[:]) > 0
+}
+
+//executeRecover renders a template from t, returning an error if rendering panics.
+func executeRecover(t templateExecutor, name string, data interface{}) (b []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	var buf bytes.Buffer
+	err = t.ExecuteTemplate(&buf, name, data)
+	if err != nil {
+		return
+	}
+
+	return buf.Bytes(), nil
+}
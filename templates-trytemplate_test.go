@@ -0,0 +1,113 @@
+package templates
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+type tryWeather struct {
+	Temp int
+}
+
+type tryData struct {
+	Weather *tryWeather
+}
+
+func TestTryTemplate(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	c := NewOnDiskConfig(base, []string{"try"})
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	w := httptest.NewRecorder()
+	c.Show(w, "try", "page", tryData{Weather: &tryWeather{Temp: 21}})
+	if strings.TrimSpace(w.Body.String()) != "<main><b>21</b>|<b>21</b></main>" {
+		t.Fatal("Partial not rendered as expected", w.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Partial fails, the page is still shown.
+	w = httptest.NewRecorder()
+	c.Show(w, "try", "page", tryData{})
+	if w.Code != 200 || strings.TrimSpace(w.Body.String()) != "<main><i>Unavailable</i>|</main>" {
+		t.Fatal("Fallback not rendered as expected", w.Code, w.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Rendered as text.
+	var b bytes.Buffer
+	err = c.RenderText(&b, "try", "page", tryData{})
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	if strings.TrimSpace(b.String()) != "<main><i>Unavailable</i>|</main>" {
+		t.Fatal("Fallback not rendered as expected", b.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Panics are recovered.
+	panicky := panickyExecutor{}
+	if out, _ := tryTemplate(panicky, "x", nil); out != "" {
+		t.Fatal("Output should have been blank", out)
+		return
+	}
+
+	//Partials that render themselves fail the render rather than recursing forever.
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	loop := NewFSConfig(fstest.MapFS{
+		"tpl/app/loop.html": {Data: []byte(`{{define "loop"}}{{tryTemplate "loop" . "loop"}}{{end}}{{tryTemplate "loop" .}}`)},
+	}, "tpl", []string{"app"})
+	err = loop.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+	err = loop.RenderToWriter(io.Discard, "app", "loop", nil)
+	if !errors.Is(err, ErrTryTemplateDepth) {
+		t.Fatal("ErrTryTemplateDepth should have occured but didn't", err)
+		return
+	}
+
+	//Untrusted templates cannot use tryTemplate.
+	p := UntrustedPolicy{Namespace: "tenant-1/", AllowedFuncs: []string{"tryTemplate"}}
+	err = CheckUntrusted("tenant-1/x", `{{tryTemplate "secret" .}}`, p)
+	if !errors.Is(err, ErrUntrustedTemplate) {
+		t.Fatal("ErrUntrustedTemplate should have occured but didn't", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+//panickyExecutor is a set of templates that panics when rendered.
+type panickyExecutor struct{}
+
+func (panickyExecutor) ExecuteTemplate(w io.Writer, name string, data interface{}) error {
+	panic("boom")
+}
@@ -0,0 +1,555 @@
+/*
+This file handles templates written by untrusted authors, such as apps where each tenant
+can edit their own templates that are stored in a database. Since templates can call any
+func in your config's FuncMap and include any other template, a template written by an
+untrusted author is checked against an UntrustedPolicy before it is accepted.
+
+A template is rejected if it:
+  - calls a func that is not a safe builtin func or listed in AllowedFuncs.
+  - includes, via {{template}}, a template outside of the policy's Namespace that is not
+    listed in AllowedTemplates.
+  - is named, or defines a template via {{define}} or {{block}}, outside of the policy's
+    Namespace. This prevents replacing templates used by your app or other tenants.
+
+Note that fields and methods of the data provided to a template can still be used, so
+only provide data to untrusted templates that the author is allowed to see.
+
+A template that passes its policy can still be costly to render, for example by ranging
+over large data many times. The policy's Limits are applied each time an untrusted
+template, or a template it defines, is shown so that one author's template cannot
+degrade your app for everyone else. Limits are checked each time output is written and
+each time a {{range}} starts; a render that exceeds a limit is stopped and an error
+response is returned. Untrusted templates are always rendered fully before anything is
+written and their output is never cached.
+*/
+
+package templates
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"log"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template/parse"
+	"time"
+)
+
+//Errors returned for untrusted templates.
+var (
+	//ErrUntrustedTemplate is returned when a template provided to ParseUntrusted() does
+	//not pass its UntrustedPolicy. The returned error wraps this error and describes
+	//why.
+	ErrUntrustedTemplate = errors.New("templates: untrusted template not allowed")
+
+	//ErrUntrustedTimeout is returned when rendering an untrusted template takes longer
+	//than allowed by its limits.
+	ErrUntrustedTimeout = errors.New("templates: untrusted template exceeded render timeout")
+
+	//ErrNoPreviousVersion is returned when RollbackUntrusted() is called for a template
+	//that has not been replaced.
+	ErrNoPreviousVersion = errors.New("templates: no previous version of template to roll back to")
+
+	//ErrMaxRangeIterations is returned when an untrusted template ranges over more
+	//items than allowed by its limits.
+	ErrMaxRangeIterations = errors.New("templates: untrusted template exceeded max range iterations")
+)
+
+//rangeGuardFunc is the name of the func added to the end of the pipeline of each
+//{{range}} in an untrusted template to enforce limits. The leading underscore keeps the
+//name from clashing with funcs in your FuncMap.
+const rangeGuardFunc = "_untrustedRange"
+
+//safeBuiltinFuncs are the funcs built into golang templates that untrusted templates can
+//always use. The call func is not included since it calls any func provided in data.
+var safeBuiltinFuncs = []string{
+	"and", "or", "not",
+	"eq", "ne", "lt", "le", "gt", "ge",
+	"len", "index", "slice",
+	"print", "printf", "println",
+	"html", "js", "urlquery",
+}
+
+//UntrustedPolicy is the set of rules a template written by an untrusted author must
+//follow to be accepted by ParseUntrusted().
+type UntrustedPolicy struct {
+	//Namespace is the prefix the names of the template, and any templates it defines,
+	//must start with, i.e. "tenant-42/". Templates in the namespace can also be
+	//included. This is typically unique per tenant. A blank namespace is not allowed.
+	Namespace string
+
+	//AllowedFuncs is the list of funcs, other than the safe builtin funcs such as eq and
+	//len, the template can call. Funcs must still exist in the config's FuncMap. The
+	//tryTemplate func is never allowed since the templates it renders are not checked.
+	AllowedFuncs []string
+
+	//AllowedTemplates is the list of templates outside of Namespace the template can
+	//include, i.e. a shared "header.html".
+	AllowedTemplates []string
+
+	//Limits are applied each time the template is rendered.
+	Limits UntrustedLimits
+
+	//Author identifies who is making the change, i.e. a user's ID. This is only used
+	//as the Actor of audit events, see AuditFunc.
+	Author string
+}
+
+//UntrustedLimits are the limits applied each time a template written by an untrusted
+//author is rendered. A limit is not enforced if it is 0.
+type UntrustedLimits struct {
+	//MaxOutputBytes is the maximum size, in bytes, of the output of a render.
+	MaxOutputBytes int64
+
+	//Timeout is the maximum time a render can take.
+	Timeout time.Duration
+
+	//MaxRangeIterations is the maximum number of items that can be ranged over in a
+	//render, in total across every {{range}}.
+	MaxRangeIterations int
+}
+
+//untrustedSource is the source of a template added with ParseUntrusted() and the limits
+//applied when it is rendered.
+type untrustedSource struct {
+	src    string
+	limits UntrustedLimits
+}
+
+//CheckUntrusted checks that the source of a template, named name, follows the policy. An
+//error wrapping ErrUntrustedTemplate is returned if it does not. An error is also
+//returned if the source cannot be parsed.
+func CheckUntrusted(name, src string, p UntrustedPolicy) (err error) {
+	//A blank namespace would allow the template to replace, or include, any template.
+	if strings.TrimSpace(p.Namespace) == "" {
+		return fmt.Errorf("%w: policy namespace is blank", ErrUntrustedTemplate)
+	}
+
+	//Parse without checking funcs exist since the funcs called are checked against the
+	//policy below.
+	tree := parse.New(name)
+	tree.Mode = parse.SkipFuncCheck
+	trees := make(map[string]*parse.Tree)
+	_, err = tree.Parse(src, "", "", trees)
+	if err != nil {
+		return
+	}
+
+	allowedFuncs := make(map[string]bool, len(safeBuiltinFuncs)+len(p.AllowedFuncs))
+	for _, f := range safeBuiltinFuncs {
+		allowedFuncs[f] = true
+	}
+	for _, f := range p.AllowedFuncs {
+		allowedFuncs[f] = true
+	}
+
+	allowedTemplates := make(map[string]bool, len(p.AllowedTemplates))
+	for _, t := range p.AllowedTemplates {
+		allowedTemplates[t] = true
+	}
+
+	if !strings.HasPrefix(name, p.Namespace) {
+		return fmt.Errorf("%w: name '%s' is outside of namespace '%s'", ErrUntrustedTemplate, name, p.Namespace)
+	}
+
+	//Check each template defined in the source, sorted so the same error is always
+	//returned for the same source.
+	names := make([]string, 0, len(trees))
+	for n := range trees {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	for _, n := range names {
+		if !strings.HasPrefix(n, p.Namespace) {
+			return fmt.Errorf("%w: defined template '%s' is outside of namespace '%s'", ErrUntrustedTemplate, n, p.Namespace)
+		}
+
+		walkNodes(trees[n].Root, func(node parse.Node) {
+			if err != nil {
+				return
+			}
+
+			switch node := node.(type) {
+			case *parse.IdentifierNode:
+				if !allowedFuncs[node.Ident] || node.Ident == tryTemplateFunc {
+					err = fmt.Errorf("%w: func '%s' is not allowed", ErrUntrustedTemplate, node.Ident)
+				}
+			case *parse.TemplateNode:
+				if !strings.HasPrefix(node.Name, p.Namespace) && !allowedTemplates[node.Name] {
+					err = fmt.Errorf("%w: including template '%s' is not allowed", ErrUntrustedTemplate, node.Name)
+				}
+			}
+		})
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+//ParseUntrusted checks the source of a template written by an untrusted author against
+//the policy and, if it passes, parses it into an already built subdirectory's templates.
+//The template can then be shown by name, i.e. Show(w, subdir, name, data). A template
+//with the same name is replaced; the replaced version is kept so that the change can be
+//undone with RollbackUntrusted(). Templates added are kept until Build() is called
+//again.
+//
+//The subdirectory's templates are reparsed from the subdirectory's existing files and
+//sources plus the provided source, the same as ParseExtra(). If an error occurs, the
+//existing templates are left untouched.
+func (c *Config) ParseUntrusted(subdir, name, src string, p UntrustedPolicy) (err error) {
+	err = CheckUntrusted(name, src, p)
+	if err != nil {
+		return
+	}
+	if c.mu == nil {
+		return ErrUnknownSubDir
+	}
+
+	//Record the change once the lock is released so that AuditFunc can use the
+	//config.
+	event := AuditEvent{Action: AuditAdd, SubDir: subdir, Templates: []string{name}, Actor: p.Author}
+	defer func() {
+		if err == nil {
+			c.audit(event)
+		}
+	}()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	paths, ok := c.files[subdir]
+	if !ok {
+		return ErrUnknownSubDir
+	}
+
+	//Build the list of sources to parse. A new map is used so that the existing sources
+	//are not modified if an error occurs.
+	sources := make(map[string]untrustedSource, len(c.sources[subdir])+1)
+	for n, s := range c.sources[subdir] {
+		sources[n] = s
+	}
+	previous, replaced := sources[name]
+	sources[name] = untrustedSource{src: src, limits: p.Limits}
+	if _, ok := c.named[subdir][name]; ok {
+		event.Action = AuditReplace
+	}
+
+	err = c.reparse(subdir, paths, sources)
+	if err != nil {
+		log.Println("templates.ParseUntrusted", "error parsing template '"+name+"' at subdir '"+subdir+"'", err)
+		return
+	}
+
+	if c.sources == nil {
+		c.sources = make(map[string]map[string]untrustedSource)
+	}
+	c.sources[subdir] = sources
+
+	if replaced {
+		c.setPreviousSource(subdir, name, previous)
+	}
+	return
+}
+
+//ParseUntrusted checks and parses a template written by an untrusted author using the
+//default package level config.
+func ParseUntrusted(subdir, name, src string, p UntrustedPolicy) (err error) {
+	err = Default().ParseUntrusted(subdir, name, src, p)
+	return
+}
+
+//RollbackUntrusted undoes the last change made to a template with ParseUntrusted(),
+//restoring the version of the template it replaced. The template's name must be within
+//the policy's Namespace. ErrNoPreviousVersion is returned if the template has not been
+//replaced, or was already rolled back, since only the previous version is kept.
+func (c *Config) RollbackUntrusted(subdir, name string, p UntrustedPolicy) (err error) {
+	//A blank namespace would allow rolling back any template.
+	if strings.TrimSpace(p.Namespace) == "" {
+		return fmt.Errorf("%w: policy namespace is blank", ErrUntrustedTemplate)
+	}
+	if !strings.HasPrefix(name, p.Namespace) {
+		return fmt.Errorf("%w: name '%s' is outside of namespace '%s'", ErrUntrustedTemplate, name, p.Namespace)
+	}
+	if c.mu == nil {
+		return ErrUnknownSubDir
+	}
+
+	defer func() {
+		if err == nil {
+			c.audit(AuditEvent{Action: AuditRollback, SubDir: subdir, Templates: []string{name}, Actor: p.Author})
+		}
+	}()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	paths, ok := c.files[subdir]
+	if !ok {
+		return ErrUnknownSubDir
+	}
+	previous, ok := c.previousSources[subdir][name]
+	if !ok {
+		return ErrNoPreviousVersion
+	}
+
+	sources := make(map[string]untrustedSource, len(c.sources[subdir]))
+	for n, s := range c.sources[subdir] {
+		sources[n] = s
+	}
+	sources[name] = previous
+
+	err = c.reparse(subdir, paths, sources)
+	if err != nil {
+		log.Println("templates.RollbackUntrusted", "error parsing template '"+name+"' at subdir '"+subdir+"'", err)
+		return
+	}
+
+	c.sources[subdir] = sources
+	delete(c.previousSources[subdir], name)
+	return
+}
+
+//RollbackUntrusted undoes the last change made to a template with ParseUntrusted()
+//using the default package level config.
+func RollbackUntrusted(subdir, name string, p UntrustedPolicy) (err error) {
+	err = Default().RollbackUntrusted(subdir, name, p)
+	return
+}
+
+//setPreviousSource keeps the source a template added with ParseUntrusted() replaced.
+//This must be called while holding the write lock.
+func (c *Config) setPreviousSource(subdir, name string, s untrustedSource) {
+	if c.previousSources == nil {
+		c.previousSources = make(map[string]map[string]untrustedSource)
+	}
+	if c.previousSources[subdir] == nil {
+		c.previousSources[subdir] = make(map[string]untrustedSource)
+	}
+
+	c.previousSources[subdir][name] = s
+}
+
+//parseSources parses each source into t, keyed by template name, and returns the limits
+//for each template defined by the sources. Sources are parsed in order of name so that
+//the result is the same each time. Each {{range}} in the sources is guarded so that
+//MaxRangeIterations can be enforced.
+func parseSources(t *template.Template, sources map[string]untrustedSource) (limits map[string]UntrustedLimits, err error) {
+	if len(sources) == 0 {
+		return
+	}
+
+	names := make([]string, 0, len(sources))
+	for n := range sources {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	//The guard does nothing unless replaced when rendering with showUntrusted().
+	t.Funcs(template.FuncMap{rangeGuardFunc: func(v interface{}) interface{} { return v }})
+
+	limits = make(map[string]UntrustedLimits)
+	for _, n := range names {
+		s := sources[n]
+		_, err = t.New(n).Parse(s.src)
+		if err != nil {
+			return
+		}
+
+		//Find the templates the source defines so that each is guarded and rendered
+		//with limits.
+		trees := make(map[string]*parse.Tree)
+		tree := parse.New(n)
+		tree.Mode = parse.SkipFuncCheck
+		_, err = tree.Parse(s.src, "", "", trees)
+		if err != nil {
+			return
+		}
+
+		for dn := range trees {
+			limits[dn] = s.limits
+			if defined := t.Lookup(dn); defined != nil && defined.Tree != nil {
+				guardRanges(defined.Tree)
+			}
+		}
+	}
+
+	return
+}
+
+//guardRanges adds the range guard func to the end of the pipeline of each {{range}} in
+//tree. Ranges that are already guarded are skipped.
+func guardRanges(tree *parse.Tree) {
+	walkNodes(tree.Root, func(n parse.Node) {
+		r, ok := n.(*parse.RangeNode)
+		if !ok || r.Pipe == nil {
+			return
+		}
+
+		if last := r.Pipe.Cmds[len(r.Pipe.Cmds)-1]; len(last.Args) == 1 {
+			if ident, ok := last.Args[0].(*parse.IdentifierNode); ok && ident.Ident == rangeGuardFunc {
+				return
+			}
+		}
+
+		guard := parse.NewIdentifier(rangeGuardFunc).SetTree(tree).SetPos(r.Pipe.Pos)
+		r.Pipe.Cmds = append(r.Pipe.Cmds, &parse.CommandNode{
+			NodeType: parse.NodeCommand,
+			Pos:      r.Pipe.Pos,
+			Args:     []parse.Node{guard},
+		})
+	})
+}
+
+//showUntrusted handles rendering a template added with ParseUntrusted() for serve().
+//An unexecuted copy of the subdirectory's templates is cloned for each render so that
+//the range guard can count the items ranged over in this render only.
+func (c *Config) showUntrusted(w http.ResponseWriter, r *http.Request, requestID string, v renderView, subdir, templateName string, injectedData interface{}) error {
+	unexecuted := v.untrusted
+	limits := v.untrustedLimits[templateName]
+	if unexecuted == nil {
+		return &showError{requestID, c.notFoundStatus(), "templates.Show: error during lookup", ErrTemplateNotFound}
+	}
+
+	clone, err := unexecuted.Clone()
+	if err != nil {
+		return &showError{requestID, http.StatusInternalServerError, "templates.Show: error cloning untrusted template", err}
+	}
+
+	u := newUntrustedRender(limits)
+	fm := template.FuncMap{rangeGuardFunc: u.guardRange}
+	rfm := c.requestFuncMap(&requestBinding{r: r})
+	for _, name := range c.requestFuncNames() {
+		fm[name] = rfm[name]
+	}
+	tmpl := c.bindTryTemplate(clone.Funcs(fm)).Lookup(templateName)
+
+	//Set headers based on the config and template being shown.
+	c.setHeaders(w, subdir, templateName)
+
+	data := c.renderData(v, subdir, requestID, injectedData)
+	c.setRequestData(&data, r)
+
+	ctx := context.Background()
+	if r != nil {
+		ctx = r.Context()
+	}
+
+	//Wait to render if too many renders are in progress.
+	release, err := c.acquireRender(ctx)
+	if err != nil {
+		return &showError{requestID, c.errorStatus(err), "templates.Show: error waiting to execute", err}
+	}
+	defer release()
+
+	//Render fully before writing anything so that an error response can be returned
+	//if a limit is exceeded.
+	var b bytes.Buffer
+	if err = tmpl.Execute(u.writer(&b), c.templateData(&data)); err != nil {
+		return &showError{requestID, http.StatusInternalServerError, "templates.Show: error during execute", err}
+	}
+
+	w.Write(b.Bytes())
+	return nil
+}
+
+//executeLimited renders the template name from t with limits. t must not have been
+//executed yet, and must not be used by other renders, since the range guard is bound to
+//this render.
+func (c *Config) executeLimited(w io.Writer, t *template.Template, name string, data interface{}, limits UntrustedLimits) error {
+	u := newUntrustedRender(limits)
+	tmpl := c.bindTryTemplate(t.Funcs(template.FuncMap{rangeGuardFunc: u.guardRange})).Lookup(name)
+	if tmpl == nil {
+		return ErrTemplateNotFound
+	}
+
+	return tmpl.Execute(u.writer(c.limitOutput(w)), data)
+}
+
+//untrustedRender enforces the limits of a single render of an untrusted template.
+type untrustedRender struct {
+	limits     UntrustedLimits
+	deadline   time.Time
+	iterations int
+}
+
+//newUntrustedRender returns the state for a render with limits, starting the render's
+//timeout.
+func newUntrustedRender(limits UntrustedLimits) *untrustedRender {
+	u := &untrustedRender{limits: limits}
+	if limits.Timeout > 0 {
+		u.deadline = time.Now().Add(limits.Timeout)
+	}
+
+	return u
+}
+
+//expired returns an error if the render's timeout has passed.
+func (u *untrustedRender) expired() error {
+	if !u.deadline.IsZero() && time.Now().After(u.deadline) {
+		return ErrUntrustedTimeout
+	}
+
+	return nil
+}
+
+//writer wraps w to enforce the render's MaxOutputBytes and Timeout.
+func (u *untrustedRender) writer(w io.Writer) io.Writer {
+	if u.limits.MaxOutputBytes > 0 {
+		w = &limitWriter{w: w, max: u.limits.MaxOutputBytes}
+	}
+
+	return untrustedWriter{w: w, u: u}
+}
+
+//guardRange is called with the value each {{range}} iterates over. The number of items
+//is counted towards MaxRangeIterations and the value is returned as is. Values whose
+//number of items is unknown, such as channels, are not counted.
+func (u *untrustedRender) guardRange(v interface{}) (interface{}, error) {
+	if err := u.expired(); err != nil {
+		return nil, err
+	}
+	if u.limits.MaxRangeIterations <= 0 {
+		return v, nil
+	}
+
+	rv := indirect(reflect.ValueOf(v))
+	switch {
+	case rv.Kind() == reflect.Array || rv.Kind() == reflect.Slice || rv.Kind() == reflect.Map:
+		u.iterations += rv.Len()
+	case isInt(rv) && rv.Int() > 0:
+		u.iterations += int(rv.Int())
+	case isUint(rv):
+		u.iterations += int(rv.Uint())
+	}
+
+	if u.iterations > u.limits.MaxRangeIterations {
+		return nil, ErrMaxRangeIterations
+	}
+
+	return v, nil
+}
+
+//untrustedWriter is an io.Writer that returns an error once a render's timeout has
+//passed.
+type untrustedWriter struct {
+	w io.Writer
+	u *untrustedRender
+}
+
+//Write implements io.Writer.
+func (uw untrustedWriter) Write(p []byte) (n int, err error) {
+	if err = uw.u.expired(); err != nil {
+		return
+	}
+
+	return uw.w.Write(p)
+}
@@ -0,0 +1,312 @@
+package templates
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckUntrusted(t *testing.T) {
+	p := UntrustedPolicy{
+		Namespace:        "tenant-1/",
+		AllowedFuncs:     []string{"truncate"},
+		AllowedTemplates: []string{"header.html"},
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	allowed := []string{
+		`{{template "header.html"}}{{if eq .A "b"}}{{truncate .Name 10}}{{end}}`,
+		`{{define "tenant-1/part"}}part{{end}}{{template "tenant-1/part" .}}`,
+	}
+	for _, src := range allowed {
+		if err := CheckUntrusted("tenant-1/home.html", src, p); err != nil {
+			t.Fatal("Template should have been allowed", src, err)
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	rejected := map[string]string{
+		"tenant-1/home.html": `{{call .Func}}`,
+		"tenant-1/x":         `{{inlineSVG "secret.svg"}}`,
+		"tenant-1/y":         `{{template "tenant-2/home"}}`,
+		"tenant-1/z":         `{{define "header.html"}}replaced{{end}}`,
+		"tenant-2/home":      `hello`,
+	}
+	for name, src := range rejected {
+		if err := CheckUntrusted(name, src, p); !errors.Is(err, ErrUntrustedTemplate) {
+			t.Fatal("Template should have been rejected", name, src, err)
+			return
+		}
+	}
+
+	for _, namespace := range []string{"", "  "} {
+		blank := p
+		blank.Namespace = namespace
+		if err := CheckUntrusted("home.html", `hello`, blank); !errors.Is(err, ErrUntrustedTemplate) {
+			t.Fatal("Template should have been rejected for blank namespace", err)
+			return
+		}
+	}
+
+	if err := CheckUntrusted("tenant-1/bad", `{{if}}`, p); err == nil {
+		t.Fatal("Error should have been returned for invalid source")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestParseUntrusted(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	subdirs := []string{"app", "help"}
+	c := NewOnDiskConfig(base, subdirs)
+	c.FuncMap = DefaultFuncMap()
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	p := UntrustedPolicy{Namespace: "tenant-1/", AllowedFuncs: []string{"truncate"}}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	err = c.ParseUntrusted("app", "tenant-1/home.html", `Hi {{truncate .InjectedData 3}}`, p)
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	err = c.ParseUntrusted("app", "tenant-1/about.html", `About`, p)
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+
+	w := httptest.NewRecorder()
+	c.Show(w, "app", "tenant-1/home.html", "Bobby")
+	if w.Code != http.StatusOK || w.Body.String() != "Hi Bo…" {
+		t.Fatal("Untrusted template not shown as expected", w.Code, w.Body.String())
+		return
+	}
+
+	//Earlier sources are kept.
+	w = httptest.NewRecorder()
+	c.Show(w, "app", "tenant-1/about.html", nil)
+	if w.Body.String() != "About" {
+		t.Fatal("Untrusted template not shown as expected", w.Code, w.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Rejected templates are not added.
+	err = c.ParseUntrusted("app", "tenant-1/home.html", `{{qrCode "x" 10}}`, p)
+	if !errors.Is(err, ErrUntrustedTemplate) || !strings.Contains(err.Error(), "qrCode") {
+		t.Fatal("Template should have been rejected", err)
+		return
+	}
+	w = httptest.NewRecorder()
+	c.Show(w, "app", "tenant-1/home.html", "Bobby")
+	if w.Body.String() != "Hi Bo…" {
+		t.Fatal("Existing template should not have been replaced", w.Body.String())
+		return
+	}
+
+	err = c.ParseUntrusted("not-built", "tenant-1/home.html", `Hi`, p)
+	if err != ErrUnknownSubDir {
+		t.Fatal("ErrUnknownSubDir should have occured but didn't", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestParseUntrustedLimits(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	c := NewOnDiskConfig(base, []string{"app"})
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	p := UntrustedPolicy{
+		Namespace: "tenant-1/",
+		Limits: UntrustedLimits{
+			MaxOutputBytes:     20,
+			MaxRangeIterations: 5,
+			Timeout:            time.Second,
+		},
+	}
+	src := `{{define "tenant-1/list.html"}}{{range $i, $v := .InjectedData}}{{range .}}{{.}}{{end}}{{end}}{{end}}`
+	err = c.ParseUntrusted("app", "tenant-1/list-source.html", src, p)
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Within limits, ranges count 1 + 3 items.
+	w := httptest.NewRecorder()
+	c.Show(w, "app", "tenant-1/list", [][]string{{"a", "b", "c"}})
+	if w.Code != http.StatusOK || w.Body.String() != "abc" {
+		t.Fatal("Untrusted template not shown as expected", w.Code, w.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Too many items ranged over in total, 2 + 2 + 2.
+	w = httptest.NewRecorder()
+	c.Show(w, "app", "tenant-1/list", [][]string{{"a", "b"}, {"c", "d"}})
+	if w.Code != http.StatusInternalServerError || !strings.Contains(w.Body.String(), ErrMaxRangeIterations.Error()) {
+		t.Fatal("Range iterations should have been limited", w.Code, w.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Too much output.
+	w = httptest.NewRecorder()
+	c.Show(w, "app", "tenant-1/list", [][]string{{strings.Repeat("a", 30)}})
+	if w.Code != http.StatusInternalServerError || strings.Contains(w.Body.String(), "aaaa") {
+		t.Fatal("Output should have been limited", w.Code, w.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Limits are enforced when rendering outside of an http handler too.
+	out, err := c.RenderToString("app", "tenant-1/list", [][]string{{"a", "b", "c"}})
+	if err != nil || out != "abc" {
+		t.Fatal("Untrusted template not rendered as expected", out, err)
+		return
+	}
+	_, err = c.RenderToString("app", "tenant-1/list", [][]string{{"a", "b"}, {"c", "d"}})
+	if !errors.Is(err, ErrMaxRangeIterations) {
+		t.Fatal("Range iterations should have been limited", err)
+		return
+	}
+	_, err = c.RenderToString("app", "tenant-1/list", [][]string{{strings.Repeat("a", 30)}})
+	if err == nil {
+		t.Fatal("Output should have been limited")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Timeout passed before output is written.
+	u := newUntrustedRender(UntrustedLimits{Timeout: time.Nanosecond})
+	time.Sleep(time.Millisecond)
+	var b strings.Builder
+	if _, err := u.writer(&b).Write([]byte("a")); err != ErrUntrustedTimeout {
+		t.Fatal("ErrUntrustedTimeout should have occured but didn't", err)
+		return
+	}
+	if _, err := u.guardRange([]int{1}); err != ErrUntrustedTimeout {
+		t.Fatal("ErrUntrustedTimeout should have occured but didn't", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Trusted templates are not limited.
+	w = httptest.NewRecorder()
+	c.Show(w, "app", "app", nil)
+	if w.Code != http.StatusOK {
+		t.Fatal("Trusted template should have been shown", w.Code, w.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestRollbackUntrusted(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	c := NewOnDiskConfig(base, []string{"app"})
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	p := UntrustedPolicy{Namespace: "tenant-1/"}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	err = c.ParseUntrusted("app", "tenant-1/home.html", "v1", p)
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	err = c.RollbackUntrusted("app", "tenant-1/home.html", p)
+	if err != ErrNoPreviousVersion {
+		t.Fatal("ErrNoPreviousVersion should have occured but didn't", err)
+		return
+	}
+
+	err = c.ParseUntrusted("app", "tenant-1/home.html", "v2", p)
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	err = c.RollbackUntrusted("app", "tenant-1/home.html", p)
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+
+	w := httptest.NewRecorder()
+	c.Show(w, "app", "tenant-1/home.html", nil)
+	if w.Body.String() != "v1" {
+		t.Fatal("Template should have been rolled back", w.Body.String())
+		return
+	}
+
+	//Only one previous version is kept.
+	err = c.RollbackUntrusted("app", "tenant-1/home.html", p)
+	if err != ErrNoPreviousVersion {
+		t.Fatal("ErrNoPreviousVersion should have occured but didn't", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	err = c.RollbackUntrusted("app", "tenant-2/home.html", p)
+	if !errors.Is(err, ErrUntrustedTemplate) {
+		t.Fatal("ErrUntrustedTemplate should have occured but didn't", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//A blank namespace is rejected the same as when parsing.
+	for _, namespace := range []string{"", "  "} {
+		err = c.RollbackUntrusted("app", "tenant-1/home.html", UntrustedPolicy{Namespace: namespace})
+		if !errors.Is(err, ErrUntrustedTemplate) {
+			t.Fatal("ErrUntrustedTemplate should have occured but didn't", namespace, err)
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
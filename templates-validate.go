@@ -0,0 +1,109 @@
+/*
+This file handles validating the source of a single template without building your
+templates, for example to check a snippet in an online template editor or to fuzz test
+template handling. The source is handled the same as a file when templates are built:
+source transforms, build directives, and trimmed blocks are applied, it is parsed with
+the same funcs, and MaxIncludeDepth is checked.
+
+Additionally, each template included with {{template "name"}} must be defined. A
+template can be defined in the source itself, be a contrib partial, or, if your
+templates have been built, be defined in any subdirectory's templates. Since Build()
+only reports a missing template when it is rendered, this catches mistakes earlier.
+*/
+
+package templates
+
+import (
+	"fmt"
+	"html/template"
+	"text/template/parse"
+)
+
+//validateName is the name the source is parsed as by ParseAndValidate().
+const validateName = "source"
+
+//ParseAndValidate parses the source of a template, and checks that each template it
+//includes is defined, the same as when templates are built. An error is returned if
+//the source is not valid. Nothing is saved to the config.
+func (c *Config) ParseAndValidate(src string) (err error) {
+	t := c.bindTryTemplate(template.New("").Funcs(c.funcMap()))
+
+	err = c.parseContrib(t)
+	if err != nil {
+		return
+	}
+
+	b, err := c.transform(validateName, []byte(src))
+	if err != nil {
+		return
+	}
+
+	//A source excluded by its build directive is valid, it is just never parsed.
+	b, include := c.applyBuildDirectives(b)
+	if !include {
+		return
+	}
+
+	_, err = t.New(validateName).Parse(c.source(b))
+	if err != nil {
+		return
+	}
+
+	trees := make(map[string]*parse.Tree)
+	for _, tmpl := range t.Templates() {
+		trees[tmpl.Name()] = tmpl.Tree
+	}
+	err = c.checkIncludeDepth(trees)
+	if err != nil {
+		return
+	}
+
+	//Make sure each included template is defined.
+	for _, tree := range trees {
+		if tree == nil {
+			continue
+		}
+
+		walkNodes(tree.Root, func(n parse.Node) {
+			tn, ok := n.(*parse.TemplateNode)
+			if !ok || err != nil {
+				return
+			}
+
+			if t.Lookup(tn.Name) == nil && !c.isBuiltTemplate(tn.Name) {
+				err = fmt.Errorf("%w: '%s' is included but is not defined", ErrTemplateNotFound, tn.Name)
+			}
+		})
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+//ParseAndValidate validates the source of a template using the default package level
+//config.
+func ParseAndValidate(src string) (err error) {
+	err = Default().ParseAndValidate(src)
+	return
+}
+
+//isBuiltTemplate returns if a template with the name is defined in any subdirectory's
+//built templates.
+func (c *Config) isBuiltTemplate(name string) bool {
+	if c.mu == nil {
+		return false
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, t := range c.templates {
+		if t.Lookup(name) != nil {
+			return true
+		}
+	}
+
+	return false
+}
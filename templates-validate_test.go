@@ -0,0 +1,88 @@
+package templates
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseAndValidate(t *testing.T) {
+	c := NewConfig()
+	c.FuncMap = DefaultFuncMap()
+	c.MaxIncludeDepth = 2
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	valid := []string{
+		``,
+		`Hello {{.InjectedData}}`,
+		`{{define "a"}}a{{end}}{{template "a"}}`,
+		`{{truncate "abc" 2}} {{tryTemplate "missing" .}}`,
+		`{{/* +build production */}}{{template "missing"}}`,
+	}
+	for _, src := range valid {
+		if err := c.ParseAndValidate(src); err != nil {
+			t.Fatal("Source should be valid", src, err)
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	invalid := []string{
+		`{{if}}`,
+		`{{notAFunc}}`,
+		`{{define "a"}}{{template "a"}}{{end}}`,
+		`{{define "a"}}a{{end}}{{define "b"}}{{template "a"}}{{end}}{{define "c"}}{{template "b"}}{{end}}{{template "c"}}`,
+	}
+	for _, src := range invalid {
+		if err := c.ParseAndValidate(src); err == nil {
+			t.Fatal("Source should be invalid", src)
+			return
+		}
+	}
+
+	err := c.ParseAndValidate(`{{template "header.html"}}`)
+	if !errors.Is(err, ErrTemplateNotFound) {
+		t.Fatal("ErrTemplateNotFound should have occured but didn't", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Templates defined in built templates and contrib partials can be included.
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	c = NewOnDiskConfig(filepath.Join(dir, "_testdata", "templates"), []string{"app"})
+	c.ContribPartials = true
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	err = c.ParseAndValidate(`{{template "header.html"}}{{template "contrib/flash" nil}}`)
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func FuzzParseAndValidate(f *testing.F) {
+	f.Add(`Hello {{.InjectedData}}`)
+	f.Add(`{{define "a"}}a{{end}}{{template "a"}}`)
+	f.Add(`{{/* +if development */}}debug{{/* +end */}}`)
+	f.Add(`{{range $i, $v := .Items}}{{$v}}{{end}}`)
+
+	c := NewConfig()
+	c.MaxIncludeDepth = 5
+	c.TrimBlocks = true
+	f.Fuzz(func(t *testing.T, src string) {
+		//Only panics are failures, invalid sources are expected.
+		c.ParseAndValidate(src)
+	})
+}
@@ -0,0 +1,70 @@
+/*
+This file handles selecting a variant of a template based upon the device a request was
+made from. This is useful for apps that serve distinct markup to mobile devices.
+
+Variants are registered by naming convention. A variant of a template is a file with the
+variant's name inserted before the extension, for example users.mobile.html is the
+"mobile" variant of users.html. Variants are stored alongside the template they are a
+variant of. When using ShowRequest(), DeviceFunc is called to determine the variant for
+the request and, if a variant of the requested template exists, the variant is shown
+instead of the requested template. If no variant exists, the requested template is shown.
+*/
+
+package templates
+
+import (
+	"html/template"
+	"net/http"
+	"strings"
+)
+
+//variant returns the name of the variant of a template to show for a request. If no
+//DeviceFunc is set, no request is known, or no variant exists for the template, the
+//templateName is returned as is. templateName must include the extension.
+func (c *Config) variant(w http.ResponseWriter, r *http.Request, t *template.Template, templateName string) string {
+	if c.DeviceFunc == nil || r == nil {
+		return templateName
+	}
+
+	//The response differs based upon the device so caches must know which header(s)
+	//the device was determined from.
+	vary := c.DeviceVary
+	if len(vary) == 0 {
+		vary = []string{"User-Agent"}
+	}
+	addVary(w, vary...)
+
+	v := strings.TrimSpace(c.DeviceFunc(r))
+	if v == "" {
+		return templateName
+	}
+
+	name := strings.TrimSuffix(templateName, "."+c.Extension) + "." + v + "." + c.Extension
+	if t.Lookup(name) == nil {
+		return templateName
+	}
+
+	return name
+}
+
+//MobileDeviceFunc is a basic DeviceFunc that returns "mobile" for requests made from
+//mobile devices based upon the Sec-CH-UA-Mobile client hint or, if not provided, common
+//mobile identifiers in the User-Agent header. This is not meant to be exhaustive; use a
+//dedicated device detection library if you need more accurate detection.
+func MobileDeviceFunc(r *http.Request) string {
+	if hint := r.Header.Get("Sec-CH-UA-Mobile"); hint != "" {
+		if hint == "?1" {
+			return "mobile"
+		}
+		return ""
+	}
+
+	ua := strings.ToLower(r.UserAgent())
+	for _, s := range []string{"mobi", "android", "iphone", "ipod", "windows phone"} {
+		if strings.Contains(ua, s) {
+			return "mobile"
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,68 @@
+package templates
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVariant(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	subdirs := []string{"app", "help"}
+	c := NewOnDiskConfig(base, subdirs)
+	c.DeviceFunc = MobileDeviceFunc
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Mobile variant is shown to mobile devices.
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("User-Agent", "Mozilla/5.0 (iPhone; CPU iPhone OS 15_0 like Mac OS X) Mobile/15E148")
+	w := httptest.NewRecorder()
+	c.ShowRequest(w, r, "help", "device", nil)
+	if strings.TrimSpace(w.Body.String()) != "mobile" {
+		t.Fatal("Mobile variant not shown", w.Body.String())
+		return
+	}
+	if w.Header().Get("Vary") != "User-Agent" {
+		t.Fatal("Vary header not set", w.Header().Get("Vary"))
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Requested template is shown to other devices.
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("User-Agent", "Mozilla/5.0 (X11; Linux x86_64)")
+	w = httptest.NewRecorder()
+	c.ShowRequest(w, r, "help", "device", nil)
+	if strings.TrimSpace(w.Body.String()) != "desktop" {
+		t.Fatal("Requested template not shown", w.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Requested template is shown when no variant exists.
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Sec-CH-UA-Mobile", "?1")
+	w = httptest.NewRecorder()
+	c.ShowRequest(w, r, "app", "app", nil)
+	if w.Code != http.StatusOK {
+		t.Fatal("Error showing", w.Code, w.Body)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
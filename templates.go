@@ -32,15 +32,23 @@ templates/
 package templates
 
 import (
+	"bytes"
+	"context"
 	"embed"
 	"errors"
+	"fmt"
 	"html/template"
 	"io/fs"
 	"log"
 	"net/http"
 	"os"
 	"path/filepath"
+	"runtime/debug"
 	"strings"
+	"sync"
+	texttemplate "text/template"
+	"text/template/parse"
+	"time"
 )
 
 //Config is the set of configuration settings for working with templates.
@@ -52,11 +60,77 @@ type Config struct {
 	//non-cache busted static files.
 	Development bool
 
+	//LintAccessibility checks the output of templates for common accessibility
+	//problems, such as images without alt text, form fields without labels, and
+	//duplicate IDs, when Development is true. Problems are logged, or provided to
+	//LintFunc, and the page is still shown.
+	LintAccessibility bool
+
+	//LintHTML checks that the output of templates is well-formed HTML, i.e. tags are
+	//closed and nested properly, when Development is true. Browsers silently repair
+	//these problems, often not as intended, resulting in subtle layout bugs. Problems
+	//are logged, or provided to LintFunc, and the page is still shown.
+	LintHTML bool
+
+	//StrictCSP checks template files for inline <script> and <style> elements and
+	//inline event handlers, such as onclick, when templates are built. These are
+	//blocked by a strict Content-Security-Policy. Each problem is listed by file and
+	//line, by logging or via LintFunc, so that templates can be migrated incrementally.
+	//Problems do not cause building to fail.
+	StrictCSP bool
+
+	//LintFunc is called with the problems found in the output of a template when
+	//checking output for problems, i.e. LintAccessibility or LintHTML. Use this to show
+	//problems in your app's debug toolbar or to fail tests. If this is not set,
+	//problems are logged. subdir and templateName are blank for problems found in
+	//template files when building, i.e. StrictCSP, see LintProblem.File instead.
+	LintFunc func(subdir, templateName string, problems []LintProblem)
+
+	//TrimBlocks removes the indentation and trailing newline of each line in your
+	//template files that only contains control structures, such as {{if}}, {{else}},
+	//{{end}}, or {{range}}, comments, or variable declarations. This removes the blank
+	//lines and odd indentation these lines leave in the output without having to use
+	//{{- -}} throughout your templates. This is similar to trim_blocks in Jinja.
+	TrimBlocks bool
+
+	//BuildTags is a list of tags used to exclude template files, or blocks within
+	//files, from being parsed using build directives, i.e. {{/* +build debug */}}. The
+	//Environment, and "development" if Development is true, are always set as tags.
+	//Directives are evaluated when templates are built. See templates-buildtags.go.
+	BuildTags []string
+
+	//SourceTransforms is a list of funcs applied, in order, to the source of each
+	//template file before it is parsed. Each func is provided the file's path and
+	//source and returns the transformed source. This allows for custom preprocessing,
+	//such as inserting compile-time constants, stripping development-only blocks, or
+	//expanding custom syntax. An error stops templates from being built. See
+	//templates-transforms.go.
+	SourceTransforms []func(path string, src []byte) ([]byte, error)
+
+	//ContribPartials means the partials provided by this package, for tables,
+	//pagination, and flash messages, are parsed into every subdirectory's templates.
+	//Use these with {{template "contrib/table" .}}. See templates-contrib.go.
+	ContribPartials bool
+
 	//UseLocalFiles is passed to each template when rendering the HTML to be sent
 	//to the user so that the HTML can be altered to use locally hosted third
 	//party libraries (JS, CSS) versus libraries retrieve from the internet.
 	UseLocalFiles bool
 
+	//Environment is passed to each template when rendering the HTML to be sent to the
+	//user so that the HTML can be altered based on the environment your app is running
+	//in, for example "production", "staging", or "dev". This is useful when you have
+	//more than one non-production environment and Development is not granular enough.
+	//Templates can check the environment with the isEnv func, i.e.:
+	//{{if isEnv "staging"}}. The comparison is case insensitive.
+	Environment string
+
+	//AppVersion is passed to each template when rendering the HTML to be sent to the
+	//user so that your app's version can be displayed, typically in a footer or on
+	//error pages. This can be set from a value provided at build time via ldflags or
+	//by using AppVersionFromBuildInfo().
+	AppVersion string
+
 	//BasePath is the full path to the directory where template files are stored
 	//not including any subdirectories. There should be at least one template file
 	//at this path. Files in this directory will be inherited into each subdirectory
@@ -70,8 +144,18 @@ type Config struct {
 	//files. This may be empty if you have no subdirectories. This must only be the
 	//actual directory names, not full paths. Full paths will be constructed from
 	//BasePath.
+	//
+	//A glob pattern, such as "docs/*", can be used to build each matching subdirectory.
+	//Patterns are expanded each time templates are built. See templates-subdirs.go.
 	SubDirs []string
 
+	//Groups is a key-value list of group names to subdirectories of the BasePath that
+	//are parsed together. This allows a family of subdirectories to share {{define}}
+	//blocks with each other, not just with the base directory. Templates in a group are
+	//shown using the group's name as the subdirectory. The subdirectories in a group do
+	//not need to be listed in SubDirs. See templates-groups.go.
+	Groups map[string][]string
+
 	//Extension is the extension you use for your HTML files. This defaults to "html".
 	Extension string
 
@@ -81,11 +165,25 @@ type Config struct {
 	//the field EmbeddedFS.
 	UseEmbedded bool
 
+	//StaticPath is the full path to the directory where static files, such as images
+	//and stylesheets, are stored. This is used by funcs that inline static files into
+	//templates, such as inlineSVG and inlineCSS. Static files are read from on-disk or
+	//embedded files the same as template files. This is optional if you do not inline
+	//static files.
+	StaticPath string
+
 	//EmbeddedFiles is the filesystem embedded into this executable via the embed package.
 	//You must have read the embedded files, with code such as var embeddedFiles embed.FS,
 	//prior and you must set UseEmbedded to true to enable use of these files.
 	EmbeddedFS embed.FS
 
+	//FS is a filesystem to read files from rather than files stored on-disk or
+	//EmbeddedFS. This allows for using any filesystem, such as a zip archive, an
+	//fstest.MapFS in tests, or a filesystem you wrote. Paths, such as BasePath, are
+	//paths within FS. When set, UseEmbedded and EmbeddedFS are ignored. See
+	//templates-fs.go.
+	FS fs.FS
+
 	//FuncMap is a collection of functions that you want to use in your templates to
 	//augment the golang provided templating funcs. This package provides some default
 	//extra funcs in templates-templatefuncs.go. See https://pkg.go.dev/text/template for
@@ -100,7 +198,7 @@ type Config struct {
 			"indexOf":   templates.FuncIndexOf,
 			"myNewFunc": myNewFunc,
 		}
-		err = config.Build()
+		err = Default().Build()
 		if err != nil {
 			//handle err
 		}
@@ -114,6 +212,9 @@ type Config struct {
 	//A1B2C3D4.script.min.js). See the package github.com/c9845/cachebusting for an example
 	//implementation and tooling.
 	//
+	//Use SetCacheBustingFilePairs() when the pairs change after templates are built so
+	//that the change is known for ConditionalGET.
+	//
 	//To use the cache busting file, you would use template code similar to the following
 	//to handle the filename replacement:
 	/*
@@ -132,17 +233,400 @@ type Config struct {
 	*/
 	CacheBustingFilePairs map[string]string
 
+	//SubDirCacheBustingFilePairs is a key-value list of subdirectories to cache busting
+	//file pairs used only when showing templates from that subdirectory. This is useful
+	//when subdirectories use different bundles of static files that have the same
+	//original filenames (i.e.: an admin and a public app.min.js). Pairs for the
+	//subdirectory are merged with, and replace the same original filenames in,
+	//CacheBustingFilePairs. As with CacheBustingFilePairs, use
+	//SetSubDirCacheBustingFilePairs() when the pairs change after templates are built.
+	SubDirCacheBustingFilePairs map[string]map[string]string
+
+	//RequestIDHeader is the name of the header a request's ID is retrieved from when
+	//using ShowRequest(). The request's ID is passed to each template when rendering
+	//and is included in logging so that errors shown to a user can be tied to your
+	//app's logs. This defaults to "X-Request-ID".
+	RequestIDHeader string
+
+	//RequestIDContextKey is the key used to retrieve a request's ID from the request's
+	//context when using ShowRequest(). This is useful if your app's middleware stores
+	//a request's ID in the request's context. The value stored in the context must be
+	//a string. If this is set, the request's context is checked prior to the request's
+	//headers.
+	RequestIDContextKey interface{}
+
+	//CacheControl is a key-value list of subdirectories, or templates, to the value of
+	//the Cache-Control header that will be set when a template is shown. This allows
+	//for setting a caching policy without having to set the header in each of your
+	//http handlers; for example, documentation pages can be cached while app pages are
+	//not. Keys can be a subdirectory's name (i.e.: "docs") or a subdirectory's name and
+	//a template's name (i.e.: "docs/index" or "docs/index.html"). Templates shown
+	//without a subdirectory are keyed by the template's name with a leading slash (i.e.:
+	//"/index"). A value for a specific template is used over the value for the
+	//template's subdirectory. No header is set if no matching key is found.
+	CacheControl map[string]string
+
+	//Vary is a list of request header names that are added to the Vary header when a
+	//template is shown. Set this when your app alters responses based upon a request's
+	//headers, for example when compressing responses (Accept-Encoding), negotiating
+	//content (Accept), or selecting a locale (Accept-Language), so that caches do not
+	//serve the wrong variant of a page. Headers this package alters responses based
+	//upon are added to the Vary header automatically.
+	Vary []string
+
+	//SecurityHeaders is a set of security related headers, such as X-Frame-Options,
+	//set when a template is shown. This provides safe defaults without having to set
+	//the headers in each of your http handlers or middleware. Use
+	//DefaultSecurityHeaders() for a sensible set of values. No headers are set if this
+	//is nil.
+	SecurityHeaders *SecurityHeaders
+
+	//SubDirSecurityHeaders is a key-value list of subdirectories to the security
+	//headers used only when showing templates from that subdirectory. This is useful
+	//when a subdirectory needs different headers, for example a Content-Security-Policy
+	//for pages that load third-party scripts or allowing pages to be framed. The
+	//headers for a subdirectory replace SecurityHeaders; set the value to nil to set no
+	//headers for a subdirectory.
+	SubDirSecurityHeaders map[string]*SecurityHeaders
+
+	//ConditionalGET enables responding to conditional GET requests, requests with an
+	//If-Modified-Since header, when using ShowRequest(). This only applies when no data
+	//is injected into the template or the injected data implements LastModifier. The
+	//Last-Modified header is set to the newest of the modification time of the template
+	//files in the subdirectory being shown, the time config fields provided to templates
+	//(such as AppVersion or CacheBustingFilePairs) last changed, the time the page's
+	//prerendered output last changed, and the injected data's LastModified(). A 304 Not
+	//Modified response is returned if none have changed since the time provided in the
+	//request. Conditional requests are not handled for pages that use data or funcs
+	//based upon the request, such as the user, since the output may differ for each
+	//request.
+	//
+	//Changes to the config fields provided to templates are only known when the fields
+	//are set before templates are built or with a Set method, such as SetAppVersion().
+	ConditionalGET bool
+
+	//TrustProxyHeaders means the X-Forwarded-Proto and X-Forwarded-Host headers are
+	//used when building absolute URLs with the absoluteURL func. Only set this if your
+	//app is behind a proxy that sets these headers, otherwise a user could provide
+	//these headers to alter the URLs built.
+	TrustProxyHeaders bool
+
+	//URLSigningKey is the key used to sign URLs with the signURL func in templates,
+	//i.e.: {{signURL "/files/report.pdf" "15m"}}, and to verify signed URLs with
+	//VerifySignedURL(). This should be a random value of at least 32 bytes and must be
+	//kept secret. Templates calling signURL fail to render, with ErrNoURLSigningKey, if
+	//this is not set so that links that will never verify are not shown.
+	URLSigningKey []byte
+
+	//FeatureFlagFunc is used to determine if a feature flag is enabled via the flag
+	//func in templates, i.e.: {{if flag "new-nav"}}. This allows for toggling parts of
+	//your templates using your feature flag service. The request's context is provided
+	//when using ShowRequest() so that flags can be evaluated per user. Flags are
+	//always disabled if this is not set.
+	FeatureFlagFunc func(ctx context.Context, flag string) bool
+
+	//DeviceFunc is used to select a variant of a template based upon the device a
+	//request was made from when using ShowRequest(). This returns the name of the
+	//variant, for example "mobile", or a blank string if no variant should be used. If
+	//the returned variant of the template exists, i.e.: users.mobile.html for
+	//users.html, the variant is shown instead. See MobileDeviceFunc() for a basic
+	//implementation.
+	DeviceFunc func(r *http.Request) string
+
+	//DeviceVary is the list of request headers DeviceFunc uses to determine the device
+	//a request was made from. These headers are added to the Vary header when
+	//DeviceFunc is set. This defaults to "User-Agent".
+	DeviceVary []string
+
+	//NotificationMaxLength is the maximum length, in characters, of a notification
+	//rendered with RenderNotification(). A warning is logged for notifications longer
+	//than this. This defaults to 160, the length of a single SMS message. Set to -1 to
+	//disable the check.
+	NotificationMaxLength int
+
+	//RedactErrors means a generic error message and a reference ID is returned to the
+	//user when a template cannot be shown, rather than the actual error. The actual
+	//error may contain internal details, such as template names and paths, that should
+	//not be shown to users in production. The actual error is logged along with the
+	//reference ID so that an error reported by a user can be found in your logs. The
+	//reference ID is the request's ID, when known, or a random ID.
+	RedactErrors bool
+
+	//ErrorFunc writes the response when a template cannot be shown, instead of the
+	//default plain text response written via http.Error(). This is useful for returning
+	//a minimal branded HTML page that does not rely on your templates, since your
+	//templates may be what failed. The status is the suggested HTTP status code and
+	//the message is the error, or the redacted message if RedactErrors is set. The func
+	//must set any headers, such as Content-Type, and write the status code itself.
+	ErrorFunc func(w http.ResponseWriter, status int, message string)
+
+	//NotFoundStatus is the HTTP status code returned when the subdirectory or template
+	//being shown does not exist. This defaults to 404 Not Found. Errors that occur when
+	//executing a template always return 500 Internal Server Error.
+	NotFoundStatus int
+
+	//StatusTemplates are the templates shown by ShowStatusPage() for each HTTP status
+	//code, i.e. 404 Not Found or 500 Internal Server Error. This allows your error
+	//pages to use the same layout as the rest of your pages. See templates-status.go.
+	StatusTemplates map[int]TemplateRef
+
+	//ValidateData is called with the data provided to each template before the
+	//template is rendered. If an error is returned, the template is not rendered and an
+	//error is returned or responded with instead. This is useful for making sure certain
+	//templates are always provided the fields they require, with a clear error, rather
+	//than failing partway through rendering. The name includes the extension.
+	ValidateData func(subdir, name string, data interface{}) error
+
+	//FixturesDir is the directory the data provided to each template is saved to, as
+	//JSON, the first time each template is shown. This is only used when Development
+	//is true. This is useful for capturing realistic data for tests. See
+	//templates-fixtures.go.
+	FixturesDir string
+
+	//MaxOutputBytes is the maximum size, in bytes, of the output of rendering a single
+	//template. This prevents an unbounded range or recursive template from exhausting
+	//memory. When set, Show() renders the template fully before writing the response.
+	//Output is not limited if this is 0.
+	MaxOutputBytes int64
+
+	//MaxIncludeDepth is the maximum depth templates can be nested via {{template}}. This
+	//is checked when templates are parsed and recursive templates are not allowed when
+	//this is set. Nesting is not limited if this is 0.
+	MaxIncludeDepth int
+
+	//IgnoreHiddenFiles means files with a name beginning with a period are not parsed
+	//as templates, even if they end in Extension. This is useful for ignoring files
+	//your editor creates alongside your templates, such as lock files.
+	IgnoreHiddenFiles bool
+
+	//IgnoreSymlinks means symlinks in your template directories are not parsed as
+	//templates. By default, symlinks to files are followed and parsed as templates.
+	//This only applies to on-disk files.
+	IgnoreSymlinks bool
+
+	//MaxFiles is the maximum number of template files that can be parsed when building
+	//templates. The number of files is not limited if this is 0.
+	MaxFiles int
+
+	//MaxFileSize is the maximum size, in bytes, of a single template file. The size of
+	//files is not limited if this is 0.
+	MaxFileSize int64
+
+	//MaxTotalBytes is the maximum total size, in bytes, of all template files parsed
+	//when building templates. The total size is not limited if this is 0.
+	MaxTotalBytes int64
+
+	//MaxConcurrentRenders is the maximum number of templates that can be rendered at
+	//once. Renders beyond this limit wait for a render to complete. This is read when
+	//templates are built. Renders are not limited if this is 0.
+	MaxConcurrentRenders int
+
+	//RenderQueueTimeout is the maximum time a render waits to start when
+	//MaxConcurrentRenders renders are in progress. A 503 Service Unavailable response
+	//is returned if the timeout is reached. Renders wait until the request is canceled
+	//if this is 0.
+	RenderQueueTimeout time.Duration
+
+	//BuildProgress is called as each file is parsed when building templates. This is
+	//useful for reporting progress when building a large number of templates takes a
+	//long time, for example to logs or a readiness probe. The total is the number of
+	//files to parse, counting files in the base directory once for each subdirectory
+	//since they are parsed into each subdirectory's templates.
+	BuildProgress func(done, total int, currentPath string)
+
+	//AuditFunc is called each time templates are added, replaced, rolled back, or
+	//rebuilt at runtime, after the change is made. This is useful for keeping a record
+	//of changes for compliance in apps that allow editing templates. See
+	//templates-audit.go.
+	AuditFunc func(e AuditEvent)
+
+	//SmokeTests are the templates rendered against a standby set of templates before
+	//it is promoted to be shown. See templates-standby.go.
+	SmokeTests []SmokeTest
+
+	//DisableRenderCache means the output of templates shown without injected data is
+	//never cached. By default, the output of these templates is cached since it is the
+	//same each time the template is shown. See templates-rendercache.go.
+	DisableRenderCache bool
+
+	//DataKey is the name your data, provided when showing a template, is available at in
+	//templates. This defaults to "InjectedData", i.e. {{.InjectedData}}. When set to a
+	//different name, templates are provided a map rather than a RenderData. See
+	//templates-data.go.
+	DataKey string
+
+	//FlattenInjectedData means your data, provided when showing a template, is provided
+	//to templates as is rather than in a RenderData. This allows your data to be used
+	//at the top level, i.e. {{.UserName}}, but the other fields of RenderData are not
+	//available.
+	FlattenInjectedData bool
+
+	//MergeInjectedMap means your data, when it is a map[string]interface{}, is merged
+	//with the fields of RenderData into a single map provided to templates. This allows
+	//your data to be used at the top level, i.e. {{.UserName}}, with the other fields of
+	//RenderData still available. Keys in your data replace fields of RenderData with
+	//the same name.
+	MergeInjectedMap bool
+
+	//SessionProvider retrieves the session data for a request, for example from
+	//gorilla/sessions or SCS. The session data is provided to templates at {{.Session}}
+	//when using ShowRequest(). If an error is returned, the error is logged and the
+	//page is shown without session data.
+	SessionProvider func(r *http.Request) (map[string]interface{}, error)
+
+	//UserProvider retrieves the user making a request. The user is provided to
+	//templates at {{.User}} when using ShowRequest() and is used by the isAuthenticated
+	//and hasRole funcs. Return nil if no user is logged in. To use the hasRole func,
+	//the user must implement RoleChecker.
+	UserProvider func(r *http.Request) interface{}
+
+	//PreferenceProvider retrieves the preferences of the user making a request, for
+	//example from a cookie or session, such as the user's preferred theme. The
+	//preferences are provided to templates at {{.Prefs}} when using ShowRequest() and
+	//are used by the prefersDark func, i.e. <html class="{{if prefersDark}}dark{{end}}">,
+	//which checks if the ThemePreference key is set to "dark".
+	PreferenceProvider func(r *http.Request) map[string]string
+
+	//Authorizer determines if the user making a request has a permission via the can
+	//func in templates, i.e.: {{if can "invoices:delete"}}. This allows for showing
+	//menus and buttons based on your authorization rules. The user, retrieved using
+	//UserProvider, is provided if set. Permissions are always denied if this is not set
+	//or when no request is known, i.e. when using Show().
+	Authorizer func(r *http.Request, user interface{}, permission string) bool
+
 	//templates holds the list of parsed files constructed into golang templates.
 	//Templates are organized by subdirectory since that is how they are organized on
 	//disk and this allows for filenames, or {{define}} blocks, to only need to be
 	//unique within a subdirectory. This is where a specific template is looked up when
 	//Show() is called to actually show and return the HTML to a user and their browser.
 	templates map[string]*template.Template
+
+	//requestTemplates holds pools of copies of the templates, for each subdirectory
+	//whose templates use request-scoped funcs, that are handed out to requests so that
+	//request-scoped funcs can read the request. See templates-requestfuncs.go.
+	requestTemplates map[string]*requestPool
+
+	//textTemplates holds the same templates as templates but parsed as text templates.
+	//Text templates are parsed when first used, not when Build() is called.
+	textTemplates map[string]*texttemplate.Template
+
+	//files holds the complete paths to each file parsed into each subdirectory's
+	//templates, including the files inherited from the base directory. This is
+	//used to reparse a subdirectory's templates when files are added after Build()
+	//has been called.
+	files map[string][]string
+
+	//sources holds the source of templates added to each subdirectory's templates with
+	//ParseUntrusted(), keyed by template name. These are parsed after the
+	//subdirectory's files.
+	sources map[string]map[string]untrustedSource
+
+	//previousSources holds the source each template added with ParseUntrusted()
+	//replaced, keyed by subdirectory and template name. This is used to roll back a
+	//change with RollbackUntrusted().
+	previousSources map[string]map[string]untrustedSource
+
+	//untrustedTemplates holds unexecuted copies of the templates, for each subdirectory
+	//with templates added with ParseUntrusted(), that are cloned for each render of an
+	//untrusted template so that limits can be enforced per render.
+	untrustedTemplates map[string]*template.Template
+
+	//untrustedLimits holds the limits applied when rendering each template, keyed by
+	//subdirectory and template name, added with ParseUntrusted().
+	untrustedLimits map[string]map[string]UntrustedLimits
+
+	//standby holds templates built with BuildStandby() that are not shown until they
+	//are promoted with Promote().
+	standby *templateSet
+
+	//hash is the hash of the contents of the files found when Build() was called.
+	//This is used to skip rebuilding when Rebuild() is called and no files have
+	//changed.
+	hash string
+
+	//builtParseFingerprint is the fingerprint of the config fields that affect parsing
+	//when Build() was called, see parseFingerprint(). This is used so that Rebuild() does
+	//not skip rebuilding when these fields changed.
+	builtParseFingerprint string
+
+	//fileHashes holds the hash and size of each file found when Build() was called,
+	//keyed by the file's path relative to BasePath. See Diff() and Manifest().
+	fileHashes map[string]ManifestFile
+
+	//includes holds the templates each template includes, keyed by subdirectory and
+	//template name. See Manifest().
+	includes map[string]map[string][]string
+
+	//buildTime is the time when templates were last built.
+	buildTime time.Time
+
+	//refs counts the renders in progress using the active templates and retiring
+	//holds the counters for replaced templates still being used by renders. See
+	//templates-grace.go.
+	refs     *setRefs
+	retiring []*setRefs
+
+	//modTimes holds the newest modification time of the files parsed into each
+	//subdirectory's templates. This is used for handling conditional GET requests.
+	modTimes map[string]time.Time
+
+	//nodeCounts holds the number of parse tree nodes in each subdirectory's templates.
+	//This is used for reporting the memory used by templates.
+	nodeCounts map[string]int
+
+	//named holds each subdirectory's templates keyed by template name. This is used
+	//to find the template to show without looking it up by name on every render.
+	named map[string]map[string]*template.Template
+
+	//memoizableSubDirs holds the subdirectories whose templates' output can be cached
+	//when shown without injected data.
+	memoizableSubDirs map[string]bool
+
+	//rendered caches the output of templates shown without injected data.
+	rendered map[renderCacheKey][]byte
+
+	//prerendered holds the output of pages rendered with SchedulePrerender() and
+	//prerenderedAt holds the time each page's output last changed.
+	prerendered   map[PrerenderPage][]byte
+	prerenderedAt map[PrerenderPage]time.Time
+
+	//configChangedAt holds the time the config fields provided to templates were last
+	//changed using a Set method, such as SetAppVersion(), or templates were first built.
+	//This is used for handling conditional GET requests.
+	configChangedAt time.Time
+
+	//inflight holds the renders in progress of templates whose output will be cached.
+	inflight map[renderCacheKey]*renderCall
+
+	//renderSlots limits the number of concurrent renders based upon
+	//MaxConcurrentRenders.
+	renderSlots chan struct{}
+
+	//staticFiles caches the contents of static files read from StaticPath.
+	staticFiles map[string][]byte
+
+	//generation is incremented each time templates are modified, by Build() or
+	//ParseExtra(). This is used to make sure templates parsed while holding no lock
+	//are not cached after templates were modified.
+	generation uint64
+
+	//captured holds the templates whose data has been saved to FixturesDir, keyed by
+	//fixture path.
+	captured map[string]bool
+
+	//maintenance is 1 when maintenance mode is on, see SetMaintenance(). This is
+	//accessed atomically.
+	maintenance int32
+
+	//mu protects templates and files since they can be modified at runtime, after
+	//Build() was called, while templates are being shown.
+	mu *sync.RWMutex
 }
 
 //defaults
 const (
-	defaultExtension = "html"
+	defaultExtension       = "html"
+	defaultRequestIDHeader = "X-Request-ID"
 )
 
 //errors
@@ -162,16 +646,34 @@ var (
 	//ErrNoEmbeddedFilesProvided is returned when a user is using a config with embedded files
 	//but no embedded files were provided.
 	ErrNoEmbeddedFilesProvided = errors.New("templates: no embedded files provided")
-)
 
-//config is the package level saved config. This stores your config when you want to store
-//it for global use. It is populated when you use one of the Default...Config() funcs.
-var config Config
+	//ErrUnknownSubDir is returned when a subdirectory is provided that was not
+	//built with Build().
+	ErrUnknownSubDir = errors.New("templates: unknown subdirectory, it was not built")
+
+	//ErrTemplateNotFound is returned when a template cannot be found in a
+	//subdirectory.
+	ErrTemplateNotFound = errors.New("templates: template not found")
+
+	//ErrNoPathsProvided is returned when ParseExtra() is called without any paths
+	//to files to parse.
+	ErrNoPathsProvided = errors.New("templates: no paths to files provided")
+
+	//ErrInvalidData is returned when ValidateData returns an error for the data
+	//provided to a template. The returned error wraps this error and includes the
+	//error from ValidateData.
+	ErrInvalidData = errors.New("templates: invalid data for template")
+
+	//ErrInvalidGroup is returned when a group has a blank name, a name that is the
+	//same as a subdirectory, or a blank list of subdirectories.
+	ErrInvalidGroup = errors.New("templates: invalid group, name or subdirectories are invalid")
+)
 
 //NewConfig returns a config for managing your templates with some defaults set.
 func NewConfig() *Config {
 	return &Config{
 		Extension: defaultExtension,
+		mu:        &sync.RWMutex{},
 	}
 }
 
@@ -179,7 +681,7 @@ func NewConfig() *Config {
 //NewConfig() and saves the config to the package.
 func DefaultConfig() {
 	cfg := NewConfig()
-	config = *cfg
+	SetDefault(cfg)
 }
 
 //NewOnDiskConfig returns a config for managing your templates when the source files are
@@ -190,6 +692,7 @@ func NewOnDiskConfig(basePath string, subdirs []string) *Config {
 		SubDirs:   subdirs,
 		Extension: defaultExtension,
 		templates: make(map[string]*template.Template),
+		mu:        &sync.RWMutex{},
 	}
 }
 
@@ -198,7 +701,7 @@ func NewOnDiskConfig(basePath string, subdirs []string) *Config {
 func DefaultOnDiskConfig(basePath string, subdirs []string) {
 	cfg := NewOnDiskConfig(basePath, subdirs)
 	cfg.FuncMap = DefaultFuncMap()
-	config = *cfg
+	SetDefault(cfg)
 }
 
 //NewEmbeddedConfig returns a config for managing your templates when the source files are
@@ -212,6 +715,7 @@ func NewEmbeddedConfig(embeddedFS embed.FS, basePath string, subdirs []string) *
 		UseEmbedded: true,
 		EmbeddedFS:  embeddedFS,
 		templates:   make(map[string]*template.Template),
+		mu:          &sync.RWMutex{},
 	}
 }
 
@@ -220,7 +724,28 @@ func NewEmbeddedConfig(embeddedFS embed.FS, basePath string, subdirs []string) *
 func DefaultEmbeddedConfig(embeddedFS embed.FS, basePath string, subdirs []string) {
 	cfg := NewEmbeddedConfig(embeddedFS, basePath, subdirs)
 	cfg.FuncMap = DefaultFuncMap()
-	config = *cfg
+	SetDefault(cfg)
+}
+
+//NewFSConfig returns a config for managing your templates when the source files are
+//stored in a filesystem, such as a zip archive or an fstest.MapFS. See templates-fs.go.
+func NewFSConfig(fsys fs.FS, basePath string, subdirs []string) *Config {
+	return &Config{
+		BasePath:  basePath,
+		SubDirs:   subdirs,
+		Extension: defaultExtension,
+		FS:        fsys,
+		templates: make(map[string]*template.Template),
+		mu:        &sync.RWMutex{},
+	}
+}
+
+//DefaultFSConfig initializes the package level config with the filesystem, path, and
+//directories provided and some defaults.
+func DefaultFSConfig(fsys fs.FS, basePath string, subdirs []string) {
+	cfg := NewFSConfig(fsys, basePath, subdirs)
+	cfg.FuncMap = DefaultFuncMap()
+	SetDefault(cfg)
 }
 
 //validate handles validation of a provided config.
@@ -234,7 +759,7 @@ func (c *Config) validate() (err error) {
 	//Check that BasePath exists. This only needs to be done for on-disk configurations
 	//since we assume that if you are using embedded files you know your directory
 	//structure and what subdirectories exist.
-	if !c.UseEmbedded {
+	if c.fsys() == nil {
 		if _, err := os.Stat(c.BasePath); os.IsNotExist(err) {
 			return err
 		}
@@ -245,7 +770,7 @@ func (c *Config) validate() (err error) {
 	//template files. This only needs to be done for on-disk configurations since we
 	//assume that if you are using embedded files you know your directory structure and
 	//what subdirectories exist.
-	if !c.UseEmbedded {
+	if c.fsys() == nil {
 		for idx, p := range c.SubDirs {
 			p = strings.TrimSpace(p)
 			if p == "" {
@@ -254,6 +779,13 @@ func (c *Config) validate() (err error) {
 
 			p = filepath.FromSlash(p)
 
+			//Glob patterns are expanded, and matching subdirectories found, when
+			//templates are built.
+			if isGlob(p) {
+				c.SubDirs[idx] = p
+				continue
+			}
+
 			if _, err := os.Stat(filepath.Join(c.BasePath, p)); os.IsNotExist(err) {
 				return err
 			}
@@ -262,6 +794,12 @@ func (c *Config) validate() (err error) {
 		}
 	}
 
+	//Check the groups of subdirectories.
+	err = c.validateGroups()
+	if err != nil {
+		return
+	}
+
 	//Make sure a filename extension was provided, if not use the default.
 	c.Extension = strings.TrimSpace(c.Extension)
 	if c.Extension == "" {
@@ -269,7 +807,7 @@ func (c *Config) validate() (err error) {
 	}
 
 	//If user is using embedded files, make sure something was provided.
-	if c.UseEmbedded && c.EmbeddedFS == (embed.FS{}) {
+	if c.UseEmbedded && c.FS == nil && c.EmbeddedFS == (embed.FS{}) {
 		return ErrNoEmbeddedFilesProvided
 	}
 
@@ -284,14 +822,194 @@ func (c *Config) validate() (err error) {
 //reference a template from another subdirectory; this allows for templates that use the same
 //name ({{define}}) or same filename to exist and be used.
 func (c *Config) Build() (err error) {
+	set, err := c.buildSet()
+	if err != nil {
+		return
+	}
+
+	//Save the built templates.
+	if c.activate(set) {
+		c.audit(AuditEvent{Action: AuditRebuild, Hash: set.hash})
+	}
+
+	return
+}
+
+//templateSet is a set of built templates for every subdirectory, before it is saved to
+//the config to be shown.
+type templateSet struct {
+	templates         map[string]*template.Template
+	requestTemplates  map[string]*requestPool
+	files             map[string][]string
+	hash              string
+	parseFingerprint  string
+	fileHashes        map[string]ManifestFile
+	includes          map[string]map[string][]string
+	modTimes          map[string]time.Time
+	nodeCounts        map[string]int
+	named             map[string]map[string]*template.Template
+	memoizableSubDirs map[string]bool
+}
+
+//buildSet finds and parses the template files into a new set of templates. The set is
+//not saved to the config; see activate().
+func (c *Config) buildSet() (set *templateSet, err error) {
 	//validate the config
 	err = c.validate()
 	if err != nil {
 		return
 	}
 
-	//empty out field that holds built templates in case Build() is called more than once.
-	c.templates = make(map[string]*template.Template)
+	//Make sure the lock exists in case this config was not created with one of the
+	//New...Config() funcs.
+	if c.mu == nil {
+		c.mu = &sync.RWMutex{}
+	}
+
+	//Note the fields that affect parsing before parsing in case the fields are
+	//modified while parsing.
+	parseFingerprint := c.parseFingerprint()
+
+	//Find the files to parse for each subdirectory.
+	files, err := c.gatherFiles()
+	if err != nil {
+		return
+	}
+
+	//Make sure the files are within the configured limits before reading them.
+	err = c.checkFileLimits(files)
+	if err != nil {
+		return
+	}
+
+	//Check for markup blocked by a strict Content-Security-Policy, if needed.
+	err = c.lintCSP(files)
+	if err != nil {
+		return
+	}
+
+	//Parse the templates for each subdirectory. Built templates are stored in a new
+	//map and only saved to the config once all templates are parsed successfully. This
+	//way if Build() is called more than once, and an error occurs, the previously built
+	//templates can still be used.
+	//
+	//The templates in the base directory are parsed with a blank subdirectory name so
+	//that when templates are shown a user can provide Show(w, "", "template name", nil).
+	//The templates in each subdirectory are parsed with the subdirectory name so that
+	//when templates are shown a user can provide Show(w, "subdir", "template name", nil).
+	set = &templateSet{
+		templates:         make(map[string]*template.Template, len(files)),
+		requestTemplates:  make(map[string]*requestPool),
+		files:             files,
+		modTimes:          make(map[string]time.Time, len(files)),
+		nodeCounts:        make(map[string]int, len(files)),
+		named:             make(map[string]map[string]*template.Template, len(files)),
+		memoizableSubDirs: make(map[string]bool, len(files)),
+		includes:          make(map[string]map[string][]string, len(files)),
+	}
+	configFuncNames := c.configFuncNames()
+	progress := c.buildProgress(files)
+	for subDir, paths := range files {
+		t, innerErr := c.parseFiles(paths, progress)
+		if innerErr != nil {
+			if subDir == "" {
+				log.Println("templates.Build", "error parsing files at base path", innerErr)
+			} else {
+				log.Println("templates.Build", "error parsing files at subdir '"+subDir+"'", innerErr)
+			}
+			return nil, innerErr
+		}
+		set.nodeCounts[subDir] = countNodes(t)
+		set.memoizableSubDirs[subDir] = !usesFuncs(t, configFuncNames)
+		set.includes[subDir] = templateIncludes(t)
+
+		executable, requests, innerErr := c.splitForRequestFuncs(t)
+		if innerErr != nil {
+			return nil, innerErr
+		}
+		set.templates[subDir] = executable
+		set.named[subDir] = namedTemplates(executable)
+		if requests != nil {
+			set.requestTemplates[subDir] = requests
+		}
+
+		modTime, innerErr := c.newestModTime(paths)
+		if innerErr != nil {
+			return nil, innerErr
+		}
+		set.modTimes[subDir] = modTime
+	}
+
+	//Calculate the hash of the source files' contents for determining if files have
+	//changed when Rebuild() is called.
+	set.hash, set.fileHashes, err = c.hashFiles(files)
+	if err != nil {
+		return nil, err
+	}
+	set.parseFingerprint = parseFingerprint
+
+	return
+}
+
+//activate saves a set of built templates to the config so that they are shown, all at
+//once, replacing any existing templates. True is returned if templates were already
+//built, meaning templates were rebuilt.
+func (c *Config) activate(set *templateSet) (rebuilt bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	rebuilt = c.hash != ""
+	c.retireRefs(newSetRefs())
+	c.templates = set.templates
+	c.requestTemplates = set.requestTemplates
+	c.files = set.files
+	c.sources = nil
+	c.previousSources = nil
+	c.untrustedTemplates = nil
+	c.untrustedLimits = nil
+	c.hash = set.hash
+	c.builtParseFingerprint = set.parseFingerprint
+	c.fileHashes = set.fileHashes
+	c.includes = set.includes
+	c.buildTime = time.Now()
+	if c.configChangedAt.IsZero() {
+		//Config fields may have been set directly before building, and differ from the
+		//last time your app was run.
+		c.configChangedAt = c.buildTime
+	}
+	c.modTimes = set.modTimes
+	c.nodeCounts = set.nodeCounts
+	c.named = set.named
+	c.memoizableSubDirs = set.memoizableSubDirs
+	c.renderSlots = c.newRenderSlots()
+	c.rendered = nil
+	c.prerendered = nil
+	c.prerenderedAt = nil
+	c.textTemplates = nil
+	c.staticFiles = nil
+	c.generation++
+	return
+}
+
+//Build builds the templates using the default package level config.
+func Build() (err error) {
+	err = Default().Build()
+	return
+}
+
+//gatherFiles builds the list of complete paths to each file that will be parsed for
+//each subdirectory. The list of files for each subdirectory includes the files from
+//the base directory for inheritance. The files in the base directory are returned
+//with a blank subdirectory name. Subdirectories without any template files are not
+//returned.
+func (c *Config) gatherFiles() (files map[string][]string, err error) {
+	files = make(map[string][]string)
+
+	//Read the patterns of files to ignore, if an ignore file exists.
+	ignore, err := c.readIgnoreFile()
+	if err != nil {
+		return
+	}
 
 	//Build complete paths to each file in the root directory. This list of paths will be
 	//appended to the list of files from each subdirectory (for inheritance). These files
@@ -300,40 +1018,34 @@ func (c *Config) Build() (err error) {
 	if err != nil {
 		return
 	}
-
-	//Parse the templates in the base directory since the user may have not provided any
-	//subdirectories. These templates are parsed with a blank subdirectory name so that
-	//when templates are shown a user can provide Show(w, "", "template name", nil).
-	//Note the template.New("") with the blank template name. This is needed so that we
-	//can add the FuncMap to the template files we are about to parse.
+	baseFilePaths = c.filterIgnored(ignore, baseFilePaths)
 	if len(baseFilePaths) > 0 {
-		t, innerErr := template.New("").Funcs(c.FuncMap).ParseFiles(baseFilePaths...)
-		if innerErr != nil {
-			log.Println("templates.Build", "error parsing files at base path", innerErr)
-			return innerErr
-		}
-		c.templates[""] = t
+		files[""] = baseFilePaths
 	}
 
-	//Build complete paths to each file in each subdirectory and parse the templates in
-	//each after appending the filepaths from the base directory. This is similar to how
-	//the base files were handled above except that we inheret the base files into each
-	//subdirectory and we parse each subdirectory independently from each other.
-	for _, subDir := range c.SubDirs {
+	//Build complete paths to each file in each subdirectory and append the filepaths
+	//from the base directory. This is similar to how the base files were handled above
+	//except that we inheret the base files into each subdirectory.
+	subDirs, err := c.subDirs()
+	if err != nil {
+		return
+	}
+	for _, subDir := range subDirs {
 		//When subdirectory(ies) are provided, each is only a subdirectory name(s), not a
 		//complete path(s). We have the build the complete path to each subdirectory first.
 		//Note that we have to handle paths specially for embedded files since the path
 		//separator is always "/" even on Windows.
 		completePathToSubdDir := filepath.Join(c.BasePath, subDir)
-		if c.UseEmbedded {
+		if c.fsys() != nil {
 			completePathToSubdDir = filepath.ToSlash(completePathToSubdDir)
 		}
 
 		//Build complete paths to each file in the subdirectory.
 		subdirFilepaths, innerErr := c.buildPathsToFiles(completePathToSubdDir)
 		if innerErr != nil {
-			return innerErr
+			return nil, innerErr
 		}
+		subdirFilepaths = c.filterIgnored(ignore, subdirFilepaths)
 
 		//Skip this subdirectory if no template files are in it.
 		if len(subdirFilepaths) == 0 {
@@ -341,27 +1053,279 @@ func (c *Config) Build() (err error) {
 		}
 
 		//Add the base file paths to the subdirectory's file for inheritance.
-		subdirFilepaths = append(subdirFilepaths, baseFilePaths...)
-
-		//Parse the templates in the subdirectory. These templates are parsed with the
-		//subdirecotry name so that when templates are shown a user can provide
-		//Show(w, "subdir", "template name", nil).
-		//Note the template.New("") with the blank template name. This is needed so that we
-		//can add the FuncMap to the template files we are about to parse.
-		t, innerErr := template.New("").Funcs(c.FuncMap).ParseFiles(subdirFilepaths...)
+		files[subDir] = append(subdirFilepaths, baseFilePaths...)
+	}
+
+	//Build complete paths to each file in each group of subdirectories.
+	groupFiles, err := c.gatherGroupFiles(ignore, baseFilePaths)
+	if err != nil {
+		return
+	}
+	for name, paths := range groupFiles {
+		files[name] = paths
+	}
+
+	return
+}
+
+//parseFiles reads and parses each file at the provided paths into a single template.
+//Each file is parsed into a template named by the file's name, the same as
+//template.ParseFiles() does, however, the files are read from on-disk or embedded
+//files based upon the config. When the same filename is provided more than once,
+//the last file provided is used.
+//Note the template.New("") with the blank template name. This is needed so that we
+//can add the FuncMap to the template files we are about to parse.
+//If progress is provided, it is called after each file is parsed.
+func (c *Config) parseFiles(paths []string, progress func(path string)) (t *template.Template, err error) {
+	t = c.bindTryTemplate(template.New("").Funcs(c.funcMap()))
+
+	//Parse the contrib partials first so that they can be replaced by your files.
+	err = c.parseContrib(t)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range paths {
+		b, innerErr := c.readFile(p)
+		if innerErr != nil {
+			return nil, innerErr
+		}
+
+		b, innerErr = c.transform(p, b)
 		if innerErr != nil {
-			log.Println("templates.Build", "error parsing files at subdir '"+subDir+"'", innerErr)
-			return innerErr
+			return nil, innerErr
 		}
-		c.templates[subDir] = t
+
+		//Skip files excluded by their build directive.
+		b, include := c.applyBuildDirectives(b)
+		if include {
+			_, innerErr = t.New(filepath.Base(p)).Parse(c.source(b))
+			if innerErr != nil {
+				return nil, innerErr
+			}
+		}
+
+		if progress != nil {
+			progress(p)
+		}
+	}
+
+	trees := make(map[string]*parse.Tree)
+	for _, tmpl := range t.Templates() {
+		trees[tmpl.Name()] = tmpl.Tree
+	}
+	err = c.checkIncludeDepth(trees)
+	if err != nil {
+		return nil, err
 	}
 
 	return
 }
 
-//Build builds the templates using the default package level config.
-func Build() (err error) {
-	err = config.Build()
+//buildProgress returns the func called after each file is parsed when building
+//templates. This calls BuildProgress with the number of files parsed so far. nil is
+//returned if BuildProgress is not set.
+func (c *Config) buildProgress(files map[string][]string) func(path string) {
+	if c.BuildProgress == nil {
+		return nil
+	}
+
+	total := 0
+	for _, paths := range files {
+		total += len(paths)
+	}
+
+	done := 0
+	return func(path string) {
+		done++
+		c.BuildProgress(done, total, path)
+	}
+}
+
+//funcMap returns the funcs used when parsing templates. This is the FuncMap from the
+//config plus the funcs that are always available, the funcs that use the config, and
+//the request-scoped funcs. Funcs in the config's FuncMap replace the funcs that are
+//always available if the same name is used.
+func (c *Config) funcMap() template.FuncMap {
+	fm := textFuncMap()
+	for k, v := range c.configFuncMap() {
+		fm[k] = v
+	}
+	for k, v := range c.requestFuncMap(&requestBinding{}) {
+		fm[k] = v
+	}
+	for k, v := range c.FuncMap {
+		fm[k] = v
+	}
+
+	return fm
+}
+
+//configFuncMap returns the funcs that use values from the config. The config's values
+//are read when each func is called, not when templates are parsed, so that changes to
+//the config are reflected without rebuilding.
+func (c *Config) configFuncMap() template.FuncMap {
+	return template.FuncMap{
+		"isEnv": func(env string) bool {
+			return strings.EqualFold(strings.TrimSpace(env), c.Environment)
+		},
+		"srcSet": func(base string, widths ...int) template.Srcset {
+			return srcSet(base, widths, c.CacheBustingFilePairs)
+		},
+		"inlineSVG": c.inlineSVG,
+		"inlineCSS": c.inlineCSS,
+		"signURL":   c.signURLFunc,
+	}
+}
+
+//readFile reads a file at the given path from on-disk files or the config's
+//filesystem, see fsys().
+func (c *Config) readFile(path string) (b []byte, err error) {
+	//Make sure that path to files in a filesystem always uses forward slash separators
+	//per io/fs package docs.
+	if fsys := c.fsys(); fsys != nil {
+		return fs.ReadFile(fsys, filepath.ToSlash(path))
+	}
+
+	return os.ReadFile(path)
+}
+
+//ParseExtra parses additional files into an already built subdirectory's templates.
+//This is useful for adding templates at runtime, after Build() was called, such as
+//for plugins or generated templates, without having to rebuild every subdirectory.
+//The paths must be complete paths to each file, the same as paths built from
+//BasePath, and are read from on-disk or embedded files based on the config. Files
+//added are inherited into the subdirectory the same as the files in the base
+//directory are; a file with the same name as an existing file will replace it.
+//Files added are kept until Build() is called again.
+//
+//The subdirectory's templates are reparsed from the subdirectory's existing files
+//plus the provided files since golang templates cannot be modified once they have
+//been executed. If an error occurs, the existing templates are left untouched.
+func (c *Config) ParseExtra(subdir string, paths ...string) (err error) {
+	if len(paths) == 0 {
+		return ErrNoPathsProvided
+	}
+	if c.mu == nil {
+		return ErrUnknownSubDir
+	}
+
+	//Record the change once the lock is released so that AuditFunc can use the
+	//config.
+	var events []AuditEvent
+	defer func() {
+		for _, e := range events {
+			c.audit(e)
+		}
+	}()
+
+	//Hold the write lock while reparsing so that ParseExtra() can be called by
+	//multiple goroutines without files being lost.
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	existing, ok := c.files[subdir]
+	if !ok {
+		return ErrUnknownSubDir
+	}
+
+	//Note which files replace an existing template with the same name.
+	for _, p := range paths {
+		e := AuditEvent{Action: AuditAdd, SubDir: subdir, Templates: []string{p}}
+		if _, ok := c.named[subdir][filepath.Base(p)]; ok {
+			e.Action = AuditReplace
+		}
+		events = append(events, e)
+	}
+
+	//Build the list of files to parse. A new slice is used so that the existing list
+	//of files is not modified if an error occurs.
+	subdirFilepaths := make([]string, 0, len(existing)+len(paths))
+	subdirFilepaths = append(subdirFilepaths, existing...)
+	subdirFilepaths = append(subdirFilepaths, paths...)
+
+	err = c.reparse(subdir, subdirFilepaths, c.sources[subdir])
+	if err != nil {
+		log.Println("templates.ParseExtra", "error parsing files at subdir '"+subdir+"'", err)
+		events = nil
+		return
+	}
+
+	return
+}
+
+//reparse parses a subdirectory's templates from the provided files and sources, see
+//ParseUntrusted(), and replaces the subdirectory's existing templates. If an error
+//occurs, the existing templates are left untouched. This must be called while holding
+//the write lock.
+func (c *Config) reparse(subdir string, paths []string, sources map[string]untrustedSource) (err error) {
+	t, err := c.parseFiles(paths, nil)
+	if err != nil {
+		return
+	}
+	includes := templateIncludes(t)
+
+	limits, err := parseSources(t, sources)
+	if err != nil {
+		return
+	}
+
+	//Keep an unexecuted copy of the templates for rendering untrusted templates with
+	//limits.
+	var untrusted *template.Template
+	if len(limits) > 0 {
+		untrusted, err = t.Clone()
+		if err != nil {
+			return
+		}
+	}
+
+	nodeCount := countNodes(t)
+	memoizable := !usesFuncs(t, c.configFuncNames())
+
+	executable, requests, err := c.splitForRequestFuncs(t)
+	if err != nil {
+		return
+	}
+
+	modTime, err := c.newestModTime(paths)
+	if err != nil {
+		return
+	}
+
+	c.templates[subdir] = executable
+	c.named[subdir] = namedTemplates(executable)
+	c.memoizableSubDirs[subdir] = memoizable
+	if requests != nil {
+		c.requestTemplates[subdir] = requests
+	} else {
+		delete(c.requestTemplates, subdir)
+	}
+	if untrusted != nil {
+		if c.untrustedTemplates == nil {
+			c.untrustedTemplates = make(map[string]*template.Template)
+			c.untrustedLimits = make(map[string]map[string]UntrustedLimits)
+		}
+		c.untrustedTemplates[subdir] = untrusted
+		c.untrustedLimits[subdir] = limits
+	} else {
+		delete(c.untrustedTemplates, subdir)
+		delete(c.untrustedLimits, subdir)
+	}
+	c.files[subdir] = paths
+	c.modTimes[subdir] = modTime
+	c.nodeCounts[subdir] = nodeCount
+	c.includes[subdir] = includes
+	delete(c.textTemplates, subdir)
+	c.clearPrerendered(subdir)
+	c.generation++
+	return
+}
+
+//ParseExtra parses additional files into a subdirectory using the default package
+//level config.
+func ParseExtra(subdir string, paths ...string) (err error) {
+	err = Default().ParseExtra(subdir, paths...)
 	return
 }
 
@@ -373,15 +1337,17 @@ func (c *Config) buildPathsToFiles(pathToDirectory string) (paths []string, err
 	//Determine the correct ReadDir func. This is used to handle reading files stored
 	//on disk or files that are embedded in the app's executable.
 	var readFunc func(string) ([]fs.DirEntry, error)
-	if c.UseEmbedded {
-		readFunc = c.EmbeddedFS.ReadDir
+	if fsys := c.fsys(); fsys != nil {
+		readFunc = func(name string) ([]fs.DirEntry, error) {
+			return fs.ReadDir(fsys, name)
+		}
 	} else {
 		readFunc = os.ReadDir
 	}
 
 	//Build complete paths to each file in the directory.
 	//Make sure that path to embedded files always uses forward slash separators per embed package docs.
-	if c.UseEmbedded {
+	if c.fsys() != nil {
 		pathToDirectory = filepath.ToSlash(pathToDirectory)
 	}
 	files, err := readFunc(pathToDirectory)
@@ -394,6 +1360,28 @@ func (c *Config) buildPathsToFiles(pathToDirectory string) (paths []string, err
 			continue
 		}
 
+		//Ignore hidden files, if needed. These are typically files created by your
+		//editor, such as lock or backup files, that may end in the required extension.
+		if c.IgnoreHiddenFiles && strings.HasPrefix(f.Name(), ".") {
+			continue
+		}
+
+		//Ignore symlinks, if needed. Otherwise, symlinks are followed but symlinks to
+		//directories are ignored, the same as directories.
+		if f.Type()&fs.ModeSymlink != 0 {
+			if c.IgnoreSymlinks {
+				continue
+			}
+
+			fi, innerErr := c.stat(filepath.Join(pathToDirectory, f.Name()))
+			if innerErr != nil {
+				return nil, innerErr
+			}
+			if fi.IsDir() {
+				continue
+			}
+		}
+
 		//Ignore files that don't end in the required extension. Not just checking for
 		//existance of the extension (using strings.Contains) since the same set of
 		//characters may exist elsewhere in the file's name.
@@ -404,7 +1392,7 @@ func (c *Config) buildPathsToFiles(pathToDirectory string) (paths []string, err
 		//Add complete path to template to list of paths. Have to handle path to embedded
 		//files specially since they always use a "/" separator, even on Windows.
 		completePathToFile := filepath.Join(pathToDirectory, f.Name())
-		if c.UseEmbedded {
+		if c.fsys() != nil {
 			completePathToFile = filepath.ToSlash(completePathToFile)
 		}
 
@@ -418,25 +1406,84 @@ func (c *Config) buildPathsToFiles(pathToDirectory string) (paths []string, err
 //by taking a subdirectory's name subdir and the name of a template (a filename) templateName
 //and looks up the associated template that was parsed earlier returning it with any
 //injected data and cache busting files.
-//Note that the user provided injectedData will be available at {{.Data}} in HTML templates.
+//Note that the user provided injectedData will be available at {{.InjectedData}} in HTML
+//templates.
 func (c *Config) Show(w http.ResponseWriter, subdir, templateName string, injectedData interface{}) {
-	//Get data to render html template.
-	//We provide some of the config defined data as well as user-provided data via
-	//the injectedData field. The injectedData field can hold any data.
-	//We aren't just reusing the Config{} struct here since we want better control
-	//over what data is used in the rendering process. Plus, not all the information
-	//stored in a Config{} object is needed here.
-	data := struct {
-		Development    bool
-		UseLocalFiles  bool
-		CacheBustFiles map[string]string
-		InjectedData   interface{}
-	}{
-		Development:    c.Development,
-		UseLocalFiles:  c.UseLocalFiles,
-		CacheBustFiles: c.CacheBustingFilePairs,
-		InjectedData:   injectedData,
+	c.show(w, nil, subdir, templateName, injectedData)
+}
+
+//ShowRequest renders a template as HTML the same as Show() but is aware of the request
+//being responded to. This allows for data about the request, such as the request's ID,
+//to be provided to templates and used in logging.
+func (c *Config) ShowRequest(w http.ResponseWriter, r *http.Request, subdir, templateName string, injectedData interface{}) {
+	c.show(w, r, subdir, templateName, injectedData)
+}
+
+//Render renders a template as HTML to the user's browser the same as Show() but returns
+//any error rather than writing an error response. This allows you to decide how to
+//respond, for example by showing your own error page. The template is rendered fully
+//before anything is written so nothing is written to w if an error is returned. Use
+//errors.Is() with ErrUnknownSubDir or ErrTemplateNotFound to check for missing
+//templates.
+func (c *Config) Render(w http.ResponseWriter, subdir, templateName string, injectedData interface{}) error {
+	return c.RenderRequest(w, nil, subdir, templateName, injectedData)
+}
+
+//RenderRequest renders a template the same as Render() but is aware of the request
+//being responded to, the same as ShowRequest().
+func (c *Config) RenderRequest(w http.ResponseWriter, r *http.Request, subdir, templateName string, injectedData interface{}) error {
+	err := c.serve(w, r, subdir, templateName, injectedData, true)
+
+	var se *showError
+	if errors.As(err, &se) {
+		//Make sure the response you write for the error is not cached.
+		w.Header().Del("Cache-Control")
+		w.Header().Del("Last-Modified")
+		return se.err
+	}
+
+	return err
+}
+
+//show handles rendering a template for Show() and ShowRequest(). The request, r, may be
+//nil if the request is not known. Errors are written to w.
+func (c *Config) show(w http.ResponseWriter, r *http.Request, subdir, templateName string, injectedData interface{}) {
+	err := c.serve(w, r, subdir, templateName, injectedData, false)
+
+	var se *showError
+	if errors.As(err, &se) {
+		c.writeError(w, se.requestID, se.status, se.msg, se.err)
 	}
+}
+
+//showError is an error that occured while serving a template, along with the status
+//code and message used when writing the error as the response.
+type showError struct {
+	requestID string
+	status    int
+	msg       string
+	err       error
+}
+
+func (e *showError) Error() string {
+	return e.err.Error()
+}
+
+func (e *showError) Unwrap() error {
+	return e.err
+}
+
+//serve renders a template for show() and RenderRequest(), returning a *showError if an
+//error occurs rather than writing the error. If buffer is true, the template is
+//rendered fully before anything is written so that nothing is written if an error
+//occurs.
+func (c *Config) serve(w http.ResponseWriter, r *http.Request, subdir, templateName string, injectedData interface{}, buffer bool) error {
+	//Get the request's ID for providing to templates and logging. This will be blank
+	//if no request was provided.
+	requestID := c.requestID(r)
+
+	//Retrieve data from the request once for use by funcs and templates.
+	r = c.withRequestValues(r)
 
 	//Add the extension to the template (file) name if needed. This handles instances
 	//where Show() was called without the extension (which is semi-expected since it
@@ -453,47 +1500,444 @@ func (c *Config) Show(w http.ResponseWriter, subdir, templateName string, inject
 	//here (return errror.New...), we don't because we assume that anyone developing
 	//using this package is acutely aware of their subdirectory name(s) and will test
 	//this prior.
-	t, ok := c.templates[subdir]
+	v, ok, releaseView := c.acquireView(subdir)
+	defer releaseView()
+	t, named := v.t, v.named
 	if !ok {
-		err := errors.New("templates.Show: invalid subdirectory '" + subdir + "'")
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		err := fmt.Errorf("templates.Show: invalid subdirectory '%s', %w", subdir, ErrUnknownSubDir)
+		return &showError{requestID, c.notFoundStatus(), "templates.Show: error during lookup", err}
+	}
+	if named[templateName] == nil {
+		err := fmt.Errorf("templates.Show: template '%s' not found in subdirectory '%s', %w", templateName, subdir, ErrTemplateNotFound)
+		return &showError{requestID, c.notFoundStatus(), "templates.Show: error during lookup", err}
 	}
 
-	if err := t.ExecuteTemplate(w, templateName, data); err != nil {
+	//Make sure the template is provided the data it requires.
+	if err := c.validateData(subdir, templateName, injectedData); err != nil {
+		return &showError{requestID, http.StatusInternalServerError, "templates.Show: error validating data", err}
+	}
+
+	//Save the data as a fixture, if needed.
+	c.captureFixture(subdir, templateName, injectedData)
+
+	//Render templates written by untrusted authors with their limits.
+	if _, ok := v.untrustedLimits[templateName]; ok {
+		return c.showUntrusted(w, r, requestID, v, subdir, templateName, injectedData)
+	}
+
+	//Bind request-scoped funcs to the request, if needed. This uses a copy of the
+	//templates so the template to show must be looked up by name from the copy.
+	bound := t
+	if v.requests != nil && r != nil {
+		b, err := v.requests.get(r)
+		if err != nil {
+			return &showError{requestID, http.StatusInternalServerError, "templates.Show: error binding request funcs", err}
+		}
+		defer v.requests.put(b)
+		bound = b.t
+	}
+
+	//Use the variant of the template for the request's device, if one exists.
+	requestedName := templateName
+	templateName = c.variant(w, r, bound, templateName)
+	tmpl := named[templateName]
+	if bound != t {
+		tmpl = bound.Lookup(templateName)
+	}
+
+	//Set headers based on the config and template being shown.
+	c.setHeaders(w, subdir, templateName)
+
+	//Handle conditional requests, if enabled, and stop if the page has not been
+	//modified. Pages using data or funcs based upon the request are skipped since the
+	//output may differ for each request even though nothing else changed.
+	if c.ConditionalGET && r != nil && bound == t && !c.usesRequestData(r) {
+		lastModified := c.outputModTime(v.modTime, subdir, requestedName, injectedData)
+		if c.notModified(w, r, lastModified, injectedData) {
+			return nil
+		}
+	}
+
+	//Use the prerendered output for the page, if it exists. Prerendered output is not
+	//used if funcs were bound to the request since the output may differ.
+	if injectedData == nil && bound == t && !c.usesRequestData(r) {
+		if b, ok := c.prerenderedPage(subdir, requestedName); ok {
+			w.Write(b)
+			return nil
+		}
+	}
+
+	//Use the cached output from a previous render, if possible.
+	memoize := c.memoizable(v, requestID, injectedData, bound != t || c.usesRequestData(r))
+	var key renderCacheKey
+	if memoize {
+		key = c.renderCacheKey(subdir, tmpl)
+		if b, ok := c.cachedRender(key); ok {
+			w.Write(b)
+			return nil
+		}
+	}
+
+	//Get data to render html template.
+	data := c.renderData(v, subdir, requestID, injectedData)
+	c.setRequestData(&data, r)
+
+	//Make sure the template is provided the data it declares it requires.
+	if err := c.checkRequires(subdir, templateName, c.templateData(&data)); err != nil {
+		return &showError{requestID, http.StatusInternalServerError, "templates.Show: missing required data", err}
+	}
+
+	ctx := context.Background()
+	if r != nil {
+		ctx = r.Context()
+	}
+
+	//Render the template and cache the output, sharing the output with any other
+	//requests for the same template made while rendering.
+	if memoize {
+		b, err := c.renderOnce(ctx, subdir, key, c.templateData(&data))
+		if err != nil {
+			return &showError{requestID, c.errorStatus(err), "templates.Show: error during execute", err}
+		}
+
+		w.Write(b)
+		return nil
+	}
+
+	//Wait to render if too many renders are in progress.
+	release, err := c.acquireRender(ctx)
+	if err != nil {
+		return &showError{requestID, c.errorStatus(err), "templates.Show: error waiting to execute", err}
+	}
+	defer release()
+
+	//When output is limited, render fully before writing anything so that an error
+	//response can be returned if the limit is exceeded. Output is also rendered fully
+	//when it is checked for problems since output must be checked before it is written,
+	//or when requested so that nothing is written if an error occurs.
+	if c.MaxOutputBytes > 0 || c.linting() || buffer {
+		var b bytes.Buffer
+		if err = tmpl.Execute(c.limitOutput(&b), c.templateData(&data)); err != nil {
+			return &showError{requestID, http.StatusInternalServerError, "templates.Show: error during execute", err}
+		}
+
+		if c.linting() {
+			c.lint(subdir, templateName, requestID, b.Bytes())
+		}
+
+		w.Write(b.Bytes())
+		return nil
+	}
+
+	if err = tmpl.Execute(w, c.templateData(&data)); err != nil {
 		//handle displaying of the templates if some kind of error occurs.
-		http.Error(w, err.Error(), http.StatusNotFound)
+		return &showError{requestID, http.StatusInternalServerError, "templates.Show: error during execute", err}
+	}
 
-		//log errors out since they may not always show up in gui
-		log.Println("templates.Show: error during execute", err)
+	return nil
+}
+
+//RenderData is the data provided to templates when rendering.
+//We provide some of the config defined data as well as user-provided data via the
+//InjectedData field. The InjectedData field can hold any data.
+//We aren't just reusing the Config{} struct here since we want better control over
+//what data is used in the rendering process. Plus, not all the information stored in
+//a Config{} object is needed here.
+//
+//Fields of RenderData will not be removed or renamed; new fields may be added. Use
+//NewRenderData() to get the data a template would be provided, for example to test
+//your templates or to render them with another package.
+type RenderData struct {
+	//Development is the config's Development field.
+	Development bool
+
+	//Environment is the config's Environment field.
+	Environment string
+
+	//UseLocalFiles is the config's UseLocalFiles field.
+	UseLocalFiles bool
 
+	//AppVersion is the config's AppVersion field.
+	AppVersion string
+
+	//CacheBustFiles is the cache busting file pairs for the subdirectory being shown.
+	CacheBustFiles map[string]string
+
+	//TemplatesVersion is the hash of the template files, see Hash().
+	TemplatesVersion string
+
+	//BuildTime is the time templates were last built.
+	BuildTime time.Time
+
+	//RequestID is the ID of the request being responded to, if known.
+	RequestID string
+
+	//InjectedData is the data provided when showing a template.
+	InjectedData interface{}
+
+	//Session is the session data for the request, retrieved using SessionProvider.
+	Session map[string]interface{}
+
+	//User is the user making the request, retrieved using UserProvider.
+	User interface{}
+
+	//Prefs is the preferences of the user making the request, retrieved using
+	//PreferenceProvider.
+	Prefs map[string]string
+}
+
+//renderData returns the data provided to templates when rendering. The version of the
+//templates is taken from v so that it matches the templates being rendered.
+func (c *Config) renderData(v renderView, subdir, requestID string, injectedData interface{}) RenderData {
+	return RenderData{
+		Development:      c.Development,
+		Environment:      c.Environment,
+		UseLocalFiles:    c.UseLocalFiles,
+		AppVersion:       c.AppVersion,
+		CacheBustFiles:   c.cacheBustingFilePairs(subdir),
+		TemplatesVersion: v.hash,
+		BuildTime:        v.buildTime,
+		RequestID:        requestID,
+		InjectedData:     injectedData,
+	}
+}
+
+//NewRenderData returns the data provided to templates when a template from subdir is
+//shown with injectedData.
+func (c *Config) NewRenderData(subdir string, injectedData interface{}) RenderData {
+	v, _, releaseView := c.acquireView(subdir)
+	defer releaseView()
+
+	return c.renderData(v, subdir, "", injectedData)
+}
+
+//NewRenderData returns the data provided to templates using the default package
+//level config.
+func NewRenderData(subdir string, injectedData interface{}) RenderData {
+	return Default().NewRenderData(subdir, injectedData)
+}
+
+//validateData checks the data provided to a template using ValidateData, if set.
+func (c *Config) validateData(subdir, templateName string, injectedData interface{}) error {
+	if c.ValidateData == nil {
+		return nil
+	}
+
+	err := c.ValidateData(subdir, templateName, injectedData)
+	if err != nil {
+		return fmt.Errorf("%w '%s' in subdirectory '%s': %v", ErrInvalidData, templateName, subdir, err)
+	}
+
+	return nil
+}
+
+//cacheBustingFilePairs returns the cache busting file pairs for a subdirectory. This is
+//CacheBustingFilePairs with any pairs for the subdirectory merged in.
+func (c *Config) cacheBustingFilePairs(subdir string) map[string]string {
+	scoped, ok := c.SubDirCacheBustingFilePairs[subdir]
+	if !ok {
+		return c.CacheBustingFilePairs
+	}
+
+	pairs := make(map[string]string, len(c.CacheBustingFilePairs)+len(scoped))
+	for k, v := range c.CacheBustingFilePairs {
+		pairs[k] = v
+	}
+	for k, v := range scoped {
+		pairs[k] = v
+	}
+
+	return pairs
+}
+
+//requestID returns the ID of the request. The ID is retrieved from the request's
+//context, if RequestIDContextKey is set, or from the request's headers. A blank
+//string is returned if no request is provided or the request does not have an ID.
+func (c *Config) requestID(r *http.Request) (id string) {
+	if r == nil {
 		return
 	}
+
+	if c.RequestIDContextKey != nil {
+		if v, ok := r.Context().Value(c.RequestIDContextKey).(string); ok && v != "" {
+			return v
+		}
+	}
+
+	header := c.RequestIDHeader
+	if header == "" {
+		header = defaultRequestIDHeader
+	}
+
+	return r.Header.Get(header)
+}
+
+//namedTemplates returns each template in t keyed by the template's name.
+func namedTemplates(t *template.Template) map[string]*template.Template {
+	templates := t.Templates()
+	named := make(map[string]*template.Template, len(templates))
+	for _, tmpl := range templates {
+		named[tmpl.Name()] = tmpl
+	}
+
+	return named
 }
 
 //Show handles showing a template using the default package-level config.
 func Show(w http.ResponseWriter, subdir, templateName string, injectedData interface{}) {
-	config.Show(w, subdir, templateName, injectedData)
+	Default().Show(w, subdir, templateName, injectedData)
+}
+
+//ShowRequest handles showing a template, aware of the request being responded to, using
+//the default package-level config.
+func ShowRequest(w http.ResponseWriter, r *http.Request, subdir, templateName string, injectedData interface{}) {
+	Default().ShowRequest(w, r, subdir, templateName, injectedData)
 }
 
-//GetConfig returns the current state of the package level config.
+//Render handles showing a template, returning any error, using the default package
+//level config.
+func Render(w http.ResponseWriter, subdir, templateName string, injectedData interface{}) error {
+	return Default().Render(w, subdir, templateName, injectedData)
+}
+
+//RenderRequest handles showing a template, aware of the request being responded to and
+//returning any error, using the default package level config.
+func RenderRequest(w http.ResponseWriter, r *http.Request, subdir, templateName string, injectedData interface{}) error {
+	return Default().RenderRequest(w, r, subdir, templateName, injectedData)
+}
+
+//GetConfig returns the package level config.
+//
+//Deprecated: use Default(), or better yet, keep and use your own *Config.
 func GetConfig() (c *Config) {
-	return &config
+	return Default()
+}
+
+//SetDevelopment sets the Development field. Use this, rather than setting the field
+//directly, once templates are built so that the change is known, see ConditionalGET.
+func (c *Config) SetDevelopment(yes bool) {
+	c.Development = yes
+	c.configChanged()
+}
+
+//SetEnvironment sets the Environment field, the same as SetDevelopment().
+func (c *Config) SetEnvironment(env string) {
+	c.Environment = env
+	c.configChanged()
+}
+
+//SetUseLocalFiles sets the UseLocalFiles field, the same as SetDevelopment().
+func (c *Config) SetUseLocalFiles(yes bool) {
+	c.UseLocalFiles = yes
+	c.configChanged()
+}
+
+//SetAppVersion sets the AppVersion field, the same as SetDevelopment().
+func (c *Config) SetAppVersion(v string) {
+	c.AppVersion = v
+	c.configChanged()
+}
+
+//SetCacheBustingFilePairs sets the CacheBustingFilePairs field, the same as
+//SetDevelopment().
+func (c *Config) SetCacheBustingFilePairs(pairs map[string]string) {
+	c.CacheBustingFilePairs = pairs
+	c.configChanged()
+}
+
+//SetSubDirCacheBustingFilePairs sets the cache busting file pairs for a subdirectory,
+//the same as SetDevelopment().
+func (c *Config) SetSubDirCacheBustingFilePairs(subdir string, pairs map[string]string) {
+	scoped := make(map[string]map[string]string, len(c.SubDirCacheBustingFilePairs)+1)
+	for k, v := range c.SubDirCacheBustingFilePairs {
+		scoped[k] = v
+	}
+	scoped[subdir] = pairs
+
+	c.SubDirCacheBustingFilePairs = scoped
+	c.configChanged()
+}
+
+//configChanged records that the config fields provided to templates changed.
+func (c *Config) configChanged() {
+	if c.mu == nil {
+		return
+	}
+
+	c.mu.Lock()
+	c.configChangedAt = time.Now()
+	c.mu.Unlock()
 }
 
 //Development sets the Development field on the package level config.
 func Development(yes bool) {
-	config.Development = yes
+	Default().SetDevelopment(yes)
+}
+
+//Environment sets the Environment field on the package level config.
+func Environment(env string) {
+	Default().SetEnvironment(env)
 }
 
 //UseLocalFiles sets the UseLocalFiles field on the package level config.
 func UseLocalFiles(yes bool) {
-	config.UseLocalFiles = yes
+	Default().SetUseLocalFiles(yes)
+}
+
+//AppVersion sets the AppVersion field on the package level config.
+func AppVersion(v string) {
+	Default().SetAppVersion(v)
+}
+
+//AppVersionFromBuildInfo returns a version for your app built from the build info
+//embedded in your app's executable by the go tool. The returned version is the main
+//module's version followed by the short VCS revision, if available, for example
+//"v1.4.2 (abc1234)". This returns a blank string if no build info is available. The
+//returned value is typically used to set the AppVersion field.
+func AppVersionFromBuildInfo() (v string) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return
+	}
+
+	v = info.Main.Version
+
+	var revision string
+	var modified bool
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			revision = s.Value
+		case "vcs.modified":
+			modified = (s.Value == "true")
+		}
+	}
+
+	if revision == "" {
+		return
+	}
+
+	const shortRevisionLength = 7
+	if len(revision) > shortRevisionLength {
+		revision = revision[:shortRevisionLength]
+	}
+	if modified {
+		revision += "-dirty"
+	}
+
+	v = strings.TrimSpace(v + " (" + revision + ")")
+	return
 }
 
 //CacheBustingFilePairs sets the CacheBustingFilePairs field on the package level config.
 func CacheBustingFilePairs(pairs map[string]string) {
-	config.CacheBustingFilePairs = pairs
+	Default().SetCacheBustingFilePairs(pairs)
+}
+
+//SubDirCacheBustingFilePairs sets the cache busting file pairs for a subdirectory on the
+//package level config.
+func SubDirCacheBustingFilePairs(subdir string, pairs map[string]string) {
+	Default().SetSubDirCacheBustingFilePairs(subdir, pairs)
 }
 
 //DefaultFuncMap returns the list of extra funcs defined for use in templates.
@@ -501,7 +1945,60 @@ func DefaultFuncMap() template.FuncMap {
 	return template.FuncMap{
 		"indexOf":      FuncIndexOf,
 		"dateReformat": FuncDateReformat,
+		"dateAdd":      FuncDateAdd,
+		"dateDiff":     FuncDateDiff,
 		"addInt":       FuncAddInt,
+		"metaTag":      FuncMetaTag,
+		"ogTags":       FuncOGTags,
+		"canonicalURL": FuncCanonicalURL,
+		"jsonLD":       FuncJSONLD,
+
+		"obfuscateEmail":  FuncObfuscateEmail,
+		"obfuscateMailto": FuncObfuscateMailto,
+		"breadcrumbs":     FuncBreadcrumbs,
+		"table":           FuncTable,
+		"qrCode":          FuncQRCode,
+		"truncate":        FuncTruncate,
+		"escapeAttr":      FuncEscapeAttr,
+		"escapeJSString":  FuncEscapeJSString,
+
+		"timeAgo":      FuncTimeAgo,
+		"isToday":      FuncIsToday,
+		"isYesterday":  FuncIsYesterday,
+		"startOfDay":   FuncStartOfDay,
+		"startOfWeek":  FuncStartOfWeek,
+		"startOfMonth": FuncStartOfMonth,
+		"withinLast":   FuncWithinLast,
+
+		"formatBytes": FuncFormatBytes,
+		"parseBytes":  FuncParseBytes,
+
+		"uuid":         FuncUUID,
+		"randomString": FuncRandomString,
+
+		"keys":    FuncKeys,
+		"values":  FuncValues,
+		"sortBy":  FuncSortBy,
+		"groupBy": FuncGroupBy,
+
+		"first":    FuncFirst,
+		"last":     FuncLast,
+		"subslice": FuncSlice,
+		"chunk":    FuncChunk,
+
+		"list": FuncList,
+		"in":   FuncIn,
+		"has":  FuncHas,
+
+		"dict":   FuncDict,
+		"format": FuncFormat,
+
+		"maskEmail":  FuncMaskEmail,
+		"maskPhone":  FuncMaskPhone,
+		"maskExcept": FuncMaskExcept,
+
+		"formatPhone": FuncFormatPhone,
+		"excerpt":     FuncExcerpt,
 	}
 }
 
@@ -1,13 +1,17 @@
 package templates
 
 import (
+	"context"
 	"embed"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
+	"testing/fstest"
 )
 
 //go:embed _testdata
@@ -240,6 +244,44 @@ func TestBuildPathsToFiles(t *testing.T) {
 		}
 	}
 	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Hidden files and symlinks to files are included by default. Symlinks to
+	//directories are never included.
+	base = filepath.Join(dir, "_testdata", "templates")
+	subdirs = []string{"hidden"}
+	c = NewOnDiskConfig(base, subdirs)
+	paths, err = c.buildPathsToFiles(filepath.Join(base, "hidden"))
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	if len(paths) != 3 {
+		t.Fatal("Hidden files and symlinks should have been included", paths)
+		return
+	}
+	for _, p := range paths {
+		if filepath.Base(p) == "dirlink.html" {
+			t.Fatal("Symlink to directory should not have been included")
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Hidden files and symlinks ignored.
+	c.IgnoreHiddenFiles = true
+	c.IgnoreSymlinks = true
+	paths, err = c.buildPathsToFiles(filepath.Join(base, "hidden"))
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	if len(paths) != 1 || filepath.Base(paths[0]) != "page.html" {
+		t.Fatal("Hidden files and symlinks should have been ignored", paths)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
 }
 
 func TestBuild(t *testing.T) {
@@ -284,6 +326,19 @@ func TestBuild(t *testing.T) {
 	}
 	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
 
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Templates keyed by name for each subdirectory.
+	for subdir, paths := range c.files {
+		for _, p := range paths {
+			tmpl, ok := c.named[subdir][filepath.Base(p)]
+			if !ok || tmpl.Name() != filepath.Base(p) {
+				t.Fatal("Template not keyed by name as expected", subdir, p)
+				return
+			}
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
 	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
 	//Create embedded config that successfully build.
 	base = filepath.Join("_testdata", "templates")
@@ -306,6 +361,36 @@ func TestBuild(t *testing.T) {
 		return
 	}
 	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Progress is reported for each file parsed.
+	base = filepath.Join(dir, "_testdata", "templates")
+	subdirs = []string{"app", "help"}
+	c = NewOnDiskConfig(base, subdirs)
+
+	calls, lastDone, lastTotal := 0, 0, 0
+	c.BuildProgress = func(done, total int, currentPath string) {
+		calls++
+		lastDone, lastTotal = done, total
+		if currentPath == "" {
+			t.Fatal("Path should have been provided")
+		}
+	}
+	err = c.Build()
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+
+	expected := 0
+	for _, paths := range c.files {
+		expected += len(paths)
+	}
+	if calls != expected || lastDone != expected || lastTotal != expected {
+		t.Fatal("Progress not reported as expected", calls, lastDone, lastTotal, expected)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
 }
 
 func TestDefaultConfig(t *testing.T) {
@@ -347,6 +432,26 @@ func TestDefaultConfig(t *testing.T) {
 	}
 	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
 
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Environment
+	Environment("staging")
+	c = GetConfig()
+	if c.Environment != "staging" {
+		t.Fatal("Environment field not set correctly")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//AppVersion
+	AppVersion("v1.0.0")
+	c = GetConfig()
+	if c.AppVersion != "v1.0.0" {
+		t.Fatal("AppVersion field not set correctly")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
 	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
 	//CacheBustingFilePairs
 	pairs := map[string]string{
@@ -359,6 +464,24 @@ func TestDefaultConfig(t *testing.T) {
 		return
 	}
 	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//SubDirCacheBustingFilePairs
+	SubDirCacheBustingFilePairs("admin", map[string]string{
+		"original.css": "admin-modified.css",
+		"admin.js":     "admin-modified.js",
+	})
+	c = GetConfig()
+	merged := c.cacheBustingFilePairs("admin")
+	if merged["original.css"] != "admin-modified.css" || merged["admin.js"] != "admin-modified.js" {
+		t.Fatal("Subdirectory pairs not merged correctly", merged)
+		return
+	}
+	if c.cacheBustingFilePairs("app")["original.css"] != "modified.css" {
+		t.Fatal("Pairs for other subdirectories should not have been modified")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
 }
 
 func TestDefaultFuncMap(t *testing.T) {
@@ -396,6 +519,32 @@ func TestShow(t *testing.T) {
 	}
 	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
 
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Templates version and build time are provided to templates.
+	w = httptest.NewRecorder()
+	c.Show(w, "help", "version", nil)
+	if w.Code != http.StatusOK {
+		t.Fatal("Error showing", w.Code, w.Body)
+		return
+	}
+	expected := c.Hash() + "|" + strconv.FormatInt(c.BuildTime().Unix(), 10)
+	if strings.TrimSpace(w.Body.String()) != expected {
+		t.Fatal("Templates version or build time not provided as expected", w.Body.String(), expected)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Environment is provided to templates.
+	c.Environment = "Staging"
+	w = httptest.NewRecorder()
+	c.Show(w, "help", "env", nil)
+	if strings.TrimSpace(w.Body.String()) != "Staging|staging" {
+		t.Fatal("Environment not provided as expected", w.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
 	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
 	//Bad subdir to serve.
 	w = httptest.NewRecorder()
@@ -406,3 +555,350 @@ func TestShow(t *testing.T) {
 	}
 	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
 }
+
+func TestRender(t *testing.T) {
+	fsys := fstest.MapFS{
+		"tpl/app/page.html":  {Data: []byte(`<p>{{.InjectedData}}</p>`)},
+		"tpl/app/error.html": {Data: []byte(`<p>{{index .InjectedData 5}}</p>`)},
+	}
+
+	c := NewFSConfig(fsys, "tpl", []string{"app"})
+	err := c.Build()
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Good file to serve.
+	w := httptest.NewRecorder()
+	err = c.Render(w, "app", "page", "hello")
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+	if w.Body.String() != "<p>hello</p>" {
+		t.Fatal("Unexpected output", w.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Missing templates are returned rather than written.
+	w = httptest.NewRecorder()
+	err = c.Render(w, "app", "missing", nil)
+	if !errors.Is(err, ErrTemplateNotFound) {
+		t.Fatal("ErrTemplateNotFound should have occured but did not", err)
+		return
+	}
+	if w.Body.Len() != 0 {
+		t.Fatal("Nothing should have been written", w.Body.String())
+		return
+	}
+
+	w = httptest.NewRecorder()
+	err = c.Render(w, "missing", "page", nil)
+	if !errors.Is(err, ErrUnknownSubDir) {
+		t.Fatal("ErrUnknownSubDir should have occured but did not", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Execution errors are returned and nothing is written.
+	w = httptest.NewRecorder()
+	err = c.Render(w, "app", "error", []int{1})
+	if err == nil {
+		t.Fatal("Error should have occured but did not")
+		return
+	}
+	if w.Body.Len() != 0 {
+		t.Fatal("Nothing should have been written", w.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestValidateData(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	c := NewOnDiskConfig(base, []string{"app", "help"})
+	c.ValidateData = func(subdir, name string, data interface{}) error {
+		if subdir == "help" && name == "version.html" && data == nil {
+			return errors.New("data is required")
+		}
+		return nil
+	}
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	w := httptest.NewRecorder()
+	c.Show(w, "help", "version", "injected")
+	if w.Code != http.StatusOK {
+		t.Fatal("Error showing", w.Code, w.Body)
+		return
+	}
+
+	w = httptest.NewRecorder()
+	c.Show(w, "help", "version", nil)
+	if w.Code != http.StatusInternalServerError || !strings.Contains(w.Body.String(), "data is required") {
+		t.Fatal("Data should have been invalid", w.Code, w.Body)
+		return
+	}
+
+	w = httptest.NewRecorder()
+	c.Show(w, "app", "app", nil)
+	if w.Code != http.StatusOK {
+		t.Fatal("Error showing", w.Code, w.Body)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	var b strings.Builder
+	err = c.RenderText(&b, "help", "version", nil)
+	if !errors.Is(err, ErrInvalidData) {
+		t.Fatal("ErrInvalidData should have occured but didn't", err)
+		return
+	}
+	err = c.render(&b, "help", "version", nil)
+	if !errors.Is(err, ErrInvalidData) {
+		t.Fatal("ErrInvalidData should have occured but didn't", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestParseExtra(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	subdirs := []string{"app", "help"}
+	c := NewOnDiskConfig(base, subdirs)
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Show a template first to make sure extra files can be added after templates
+	//have been executed.
+	w := httptest.NewRecorder()
+	c.Show(w, "app", "app", nil)
+	if w.Code != http.StatusOK {
+		t.Fatal("Error showing", w.Code, w.Body)
+		return
+	}
+
+	extra := filepath.Join(dir, "_testdata", "extra", "extra.html")
+	err = c.ParseExtra("app", extra)
+	if err != nil {
+		t.Fatal("Error should not have occured but did", err)
+		return
+	}
+
+	w = httptest.NewRecorder()
+	c.Show(w, "app", "extra", nil)
+	if w.Code != http.StatusOK {
+		t.Fatal("Error showing extra template", w.Code, w.Body)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Extra file should not be added to other subdirectories.
+	w = httptest.NewRecorder()
+	c.Show(w, "help", "extra", nil)
+	if w.Code == http.StatusOK {
+		t.Fatal("Extra template should not exist in other subdirectory")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Subdirectory that was not built.
+	err = c.ParseExtra("non-existant-subdir", extra)
+	if err != ErrUnknownSubDir {
+		t.Fatal("ErrUnknownSubDir should have occured but didn't", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//No paths provided.
+	err = c.ParseExtra("app")
+	if err != ErrNoPathsProvided {
+		t.Fatal("ErrNoPathsProvided should have occured but didn't", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestShowRequest(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	subdirs := []string{"app", "help"}
+	c := NewOnDiskConfig(base, subdirs)
+	err = c.Build()
+	if err != nil {
+		t.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Request ID from default header.
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("X-Request-ID", "abc123")
+	w := httptest.NewRecorder()
+	c.ShowRequest(w, r, "help", "request", nil)
+	if w.Code != http.StatusOK {
+		t.Fatal("Error showing", w.Code, w.Body)
+		return
+	}
+	if strings.TrimSpace(w.Body.String()) != "abc123" {
+		t.Fatal("Request ID not provided as expected", w.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Request ID from context takes precedence over header.
+	type ctxKey string
+	c.RequestIDContextKey = ctxKey("request-id")
+	r = r.WithContext(context.WithValue(r.Context(), ctxKey("request-id"), "def456"))
+	w = httptest.NewRecorder()
+	c.ShowRequest(w, r, "help", "request", nil)
+	if strings.TrimSpace(w.Body.String()) != "def456" {
+		t.Fatal("Request ID not provided from context as expected", w.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestRenderData(t *testing.T) {
+	c := NewConfig()
+	c.Development = true
+	c.AppVersion = "1.2.3"
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Data populated from config.
+	d := c.renderData(renderView{}, "", "abc", "injected")
+	if !d.Development || d.AppVersion != "1.2.3" || d.RequestID != "abc" || d.InjectedData != "injected" {
+		t.Fatal("Render data not populated as expected", d)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Exported data.
+	c.CacheBustingFilePairs = map[string]string{"app.js": "1234.app.js"}
+	data := c.NewRenderData("", "injected")
+	if !data.Development || data.InjectedData != "injected" || data.CacheBustFiles["app.js"] != "1234.app.js" {
+		t.Fatal("Render data not populated as expected", data)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+//discardResponseWriter is an http.ResponseWriter that discards everything written to
+//it. This is used for benchmarking so that the response writer does not allocate.
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func (d *discardResponseWriter) Header() http.Header         { return d.header }
+func (d *discardResponseWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (d *discardResponseWriter) WriteHeader(int)             {}
+
+func BenchmarkShow(b *testing.B) {
+	dir, err := os.Getwd()
+	if err != nil {
+		b.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	subdirs := []string{"app", "help"}
+	c := NewOnDiskConfig(base, subdirs)
+	err = c.Build()
+	if err != nil {
+		b.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	w := &discardResponseWriter{header: make(http.Header)}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.Show(w, "help", "version", nil)
+	}
+}
+
+func BenchmarkShowInjectedData(b *testing.B) {
+	dir, err := os.Getwd()
+	if err != nil {
+		b.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	subdirs := []string{"app", "help"}
+	c := NewOnDiskConfig(base, subdirs)
+	err = c.Build()
+	if err != nil {
+		b.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	w := &discardResponseWriter{header: make(http.Header)}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.Show(w, "help", "version", "injected")
+	}
+}
+
+func BenchmarkShowRequest(b *testing.B) {
+	dir, err := os.Getwd()
+	if err != nil {
+		b.Fatal(err)
+		return
+	}
+
+	base := filepath.Join(dir, "_testdata", "templates")
+	subdirs := []string{"app", "help"}
+	c := NewOnDiskConfig(base, subdirs)
+	err = c.Build()
+	if err != nil {
+		b.Fatal("failed building for some reason...", err)
+		return
+	}
+
+	//The absolute template uses a request-scoped func.
+	r := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+	w := &discardResponseWriter{header: make(http.Header)}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.ShowRequest(w, r, "help", "absolute", nil)
+	}
+}